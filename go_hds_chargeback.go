@@ -0,0 +1,242 @@
+package main
+
+import (
+	"encoding/csv"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//ChargebackInfo type is the capacity one group (host group, name pattern or tag) uses in one pool. Capacities are in [MB].
+type ChargebackInfo struct {
+	Group            string
+	PoolID           int
+	PoolName         string
+	CompressionRatio float64
+	HostGroups       map[string]bool
+	Ldevs            int
+	Provisioned      float64
+	Used             float64
+	Physical         float64
+}
+
+//ChargebackTag type is one line of the tag file. Host groups with a name matching the pattern get the tag.
+type ChargebackTag struct {
+	Pattern *regexp.Regexp
+	Tag     string
+}
+
+//ChargebackGet sums the provisioned and used capacity per host group, host group name pattern or tag and pool.
+//host groups -> LUNs -> LDEVs -> pools are joined. The physical consumption is estimated with the compression ratio total of the pool.
+//LDEVs mapped to more than one group are split equally between the groups. the host groups of a row are the host groups with LDEVs of its pool.
+//the tags of the tag file (-tagfile) are read by ChargebackTagsRead before the session is opened.
+//return value (string) is empty. if an error happened the state is true. Otherwise false.
+//example: ChargebackGet(p, Tags)
+func ChargebackGet(p Params, Tags []ChargebackTag) (string, bool) {
+	Debug.Println("Function 'ChargebackGet' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//initial state is true that means NOK
+	State := true
+
+	var Mb2Gb float64
+	Mb2Gb = 1024.0
+
+	Info.Println("Get chargeback information start")
+
+	//pools by pool id
+	var Pools []PoolInfo
+	Pools, State = PoolsListGet(p)
+	PoolMap := map[int]PoolInfo{}
+	for _, Pool := range Pools {
+		PoolID, _ := strconv.Atoi(Pool.PoolID)
+		PoolMap[PoolID] = Pool
+	}
+
+	//ldevs by ldev id
+	var Ldevs []LdevInfo
+	Ldevs, State = LdevsListGet(p)
+	LdevMap := map[int]LdevInfo{}
+	for _, Ldev := range Ldevs {
+		LdevMap[Ldev.LdevID] = Ldev
+	}
+
+	var Luns []LunInfo
+	Luns, State = LunsListGet(p)
+
+	//groups of every ldev and the host groups of the ldev in every group
+	LdevGroups := map[int]map[string]map[string]bool{}
+	for _, Lun := range Luns {
+		Group := ChargebackGroup(Lun.HostGroupName, p, Tags)
+		if LdevGroups[Lun.LdevID] == nil {
+			LdevGroups[Lun.LdevID] = map[string]map[string]bool{}
+		}
+		if LdevGroups[Lun.LdevID][Group] == nil {
+			LdevGroups[Lun.LdevID][Group] = map[string]bool{}
+		}
+		LdevGroups[Lun.LdevID][Group][Lun.PortID+","+strconv.Itoa(Lun.HostGroupNumber)] = true
+	}
+
+	//sum per group and pool
+	Chargeback := map[string]*ChargebackInfo{}
+	for LdevID, Groups := range LdevGroups {
+		Ldev, ok := LdevMap[LdevID]
+		if !ok {
+			Verbose.Println("LDEV: " + LdevIDFormat(LdevID) + " has a LUN path but is not in the LDEV list. It is skipped.")
+			continue
+		}
+
+		var Share float64
+		Share = 1 / float64(len(Groups))
+
+		for Group, HostGroups := range Groups {
+			Key := Group + "," + strconv.Itoa(Ldev.PoolID)
+			if Chargeback[Key] == nil {
+				Chargeback[Key] = &ChargebackInfo{Group: Group, PoolID: Ldev.PoolID, PoolName: "-", CompressionRatio: -1, HostGroups: map[string]bool{}}
+				if Pool, ok := PoolMap[Ldev.PoolID]; ok {
+					Chargeback[Key].PoolName = Pool.PoolName
+					Chargeback[Key].CompressionRatio = Pool.CompressionRatio
+				}
+			}
+			Entry := Chargeback[Key]
+			//only the host groups with LDEVs of this pool
+			for HostGroup := range HostGroups {
+				Entry.HostGroups[HostGroup] = true
+			}
+			Entry.Ldevs = Entry.Ldevs + 1
+			Entry.Provisioned = Entry.Provisioned + Ldev.Capacity*Share
+			Entry.Used = Entry.Used + Ldev.UsedCapacity*Share
+			//no compression ratio (-1) -> the used capacity is physical
+			if Entry.CompressionRatio > 0 {
+				Entry.Physical = Entry.Physical + Ldev.UsedCapacity*Share/Entry.CompressionRatio
+			} else {
+				Entry.Physical = Entry.Physical + Ldev.UsedCapacity*Share
+			}
+		}
+	}
+
+	//sort by group and pool
+	var Entries []*ChargebackInfo
+	for _, Entry := range Chargeback {
+		Entries = append(Entries, Entry)
+	}
+	sort.Slice(Entries, func(i, j int) bool {
+		if Entries[i].Group != Entries[j].Group {
+			return Entries[i].Group < Entries[j].Group
+		}
+		return Entries[i].PoolID < Entries[j].PoolID
+	})
+
+	//add empty string of strings to collect all chargeback data to output
+	OutData := [][]string{}
+	for _, Entry := range Entries {
+		var PoolID string
+		PoolID = "-"
+		if Entry.PoolID >= 0 {
+			PoolID = strconv.Itoa(Entry.PoolID)
+		}
+
+		OutData = append(OutData, []string{p.ElementStringStart})
+		OutData = append(OutData, []string{HeaderFormat("Group", "string", p), Entry.Group})
+		OutData = append(OutData, []string{HeaderFormat("Host groups", "float64", p), strconv.Itoa(len(Entry.HostGroups))})
+		OutData = append(OutData, []string{HeaderFormat("Pool ID", "string", p), PoolID})
+		OutData = append(OutData, []string{HeaderFormat("Pool name", "string", p), Entry.PoolName})
+		OutData = append(OutData, []string{HeaderFormat("Compression ratio total", "float64", p), strconv.FormatFloat(Entry.CompressionRatio, 'f', p.RoundPrecision, 64)})
+		OutData = append(OutData, []string{HeaderFormat("LDEVs", "float64", p), strconv.Itoa(Entry.Ldevs)})
+		OutData = append(OutData, []string{HeaderFormat("Provisioned capacity [GB]", "float64", p), strconv.FormatFloat(Entry.Provisioned/Mb2Gb, 'f', p.RoundPrecision, 64)})
+		OutData = append(OutData, []string{HeaderFormat("Used capacity [GB]", "float64", p), strconv.FormatFloat(Entry.Used/Mb2Gb, 'f', p.RoundPrecision, 64)})
+		OutData = append(OutData, []string{HeaderFormat("Estimated physical capacity [GB]", "float64", p), strconv.FormatFloat(Entry.Physical/Mb2Gb, 'f', p.RoundPrecision, 64)})
+		OutData = append(OutData, []string{p.ElementStringEnd})
+	}
+
+	if OutputListFormat(OutData, p) {
+		Warning.Println("The function 'OutputListFormat' returned an Error.")
+	}
+
+	Info.Println("Get chargeback information end")
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'ChargebackGet' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'ChargebackGet' return values State:", State)
+	Debug.Println("Function 'ChargebackGet' end")
+
+	//state to OK
+	State = false
+	return "", State
+}
+
+//ChargebackGroup returns the group of a host group
+//with a tag file the first matching tag ("untagged" if none matches). -groupby hostgroup -> the host group name.
+//otherwise -groupby is a regular expression. the first capture group (or the whole match) is the group ("unassigned" if it does not match).
+func ChargebackGroup(HostGroupName string, p Params, Tags []ChargebackTag) string {
+	if len(Tags) > 0 {
+		for _, Tag := range Tags {
+			if Tag.Pattern.MatchString(HostGroupName) {
+				return Tag.Tag
+			}
+		}
+		return "untagged"
+	}
+
+	if p.GroupBy == "hostgroup" {
+		return HostGroupName
+	}
+
+	//the regular expression is checked at the start
+	Match := regexp.MustCompile(p.GroupBy).FindStringSubmatch(HostGroupName)
+	if Match == nil {
+		return "unassigned"
+	}
+	if len(Match) > 1 {
+		return Match[1]
+	}
+	return Match[0]
+}
+
+//ChargebackTagsRead reads the tag file. every line is "<host group name regular expression>,<tag>". lines starting with # are skipped.
+//The function stops with exit status 70 ("The tag file cannot be read.")
+//The function stops with exit status 71 ("The tag file contains an invalid line.")
+func ChargebackTagsRead(FileName string) ([]ChargebackTag, bool) {
+	Debug.Println("Function 'ChargebackTagsRead' started.")
+
+	//initial state is true that means NOK
+	State := true
+
+	File, err := os.Open(FileName)
+	if err != nil {
+		Error.Println("The tag file (" + FileName + ") cannot be read: " + err.Error())
+		os.Exit(70)
+	}
+	defer File.Close()
+
+	Reader := csv.NewReader(File)
+	Reader.Comment = '#'
+	Reader.FieldsPerRecord = 2
+	Records, err := Reader.ReadAll()
+	if err != nil {
+		Error.Println("The tag file (" + FileName + ") contains an invalid line: " + err.Error())
+		os.Exit(71)
+	}
+
+	var Tags []ChargebackTag
+	for _, Record := range Records {
+		Pattern, err := regexp.Compile(strings.TrimSpace(Record[0]))
+		if err != nil {
+			Error.Println("The tag file (" + FileName + ") contains an invalid regular expression: " + err.Error())
+			os.Exit(71)
+		}
+		Tags = append(Tags, ChargebackTag{Pattern: Pattern, Tag: strings.TrimSpace(Record[1])})
+	}
+
+	Debug.Println("Function 'ChargebackTagsRead' return values number of tags:", len(Tags))
+	Debug.Println("Function 'ChargebackTagsRead' ended.")
+
+	//state to OK
+	State = false
+	return Tags, State
+}
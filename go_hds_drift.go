@@ -0,0 +1,269 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+//DesiredState type is the state file (-statefile) with the intended host groups, WWNs and LUN paths
+//the host groups are identified by port and name. only the ports of the state file are checked. hostMode and hostModeOptions are only checked if they are set.
+/*
+   hostGroups:
+     - port: CL1-A
+       name: 1A_esx01
+       hostMode: VMWARE_EX
+       hostModeOptions: [54, 63, 114]
+       wwns: ["10:00:00:90:fa:00:00:01"]
+       luns:
+         0: "00:04:00"
+         1: 1025
+*/
+type DesiredState struct {
+	HostGroups []DesiredHostGroup `yaml:"hostGroups"`
+}
+
+//DesiredHostGroup type is one host group of the state file. Luns are the LDEV IDs (decimal or 00:04:00) per LUN.
+type DesiredHostGroup struct {
+	Port            string         `yaml:"port"`
+	Name            string         `yaml:"name"`
+	HostMode        string         `yaml:"hostMode"`
+	HostModeOptions []int          `yaml:"hostModeOptions"`
+	WWNs            []string       `yaml:"wwns"`
+	Luns            map[int]string `yaml:"luns"`
+}
+
+//DriftItem type is one difference between the state file and the storage
+//Object is "host group", "host mode", "host mode options", "WWN" or "LUN". Drift is "missing", "extra" or "changed".
+type DriftItem struct {
+	Object    string
+	Port      string
+	HostGroup string
+	Item      string
+	Drift     string
+	Desired   string
+	Live      string
+}
+
+//Drift compares the host groups, WWNs and LUN paths of the state file (-statefile, read by DesiredStateRead before the session is opened) with the storage
+//missing objects are in the state file only, extra objects are on the storage only and changed objects are different.
+//host groups of the ports of the state file that are not in it are extra. host group 0 is only extra if it has WWNs or LUN paths.
+//return value (int) is the number of differences. if an error happened the state is true. Otherwise false.
+//example: Drift(p, Desired)
+func Drift(p Params, Desired DesiredState) (int, bool) {
+	Debug.Println("Function 'Drift' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//initial state is true that means NOK
+	State := true
+
+	Info.Println("Drift detection start")
+
+	var HostGroups []HostGroupInfo
+	HostGroups, State = HostGroupsListGet(p)
+
+	Ports := map[string]bool{}
+	for _, DesiredGroup := range Desired.HostGroups {
+		Ports[DesiredGroup.Port] = true
+	}
+
+	var Items []DriftItem
+
+	//desired host groups
+	Found := map[string]bool{}
+	for _, DesiredGroup := range Desired.HostGroups {
+		var Live HostGroupInfo
+		var LiveFound bool
+		LiveFound = false
+		for _, HostGroup := range HostGroups {
+			if HostGroup.PortID == DesiredGroup.Port && HostGroup.HostGroupName == DesiredGroup.Name {
+				Live = HostGroup
+				LiveFound = true
+				break
+			}
+		}
+		if !LiveFound {
+			Items = append(Items, DriftItem{Object: "host group", Port: DesiredGroup.Port, HostGroup: DesiredGroup.Name, Drift: "missing", Desired: DesiredGroup.Name})
+			continue
+		}
+		Found[Live.PortID+","+strconv.Itoa(Live.HostGroupNumber)] = true
+		Items = append(Items, DriftHostGroupCompare(p, DesiredGroup, Live)...)
+	}
+
+	//extra host groups of the ports
+	for _, HostGroup := range HostGroups {
+		if !Ports[HostGroup.PortID] || Found[HostGroup.PortID+","+strconv.Itoa(HostGroup.HostGroupNumber)] {
+			continue
+		}
+		if HostGroup.HostGroupNumber == 0 {
+			//the default host group is only used if it has WWNs or LUN paths
+			WWNs, _ := HostWWNsListGet(p, HostGroup)
+			Luns, _ := HostGroupLunsListGet(p, HostGroup)
+			if len(WWNs) == 0 && len(Luns) == 0 {
+				continue
+			}
+		}
+		Items = append(Items, DriftItem{Object: "host group", Port: HostGroup.PortID, HostGroup: HostGroup.HostGroupName, Item: strconv.Itoa(HostGroup.HostGroupNumber), Drift: "extra", Live: HostGroup.HostGroupName})
+	}
+
+	OutData := [][]string{}
+	for _, Item := range Items {
+		OutData = append(OutData, []string{p.ElementStringStart})
+		OutData = append(OutData, []string{HeaderFormat("Object", "string", p), Item.Object})
+		OutData = append(OutData, []string{HeaderFormat("Port", "string", p), Item.Port})
+		OutData = append(OutData, []string{HeaderFormat("Host group", "string", p), Item.HostGroup})
+		OutData = append(OutData, []string{HeaderFormat("Item", "string", p), Item.Item})
+		OutData = append(OutData, []string{HeaderFormat("Drift", "string", p), Item.Drift})
+		OutData = append(OutData, []string{HeaderFormat("Desired", "string", p), Item.Desired})
+		OutData = append(OutData, []string{HeaderFormat("Live", "string", p), Item.Live})
+		OutData = append(OutData, []string{p.ElementStringEnd})
+	}
+	if len(Items) == 0 {
+		Info.Println("No drift. The storage matches the state file.")
+	} else if OutputListFormat(OutData, p) {
+		Warning.Println("The function 'OutputListFormat' returned an Error.")
+	}
+
+	Info.Println("Drift detection end (" + strconv.Itoa(len(Items)) + " differences)")
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'Drift' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'Drift' return values number of differences:", len(Items))
+	Debug.Println("Function 'Drift' ended.")
+
+	//state to OK
+	State = false
+	return len(Items), State
+}
+
+//DriftHostGroupCompare returns the differences of the host mode, the WWNs and the LUN paths of a host group
+func DriftHostGroupCompare(p Params, Desired DesiredHostGroup, Live HostGroupInfo) []DriftItem {
+	var Items []DriftItem
+
+	if Desired.HostMode != "" && Desired.HostMode != Live.HostMode {
+		Items = append(Items, DriftItem{Object: "host mode", Port: Live.PortID, HostGroup: Live.HostGroupName, Drift: "changed", Desired: Desired.HostMode, Live: Live.HostMode})
+	}
+	if Desired.HostModeOptions != nil {
+		DesiredOptions := HostModeFormat(HostGroupInfo{HostModeOptions: Desired.HostModeOptions})
+		LiveOptions := HostModeFormat(HostGroupInfo{HostModeOptions: Live.HostModeOptions})
+		if DesiredOptions != LiveOptions {
+			Items = append(Items, DriftItem{Object: "host mode options", Port: Live.PortID, HostGroup: Live.HostGroupName, Drift: "changed", Desired: strings.TrimSpace(DesiredOptions), Live: strings.TrimSpace(LiveOptions)})
+		}
+	}
+
+	//WWNs
+	LiveWWNs, _ := HostWWNsListGet(p, Live)
+	LiveWWNFound := map[string]bool{}
+	for _, WWN := range LiveWWNs {
+		LiveWWNFound[WWN] = true
+	}
+	DesiredWWNFound := map[string]bool{}
+	for _, WWN := range Desired.WWNs {
+		DesiredWWNFound[WWN] = true
+		if !LiveWWNFound[WWN] {
+			Items = append(Items, DriftItem{Object: "WWN", Port: Live.PortID, HostGroup: Live.HostGroupName, Item: WWN, Drift: "missing", Desired: WWN})
+		}
+	}
+	for _, WWN := range LiveWWNs {
+		if !DesiredWWNFound[WWN] {
+			Items = append(Items, DriftItem{Object: "WWN", Port: Live.PortID, HostGroup: Live.HostGroupName, Item: WWN, Drift: "extra", Live: WWN})
+		}
+	}
+
+	//LUN paths
+	LiveLuns, _ := HostGroupLunsListGet(p, Live)
+	LiveLdevs := map[int]int{}
+	for _, Lun := range LiveLuns {
+		LiveLdevs[Lun.Lun] = Lun.LdevID
+	}
+	var DesiredLuns []int
+	for Lun := range Desired.Luns {
+		DesiredLuns = append(DesiredLuns, Lun)
+	}
+	sort.Ints(DesiredLuns)
+	for _, Lun := range DesiredLuns {
+		DesiredLdevID, _ := LdevIDParse(Desired.Luns[Lun])
+		LiveLdevID, ok := LiveLdevs[Lun]
+		switch {
+		case !ok:
+			Items = append(Items, DriftItem{Object: "LUN", Port: Live.PortID, HostGroup: Live.HostGroupName, Item: strconv.Itoa(Lun), Drift: "missing", Desired: LdevIDFormat(DesiredLdevID)})
+		case LiveLdevID != DesiredLdevID:
+			Items = append(Items, DriftItem{Object: "LUN", Port: Live.PortID, HostGroup: Live.HostGroupName, Item: strconv.Itoa(Lun), Drift: "changed", Desired: LdevIDFormat(DesiredLdevID), Live: LdevIDFormat(LiveLdevID)})
+		}
+	}
+	for _, Lun := range LiveLuns {
+		if _, ok := Desired.Luns[Lun.Lun]; !ok {
+			Items = append(Items, DriftItem{Object: "LUN", Port: Live.PortID, HostGroup: Live.HostGroupName, Item: strconv.Itoa(Lun.Lun), Drift: "extra", Live: LdevIDFormat(Lun.LdevID)})
+		}
+	}
+
+	return Items
+}
+
+//DesiredStateRead reads the state file (YAML). the WWNs are returned lower case without ":" and the ports upper case.
+//The function stops with exit status 82 ("The state file cannot be read.")
+//The function stops with exit status 85 ("The state file is not valid.")
+func DesiredStateRead(FileName string) (DesiredState, bool) {
+	Debug.Println("Function 'DesiredStateRead' started.")
+
+	//initial state is true that means NOK
+	State := true
+
+	var Desired DesiredState
+
+	Content, err := ioutil.ReadFile(FileName)
+	if err != nil {
+		Error.Println("The state file (" + FileName + ") cannot be read: " + err.Error())
+		os.Exit(82)
+	}
+
+	if err := yaml.UnmarshalStrict(Content, &Desired); err != nil {
+		Error.Println("The state file (" + FileName + ") is not valid: " + err.Error())
+		os.Exit(85)
+	}
+
+	WWNPattern := regexp.MustCompile(`^[0-9a-f]{16}$`)
+	Names := map[string]bool{}
+	for i := range Desired.HostGroups {
+		DesiredGroup := &Desired.HostGroups[i]
+		DesiredGroup.Port = strings.ToUpper(strings.TrimSpace(DesiredGroup.Port))
+		if DesiredGroup.Port == "" || DesiredGroup.Name == "" {
+			Error.Println("The state file (" + FileName + ") needs the port and the name of every host group.")
+			os.Exit(85)
+		}
+		if Names[DesiredGroup.Port+","+DesiredGroup.Name] {
+			Error.Println("The state file (" + FileName + ") contains the host group " + DesiredGroup.Port + " " + DesiredGroup.Name + " twice.")
+			os.Exit(85)
+		}
+		Names[DesiredGroup.Port+","+DesiredGroup.Name] = true
+
+		for j, WWN := range DesiredGroup.WWNs {
+			DesiredGroup.WWNs[j] = strings.ToLower(strings.Replace(strings.TrimSpace(WWN), ":", "", -1))
+			if !WWNPattern.MatchString(DesiredGroup.WWNs[j]) {
+				Error.Println("The state file (" + FileName + ") contains the invalid WWN " + WWN + ".")
+				os.Exit(85)
+			}
+		}
+		for Lun, LdevID := range DesiredGroup.Luns {
+			if _, LdevIDState := LdevIDParse(strings.TrimSpace(LdevID)); LdevIDState || Lun < 0 || Lun > ProvisionMaxLun {
+				Error.Println("The state file (" + FileName + ") contains the invalid LUN " + strconv.Itoa(Lun) + ": " + LdevID + " of the host group " + DesiredGroup.Port + " " + DesiredGroup.Name + ".")
+				os.Exit(85)
+			}
+		}
+	}
+
+	Debug.Println("Function 'DesiredStateRead' return values number of host groups:", len(Desired.HostGroups))
+	Debug.Println("Function 'DesiredStateRead' ended.")
+
+	//state to OK
+	State = false
+	return Desired, State
+}
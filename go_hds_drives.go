@@ -0,0 +1,303 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+//DriveInfo type is used for all Drive Element actions
+type DriveInfo struct {
+	DriveLocationID string
+	DriveTypeName   string
+	DriveType       string
+	DriveSpeed      float64
+	TotalCapacity   float64
+	Status          string
+	UsageType       string
+	ParityGroupID   string
+}
+
+//DrivesGet shows all physical drives of the storage and audits the spare coverage
+//return value (string) is empty. if an error happened the state is true. Otherwise false.
+//The function stops with exit status 61 ("JSON parsing error (Return Format is not correct).")
+//example: DrivesGet(p)
+func DrivesGet(p Params) (string, bool) {
+	Debug.Println("Function 'DrivesGet' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//initial state is true that means NOK
+	State := true
+
+	//GET base-URL/v1/objects/storages/storage-device-ID/drives
+	var ConfigurationManagerString string
+	ConfigurationManagerString = "/ConfigurationManager/v1/objects/storages/"
+	var DrivesString string
+	DrivesString = "/drives"
+
+	Info.Println("Get Drive information start")
+
+	/*
+	   https://10.70.5.104/ConfigurationManager/v1/objects/storages/834000470018/drives
+	   {
+	       "data": [{
+	           "driveLocationId": "0-0",
+	           "driveTypeName": "SSD",
+	           "driveSpeed": 0,
+	           "totalCapacity": 1920,
+	           "driveType": "DKR5D-J1R9SS",
+	           "usageType": "DATA",
+	           "status": "NML",
+	           "parityGroupId": "1-1",
+	           "serialNumber": "12345678"
+	       }, {
+	*/
+
+	p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + ConfigurationManagerString + p.StorageDeviceID + DrivesString
+	p.RequestType = "GET"
+
+	var Data []interface{}
+	Data, State = RestDataGet(p, 61)
+
+	Debug.Println("Number of Drives", len(Data))
+
+	//all drives are kept for the audit at the end
+	var Drives []DriveInfo
+
+	//add empty string of strings to collect all drive data to output
+	OutData := [][]string{}
+
+	for _, Value1 := range Data {
+		ParsedMap := Value1.(map[string]interface{})
+
+		var DriveElement DriveInfo
+		DriveElement.DriveLocationID = ElementString(ParsedMap, "driveLocationId")
+		DriveElement.DriveTypeName = ElementString(ParsedMap, "driveTypeName")
+		DriveElement.DriveType = ElementString(ParsedMap, "driveType")
+		DriveElement.DriveSpeed = ElementFloat64(ParsedMap, "driveSpeed")
+		DriveElement.TotalCapacity = ElementFloat64(ParsedMap, "totalCapacity")
+		DriveElement.Status = ElementString(ParsedMap, "status")
+		DriveElement.UsageType = ElementString(ParsedMap, "usageType")
+		DriveElement.ParityGroupID = ElementString(ParsedMap, "parityGroupId")
+
+		Debug.Println("Drive Element: ", DriveElement)
+		Drives = append(Drives, DriveElement)
+
+		OutData, State = DriveInfoFormat(OutData, DriveElement, p)
+	}
+
+	if OutputListFormat(OutData, p) {
+		Warning.Println("The function 'OutputListFormat' returned an Error.")
+	}
+
+	//audit the spare coverage and the drive states
+	DriveAudit(Drives)
+
+	Info.Println("Get Drive information end")
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'DrivesGet' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'DrivesGet' return values State:", State)
+	Debug.Println("Function 'DrivesGet' end")
+
+	//state to OK
+	State = false
+	return "", State
+}
+
+//DriveInfoFormat adds the Drive data to the output data
+func DriveInfoFormat(OutData [][]string, DriveDataSet DriveInfo, p Params) ([][]string, bool) {
+	//initial state is true that means NOK
+	State := true
+
+	var TempData [][]string
+	TempData = OutData
+
+	var ParityGroupID string
+	ParityGroupID = DriveDataSet.ParityGroupID
+	if ParityGroupID == "" {
+		ParityGroupID = "-"
+	}
+
+	//table start line
+	TempData = append(TempData, []string{p.ElementStringStart})
+
+	TempData = append(TempData, []string{HeaderFormat("Location", "string", p), DriveDataSet.DriveLocationID})
+	TempData = append(TempData, []string{HeaderFormat("Drive type", "string", p), DriveDataSet.DriveType})
+	TempData = append(TempData, []string{HeaderFormat("Drive type name", "string", p), DriveDataSet.DriveTypeName})
+	TempData = append(TempData, []string{HeaderFormat("Speed [rpm]", "float64", p), strconv.FormatFloat(DriveDataSet.DriveSpeed, 'f', 0, 64)})
+	TempData = append(TempData, []string{HeaderFormat("Capacity [GB]", "float64", p), strconv.FormatFloat(DriveDataSet.TotalCapacity, 'f', p.RoundPrecision, 64)})
+	TempData = append(TempData, []string{HeaderFormat("Status", "string", p), DriveDataSet.Status})
+	TempData = append(TempData, []string{HeaderFormat("Usage", "string", p), DriveDataSet.UsageType})
+	TempData = append(TempData, []string{HeaderFormat("Parity group", "string", p), ParityGroupID})
+
+	//table end line
+	TempData = append(TempData, []string{p.ElementStringEnd})
+
+	State = false
+	return TempData, State
+}
+
+//DriveAudit warns about drive types without a matching spare drive, about failed drives per drive type and about blocked or copying drives
+//a spare drive matches if it has the same drive type name (ex: SSD), at least the capacity of the data drive and is not failed or blocked
+//return value (int) is the number of warnings
+func DriveAudit(Drives []DriveInfo) int {
+	Debug.Println("Function 'DriveAudit' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	var Warnings int
+	Warnings = 0
+
+	//drive status
+	//"FAI" -> failed, "BLK" -> blocked, "CPY" -> copying, "CPI" -> copy incomplete
+	FailedDrives := map[string][]string{}
+	var DriveTypes []string
+	for _, Drive := range Drives {
+		switch Drive.Status {
+		case "FAI":
+			if FailedDrives[Drive.DriveType] == nil {
+				DriveTypes = append(DriveTypes, Drive.DriveType)
+			}
+			FailedDrives[Drive.DriveType] = append(FailedDrives[Drive.DriveType], Drive.DriveLocationID+" ("+Drive.UsageType+")")
+		case "BLK":
+			Warning.Println("Drive: " + Drive.DriveLocationID + " (" + Drive.DriveType + ") is blocked (status: " + Drive.Status + ").")
+			Warnings = Warnings + 1
+		case "CPY", "CPI":
+			Warning.Println("Drive: " + Drive.DriveLocationID + " (" + Drive.DriveType + ") is copying (status: " + Drive.Status + ").")
+			Warnings = Warnings + 1
+		}
+	}
+
+	//failed drives per drive type
+	for _, DriveType := range DriveTypes {
+		Warning.Println("Drive type: " + DriveType + " has " + strconv.Itoa(len(FailedDrives[DriveType])) + " failed drive(s): " + strings.Join(FailedDrives[DriveType], ", ") + ".")
+		Warnings = Warnings + 1
+	}
+
+	//spare coverage
+	//every drive type is only checked once
+	Checked := map[string]bool{}
+	for _, Drive := range Drives {
+		if Drive.UsageType != "DATA" || Checked[Drive.DriveType] {
+			continue
+		}
+		Checked[Drive.DriveType] = true
+
+		var SpareFound bool
+		SpareFound = false
+		for _, Spare := range Drives {
+			if Spare.UsageType == "SPARE" && Spare.Status != "FAI" && Spare.Status != "BLK" && Spare.DriveTypeName == Drive.DriveTypeName && Spare.TotalCapacity >= Drive.TotalCapacity {
+				SpareFound = true
+				break
+			}
+		}
+
+		if !SpareFound {
+			Warning.Println("Drive type: " + Drive.DriveType + " (" + Drive.DriveTypeName + " " + strconv.FormatFloat(Drive.TotalCapacity, 'f', 0, 64) + "GB) has no matching spare drive.")
+			Warnings = Warnings + 1
+		}
+	}
+
+	if Warnings == 0 {
+		Info.Println("Drive audit: all drive types have a matching spare drive and no drive is failed, blocked or copying.")
+	} else {
+		Info.Println("Drive audit: " + strconv.Itoa(Warnings) + " warning(s).")
+	}
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'DriveAudit' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'DriveAudit' return values Warnings:", Warnings)
+	Debug.Println("Function 'DriveAudit' ended.")
+
+	return Warnings
+}
+
+//ParityGroupInfo type is used for all parity group actions
+//TotalCapacity and AvailableCapacity are in [MB]
+type ParityGroupInfo struct {
+	ParityGroupID     string
+	DriveTypeName     string
+	DriveType         string
+	RaidLevel         string
+	RaidType          string
+	NumOfLdevs        int
+	TotalCapacity     float64
+	AvailableCapacity float64
+}
+
+//ParityGroupsListGet gets all parity groups of the storage
+//return value ([]ParityGroupInfo) are all parity groups. if an error happened the state is true. Otherwise false.
+//The function stops with exit status 62 ("JSON parsing error (Return Format is not correct).")
+//example: ParityGroupsListGet(p)
+func ParityGroupsListGet(p Params) ([]ParityGroupInfo, bool) {
+	Debug.Println("Function 'ParityGroupsListGet' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//initial state is true that means NOK
+	State := true
+
+	var ConfigurationManagerString string
+	ConfigurationManagerString = "/ConfigurationManager/v1/objects/storages/"
+	var ParityGroupsString string
+	ParityGroupsString = "/parity-groups"
+
+	/*
+	   https://10.70.5.104/ConfigurationManager/v1/objects/storages/834000470018/parity-groups
+	   {
+	       "data": [{
+	           "parityGroupId": "1-1",
+	           "numOfLdevs": 4,
+	           "usedCapacityRate": 100,
+	           "availableVolumeCapacity": 0,
+	           "raidLevel": "RAID5",
+	           "raidType": "3D+1P",
+	           "clprId": 0,
+	           "driveType": "DKR5D-J1R9SS",
+	           "driveTypeName": "SSD",
+	           "driveSpeed": 0,
+	           "totalCapacity": 5251
+	       }, {
+	*/
+
+	p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + ConfigurationManagerString + p.StorageDeviceID + ParityGroupsString
+	p.RequestType = "GET"
+
+	var Data []interface{}
+	Data, State = RestDataGet(p, 62)
+
+	var ParityGroups []ParityGroupInfo
+	for _, Value1 := range Data {
+		ParsedMap := Value1.(map[string]interface{})
+
+		var ParityGroupElement ParityGroupInfo
+		ParityGroupElement.ParityGroupID = ElementString(ParsedMap, "parityGroupId")
+		ParityGroupElement.DriveTypeName = ElementString(ParsedMap, "driveTypeName")
+		ParityGroupElement.DriveType = ElementString(ParsedMap, "driveType")
+		ParityGroupElement.RaidLevel = ElementString(ParsedMap, "raidLevel")
+		ParityGroupElement.RaidType = ElementString(ParsedMap, "raidType")
+		ParityGroupElement.NumOfLdevs = int(ElementFloat64(ParsedMap, "numOfLdevs"))
+		//[GB] to [MB]. older versions only return the available capacity
+		ParityGroupElement.AvailableCapacity = ElementFloat64(ParsedMap, "availableVolumeCapacity") * 1024
+		ParityGroupElement.TotalCapacity = ParityGroupElement.AvailableCapacity
+		if ParsedMap["totalCapacity"] != nil {
+			ParityGroupElement.TotalCapacity = ElementFloat64(ParsedMap, "totalCapacity") * 1024
+		}
+
+		ParityGroups = append(ParityGroups, ParityGroupElement)
+	}
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'ParityGroupsListGet' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'ParityGroupsListGet' return values number of parity groups:", len(ParityGroups))
+	Debug.Println("Function 'ParityGroupsListGet' ended.")
+
+	//state to OK
+	State = false
+	return ParityGroups, State
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//HCSStorageInfo type is a storage system registered in the HCS Configuration Manager
+type HCSStorageInfo struct {
+	StorageDeviceID string `json:"-"`
+	SvpIP           string `json:"svpIp"`
+	SerialNumber    int    `json:"serialNumber"`
+	Model           string `json:"model"`
+}
+
+//HCSStoragesListGet gets all storage systems registered in the HCS Configuration Manager
+//return value ([]HCSStorageInfo) are all storage systems. if an error happened the state is true. Otherwise false.
+//The function stops with exit status 11 ("JSON parsing error (Return Format is not correct).")
+//example: HCSStoragesListGet(p)
+func HCSStoragesListGet(p Params) ([]HCSStorageInfo, bool) {
+	Debug.Println("Function 'HCSStoragesListGet' started.")
+
+	//initial state is true that means NOK
+	State := true
+
+	var Storages []HCSStorageInfo
+
+	p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + "/ConfigurationManager/v1/objects/storages"
+	p.RequestType = "GET"
+
+	var Data []interface{}
+	Data, State = RestDataGet(p, 11)
+	for _, Value1 := range Data {
+		ParsedMap := Value1.(map[string]interface{})
+
+		Storages = append(Storages, HCSStorageInfo{
+			StorageDeviceID: ElementString(ParsedMap, "storageDeviceId"),
+			SvpIP:           ElementString(ParsedMap, "svpIp"),
+			SerialNumber:    int(ElementFloat64(ParsedMap, "serialNumber")),
+			Model:           ElementString(ParsedMap, "model"),
+		})
+	}
+
+	Debug.Println("Function 'HCSStoragesListGet' return values number of storages:", len(Storages))
+	Debug.Println("Function 'HCSStoragesListGet' ended.")
+
+	//state to OK
+	State = false
+	return Storages, State
+}
+
+//HCSStorageRegister registers a storage system in the HCS Configuration Manager and waits for the registration job
+//return value (string) is the storageDeviceId of the registered storage or the error. if an error happened the state is true. Otherwise false.
+//example: HCSStorageRegister(p, HCSStorageInfo{SvpIP: "10.0.0.1", SerialNumber: 470018, Model: "VSP G600"})
+func HCSStorageRegister(p Params, Storage HCSStorageInfo) (string, bool) {
+	Debug.Println("Function 'HCSStorageRegister' started.")
+
+	//initial state is true that means NOK
+	State := true
+
+	JSONByt, err := json.Marshal(Storage)
+	if err != nil {
+		return "The request body cannot be created: " + err.Error(), State
+	}
+
+	//curl -kv -H "Accept:application/json" -H "Content-Type:application/json" -u raidcom:raidcom -X POST --data-binary "@./g600.txt" https://10.70.4.84:23451/ConfigurationManager/v1/objects/storages
+	p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + "/ConfigurationManager/v1/objects/storages"
+	p.RequestType = "POST"
+	p.RequestBody = string(JSONByt)
+	Debug.Println("JSON Request body:" + p.RequestBody)
+
+	//the registration is a job. the registered storage is the affected resource
+	//"affectedResources": ["/ConfigurationManager/v1/objects/storages/834000470018"]
+	var Job JobInfo
+	Job, State = JobRequest(p)
+	if State {
+		return "The registration of the storage " + strconv.Itoa(Storage.SerialNumber) + " failed: " + JobErrorFormat(Job), State
+	}
+
+	StorageDeviceID := JobResourceID(Job)
+	if StorageDeviceID == "" {
+		return "The registration job of the storage " + strconv.Itoa(Storage.SerialNumber) + " did not return the storageDeviceId.", true
+	}
+
+	Debug.Println("Function 'HCSStorageRegister' return values StorageDeviceID:", StorageDeviceID)
+	Debug.Println("Function 'HCSStorageRegister' ended.")
+
+	//state to OK
+	State = false
+	return StorageDeviceID, State
+}
+
+//HCSStorageUnregister removes a storage system from the HCS Configuration Manager and waits for the job
+//return value (string) is empty or the error. if an error happened the state is true. Otherwise false.
+//example: HCSStorageUnregister(p, "834000470018")
+func HCSStorageUnregister(p Params, StorageDeviceID string) (string, bool) {
+	Debug.Println("Function 'HCSStorageUnregister' started.")
+
+	//initial state is true that means NOK
+	State := true
+
+	p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + "/ConfigurationManager/v1/objects/storages/" + StorageDeviceID
+	p.RequestType = "DELETE"
+	p.RequestBody = ""
+
+	var Job JobInfo
+	Job, State = JobRequest(p)
+	if State {
+		return "The unregistration of the storage " + StorageDeviceID + " failed: " + JobErrorFormat(Job), State
+	}
+
+	Debug.Println("Function 'HCSStorageUnregister' ended.")
+
+	//state to OK
+	State = false
+	return "", State
+}
+
+//HCSStoragesArgsGet returns the storage systems to register or unregister from the storage file (-storagefile) or the -svpip, -serial and -model flags
+//The function stops with exit status 74 ("The storage file cannot be read.")
+//The function stops with exit status 75 ("The storage file contains an invalid line.")
+func HCSStoragesArgsGet(p Params) ([]HCSStorageInfo, bool) {
+	Debug.Println("Function 'HCSStoragesArgsGet' started.")
+
+	//initial state is true that means NOK
+	State := true
+
+	if p.StorageFile == "" {
+		return []HCSStorageInfo{{SvpIP: p.SvpIP, SerialNumber: p.Serial, Model: p.Model}}, false
+	}
+
+	File, err := os.Open(p.StorageFile)
+	if err != nil {
+		Error.Println("The storage file (" + p.StorageFile + ") cannot be read: " + err.Error())
+		os.Exit(74)
+	}
+	defer File.Close()
+
+	Reader := csv.NewReader(File)
+	Reader.Comment = '#'
+	Reader.FieldsPerRecord = 3
+	Records, err := Reader.ReadAll()
+	if err != nil {
+		Error.Println("The storage file (" + p.StorageFile + ") contains an invalid line: " + err.Error())
+		os.Exit(75)
+	}
+
+	var Storages []HCSStorageInfo
+	for _, Record := range Records {
+		Serial, err := strconv.Atoi(strings.TrimSpace(Record[1]))
+		if err != nil {
+			Error.Println("The storage file (" + p.StorageFile + ") contains an invalid serial number: " + Record[1])
+			os.Exit(75)
+		}
+		Storages = append(Storages, HCSStorageInfo{SvpIP: strings.TrimSpace(Record[0]), SerialNumber: Serial, Model: strings.TrimSpace(Record[2])})
+	}
+
+	Debug.Println("Function 'HCSStoragesArgsGet' return values number of storages:", len(Storages))
+	Debug.Println("Function 'HCSStoragesArgsGet' ended.")
+
+	//state to OK
+	State = false
+	return Storages, State
+}
+
+//HCSRegister registers all storage systems of the flags or the storage file in the HCS Configuration Manager
+//storage systems with a serial number that is already registered are skipped.
+//return value (int) is the number of failed registrations. if an error happened the state is true. Otherwise false.
+//example: HCSRegister(p)
+func HCSRegister(p Params) (int, bool) {
+	Debug.Println("Function 'HCSRegister' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	Info.Println("Register storage systems start")
+
+	Storages, _ := HCSStoragesArgsGet(p)
+	Registered, _ := HCSStoragesListGet(p)
+
+	//add empty string of strings to collect all registration data to output
+	OutData := [][]string{}
+	var Failed int
+	Failed = 0
+	for _, Storage := range Storages {
+		var Result string
+		var StorageDeviceID string
+		for _, Existing := range Registered {
+			if Existing.SerialNumber == Storage.SerialNumber {
+				StorageDeviceID = Existing.StorageDeviceID
+			}
+		}
+
+		if StorageDeviceID != "" {
+			Verbose.Println("The storage " + strconv.Itoa(Storage.SerialNumber) + " is already registered. It is skipped.")
+			Result = "already registered"
+		} else {
+			Verbose.Println("Register the storage " + strconv.Itoa(Storage.SerialNumber) + " (" + Storage.Model + " " + Storage.SvpIP + ")")
+			Out, State := HCSStorageRegister(p, Storage)
+			if State {
+				Warning.Println(Out)
+				Result = "failed"
+				StorageDeviceID = "-"
+				Failed = Failed + 1
+			} else {
+				Result = "registered"
+				StorageDeviceID = Out
+			}
+		}
+
+		OutData = append(OutData, []string{p.ElementStringStart})
+		OutData = append(OutData, []string{HeaderFormat("Serial", "string", p), strconv.Itoa(Storage.SerialNumber)})
+		OutData = append(OutData, []string{HeaderFormat("Model", "string", p), Storage.Model})
+		OutData = append(OutData, []string{HeaderFormat("SVP IP", "string", p), Storage.SvpIP})
+		OutData = append(OutData, []string{HeaderFormat("Storage device ID", "string", p), StorageDeviceID})
+		OutData = append(OutData, []string{HeaderFormat("Result", "string", p), Result})
+		OutData = append(OutData, []string{p.ElementStringEnd})
+	}
+
+	if OutputListFormat(OutData, p) {
+		Warning.Println("The function 'OutputListFormat' returned an Error.")
+	}
+
+	Info.Println("Register storage systems end")
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'HCSRegister' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'HCSRegister' return values Failed:", Failed)
+	Debug.Println("Function 'HCSRegister' ended.")
+
+	return Failed, Failed > 0
+}
+
+//HCSUnregister removes all storage systems of the flags or the storage file (by serial number) from the HCS Configuration Manager
+//storage systems that are not registered are skipped.
+//return value (int) is the number of failed unregistrations. if an error happened the state is true. Otherwise false.
+//example: HCSUnregister(p)
+func HCSUnregister(p Params) (int, bool) {
+	Debug.Println("Function 'HCSUnregister' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	Info.Println("Unregister storage systems start")
+
+	Storages, _ := HCSStoragesArgsGet(p)
+	Registered, _ := HCSStoragesListGet(p)
+
+	//add empty string of strings to collect all unregistration data to output
+	OutData := [][]string{}
+	var Failed int
+	Failed = 0
+	for _, Storage := range Storages {
+		var Result string
+		var StorageDeviceID string
+		StorageDeviceID = "-"
+		Result = "not registered"
+		for _, Existing := range Registered {
+			if Existing.SerialNumber != Storage.SerialNumber {
+				continue
+			}
+			StorageDeviceID = Existing.StorageDeviceID
+			Verbose.Println("Unregister the storage " + strconv.Itoa(Storage.SerialNumber) + " (" + StorageDeviceID + ")")
+			if Out, State := HCSStorageUnregister(p, StorageDeviceID); State {
+				Warning.Println(Out)
+				Result = "failed"
+				Failed = Failed + 1
+			} else {
+				Result = "unregistered"
+			}
+		}
+
+		OutData = append(OutData, []string{p.ElementStringStart})
+		OutData = append(OutData, []string{HeaderFormat("Serial", "string", p), strconv.Itoa(Storage.SerialNumber)})
+		OutData = append(OutData, []string{HeaderFormat("Storage device ID", "string", p), StorageDeviceID})
+		OutData = append(OutData, []string{HeaderFormat("Result", "string", p), Result})
+		OutData = append(OutData, []string{p.ElementStringEnd})
+	}
+
+	if OutputListFormat(OutData, p) {
+		Warning.Println("The function 'OutputListFormat' returned an Error.")
+	}
+
+	Info.Println("Unregister storage systems end")
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'HCSUnregister' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'HCSUnregister' return values Failed:", Failed)
+	Debug.Println("Function 'HCSUnregister' ended.")
+
+	return Failed, Failed > 0
+}
+
+//HCSList shows all storage systems registered in the HCS Configuration Manager
+//return value (string) is empty. if an error happened the state is true. Otherwise false.
+//example: HCSList(p)
+func HCSList(p Params) (string, bool) {
+	Debug.Println("Function 'HCSList' started.")
+
+	Info.Println("Get registered storage systems start")
+
+	Storages, State := HCSStoragesListGet(p)
+
+	//add empty string of strings to collect all storage data to output
+	OutData := [][]string{}
+	for _, Storage := range Storages {
+		OutData = append(OutData, []string{p.ElementStringStart})
+		OutData = append(OutData, []string{HeaderFormat("Storage device ID", "string", p), Storage.StorageDeviceID})
+		OutData = append(OutData, []string{HeaderFormat("Model", "string", p), Storage.Model})
+		OutData = append(OutData, []string{HeaderFormat("Serial", "string", p), strconv.Itoa(Storage.SerialNumber)})
+		OutData = append(OutData, []string{HeaderFormat("SVP IP", "string", p), Storage.SvpIP})
+		OutData = append(OutData, []string{p.ElementStringEnd})
+	}
+
+	if len(Storages) == 0 {
+		Info.Println("No storage system registered.")
+	} else {
+		if OutputListFormat(OutData, p) {
+			Warning.Println("The function 'OutputListFormat' returned an Error.")
+		}
+	}
+
+	Info.Println("Get registered storage systems end")
+	Debug.Println("Function 'HCSList' ended.")
+
+	return "", State
+}
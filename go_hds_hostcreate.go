@@ -0,0 +1,494 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+//HostDefinition type is the host file (-hostfile) of a server
+//the host mode and the host mode options are taken from the OS if they are not set. HostGroupName is taken from the naming of the existing host groups if it is not set.
+/*
+   name: esx01
+   os: vmware
+   fabrics:
+     A:
+       wwns: ["10:00:00:90:fa:00:00:01"]
+       ports: [CL1-A, CL3-A]
+     B:
+       wwns: ["10:00:00:90:fa:00:00:02"]
+       ports: [CL2-A, CL4-A]
+*/
+type HostDefinition struct {
+	Name            string                `yaml:"name"`
+	OS              string                `yaml:"os"`
+	HostGroupName   string                `yaml:"hostGroupName"`
+	HostMode        string                `yaml:"hostMode"`
+	HostModeOptions []int                 `yaml:"hostModeOptions"`
+	Fabrics         map[string]HostFabric `yaml:"fabrics"`
+}
+
+//HostFabric type are the WWNs of a host in one fabric and the storage ports of the fabric
+type HostFabric struct {
+	WWNs  []string `yaml:"wwns"`
+	Ports []string `yaml:"ports"`
+}
+
+//HostStep type is one step to create a host. Action is "create host group", "add WWN" or "none" (exists)
+//Status is "planned" (dry-run), "exists", "done", "failed", "skipped" (the host group could not be created) or the conflict with the existing configuration
+type HostStep struct {
+	Fabric    string
+	HostGroup HostGroupInfo
+	Action    string
+	WWN       string
+	Details   string
+	Status    string
+}
+
+//HostOSModes are the host mode and the host mode options of an OS
+var HostOSModes = map[string]HostGroupInfo{
+	"linux":   {HostMode: "LINUX/IRIX"},
+	"vmware":  {HostMode: "VMWARE_EX", HostModeOptions: []int{54, 63, 114}},
+	"windows": {HostMode: "WIN_EX", HostModeOptions: []int{40, 73}},
+	"aix":     {HostMode: "AIX"},
+	"solaris": {HostMode: "SOLARIS"},
+	"hp-ux":   {HostMode: "HP-UX"},
+}
+
+//HostCreate creates the host groups and registers the WWNs of a host (-hostfile, read by HostDefinitionRead before the session is opened) that are missing
+//a host group of the host on a port is found by its name or by one of the WWNs of the host. Existing host groups and WWNs are not changed. So a rerun changes nothing.
+//without -execute nothing is changed (dry-run). With -execute the resources are locked and every job is waited for.
+//return value (int) is the number of failed steps. if an error happened the state is true. Otherwise false.
+//example: HostCreate(p, Host)
+func HostCreate(p Params, Host HostDefinition) (int, bool) {
+	Debug.Println("Function 'HostCreate' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//initial state is true that means NOK
+	State := true
+
+	if p.Execute {
+		Info.Println("Create host " + Host.Name + " start")
+	} else {
+		Info.Println("Create host " + Host.Name + " start (dry-run. use -execute to create the host)")
+	}
+
+	var HostGroups []HostGroupInfo
+	HostGroups, State = HostGroupsListGet(p)
+
+	//the host group name follows the naming of the existing host groups
+	var Template string
+	Template = Host.HostGroupName
+	if Template == "" {
+		Template = HostGroupNameTemplate(HostGroups)
+		Verbose.Println("Host group naming of the existing host groups: " + Template)
+	}
+
+	var Steps []HostStep
+	Steps = HostStepsPlan(p, Host, Template, HostGroups)
+
+	if p.Execute {
+		_, LockState := ResourceLock(p)
+
+		//the host group number of the created host groups. key: port
+		Created := map[string]HostGroupInfo{}
+		for i := range Steps {
+			Step := &Steps[i]
+			if Step.Action == "none" {
+				continue
+			}
+			//no changes if the resources cannot be locked
+			if LockState {
+				Step.Status = "skipped"
+				continue
+			}
+
+			if Step.Action == "create host group" {
+				Job, JobState := HostGroupCreate(p, Step.HostGroup)
+				if JobState {
+					Step.Status = "failed " + JobErrorFormat(Job)
+					continue
+				}
+				//"affectedResources": ["/ConfigurationManager/v1/objects/storages/834000470018/host-groups/CL1-A,5"]
+				Parts := strings.Split(JobResourceID(Job), ",")
+				Number, err := strconv.Atoi(Parts[len(Parts)-1])
+				if err != nil {
+					Step.Status = "failed the job did not return the host group number"
+					continue
+				}
+				Step.HostGroup.HostGroupNumber = Number
+				Step.Status = "done"
+				Created[Step.HostGroup.PortID] = Step.HostGroup
+				continue
+			}
+
+			//add WWN to a created host group
+			if Step.HostGroup.HostGroupNumber < 0 {
+				HostGroup, ok := Created[Step.HostGroup.PortID]
+				if !ok {
+					Step.Status = "skipped"
+					continue
+				}
+				Step.HostGroup = HostGroup
+			}
+			Job, JobState := HostWWNAdd(p, Step.HostGroup, Step.WWN)
+			if JobState {
+				Step.Status = "failed " + JobErrorFormat(Job)
+				continue
+			}
+			Step.Status = "done"
+		}
+
+		if !LockState {
+			ResourceUnlock(p)
+		}
+	}
+
+	var Changes int
+	Changes = 0
+	var Failed int
+	Failed = 0
+	OutData := [][]string{}
+	for _, Step := range Steps {
+		if Step.Action != "none" {
+			Changes = Changes + 1
+		}
+		if strings.HasPrefix(Step.Status, "failed") || Step.Status == "skipped" || strings.HasPrefix(Step.Status, "conflict") {
+			Failed = Failed + 1
+		}
+
+		var HostGroupNumber string
+		HostGroupNumber = "new"
+		if Step.HostGroup.HostGroupNumber >= 0 {
+			HostGroupNumber = strconv.Itoa(Step.HostGroup.HostGroupNumber)
+		}
+
+		OutData = append(OutData, []string{p.ElementStringStart})
+		OutData = append(OutData, []string{HeaderFormat("Fabric", "string", p), Step.Fabric})
+		OutData = append(OutData, []string{HeaderFormat("Port", "string", p), Step.HostGroup.PortID})
+		OutData = append(OutData, []string{HeaderFormat("Host group number", "string", p), HostGroupNumber})
+		OutData = append(OutData, []string{HeaderFormat("Host group name", "string", p), Step.HostGroup.HostGroupName})
+		OutData = append(OutData, []string{HeaderFormat("Action", "string", p), Step.Action})
+		OutData = append(OutData, []string{HeaderFormat("WWN", "string", p), Step.WWN})
+		OutData = append(OutData, []string{HeaderFormat("Details", "string", p), Step.Details})
+		OutData = append(OutData, []string{HeaderFormat("Status", "string", p), Step.Status})
+		OutData = append(OutData, []string{p.ElementStringEnd})
+	}
+	if OutputListFormat(OutData, p) {
+		Warning.Println("The function 'OutputListFormat' returned an Error.")
+	}
+
+	if p.Execute {
+		Info.Println("Changes: " + strconv.Itoa(Changes-Failed) + " of " + strconv.Itoa(Changes) + " done")
+	} else {
+		Info.Println("Changes: " + strconv.Itoa(Changes))
+	}
+	Info.Println("Create host " + Host.Name + " end")
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'HostCreate' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'HostCreate' return values Failed:", Failed)
+	Debug.Println("Function 'HostCreate' ended.")
+
+	//state to OK
+	State = false
+	return Failed, State
+}
+
+//HostStepsPlan returns the steps to create the host on all ports of all fabrics
+//a WWN that is registered in another host group of the port is a conflict. an existing host group with another host mode is only shown.
+func HostStepsPlan(p Params, Host HostDefinition, Template string, HostGroups []HostGroupInfo) []HostStep {
+	Debug.Println("Function 'HostStepsPlan' started.")
+
+	//host mode of the host
+	var Mode HostGroupInfo
+	Mode = HostOSModes[strings.ToLower(Host.OS)]
+	if Host.HostMode != "" {
+		Mode.HostMode = Host.HostMode
+	}
+	if Host.HostModeOptions != nil {
+		Mode.HostModeOptions = Host.HostModeOptions
+	}
+
+	//the fabrics in a stable order
+	var Fabrics []string
+	for Fabric := range Host.Fabrics {
+		Fabrics = append(Fabrics, Fabric)
+	}
+	sort.Strings(Fabrics)
+
+	var Steps []HostStep
+	for _, Fabric := range Fabrics {
+		for _, Port := range Host.Fabrics[Fabric].Ports {
+			var Name string
+			Name = HostGroupNameFormat(Template, Port, Host.Name)
+
+			//the WWNs of all host groups of the port
+			PortWWNs := map[string]HostGroupInfo{}
+			var Existing HostGroupInfo
+			Existing.HostGroupNumber = -1
+			for _, HostGroup := range HostGroups {
+				if HostGroup.PortID != Port {
+					continue
+				}
+				WWNs, _ := HostWWNsListGet(p, HostGroup)
+				for _, WWN := range WWNs {
+					PortWWNs[WWN] = HostGroup
+				}
+				if HostGroup.HostGroupName == Name {
+					Existing = HostGroup
+				}
+			}
+
+			//a host group with another name that contains a WWN of the host is the host group of the host
+			if Existing.HostGroupNumber < 0 {
+				for _, WWN := range Host.Fabrics[Fabric].WWNs {
+					if HostGroup, ok := PortWWNs[WWN]; ok {
+						Existing = HostGroup
+						Verbose.Println("The host group " + HostGroup.HostGroupName + " on " + Port + " contains the WWN " + WWN + ". It is used for the host.")
+						break
+					}
+				}
+			}
+
+			if Existing.HostGroupNumber < 0 {
+				Existing = HostGroupInfo{PortID: Port, HostGroupNumber: -1, HostGroupName: Name, HostMode: Mode.HostMode, HostModeOptions: Mode.HostModeOptions}
+				Steps = append(Steps, HostStep{Fabric: Fabric, HostGroup: Existing, Action: "create host group", Details: HostModeFormat(Existing), Status: "planned"})
+			} else {
+				Step := HostStep{Fabric: Fabric, HostGroup: Existing, Action: "none", Details: HostModeFormat(Existing), Status: "exists"}
+				if HostModeFormat(Existing) != HostModeFormat(Mode) {
+					Step.Status = "exists (the host mode differs from " + HostModeFormat(Mode) + ". it is not changed)"
+					Warning.Println("The host mode of the host group " + Existing.HostGroupName + " on " + Port + " is " + HostModeFormat(Existing) + ". Expected is " + HostModeFormat(Mode) + ".")
+				}
+				Steps = append(Steps, Step)
+			}
+
+			for _, WWN := range Host.Fabrics[Fabric].WWNs {
+				HostGroup, ok := PortWWNs[WWN]
+				switch {
+				case !ok:
+					Steps = append(Steps, HostStep{Fabric: Fabric, HostGroup: Existing, Action: "add WWN", WWN: WWN, Status: "planned"})
+				case HostGroup.HostGroupNumber == Existing.HostGroupNumber:
+					Steps = append(Steps, HostStep{Fabric: Fabric, HostGroup: Existing, Action: "none", WWN: WWN, Status: "exists"})
+				default:
+					Steps = append(Steps, HostStep{Fabric: Fabric, HostGroup: Existing, Action: "none", WWN: WWN, Status: "conflict: the WWN is registered in the host group " + HostGroup.HostGroupName + " (" + strconv.Itoa(HostGroup.HostGroupNumber) + ")"})
+				}
+			}
+		}
+	}
+
+	Debug.Println("Function 'HostStepsPlan' return values number of steps:", len(Steps))
+	Debug.Println("Function 'HostStepsPlan' ended.")
+	return Steps
+}
+
+//HostGroupNameTemplate returns the naming of the existing host groups. "{name}" is the host name, "{port}" the port (ex: CL1-A) and "{portshort}" the short port (ex: 1A).
+//the naming used by most host groups is returned. host group 0 has the default name of the storage and is not used. Without host groups it is "{name}".
+//example: "1A_esx01" -> "{portshort}_{name}"
+func HostGroupNameTemplate(HostGroups []HostGroupInfo) string {
+	Votes := map[string]int{}
+	for _, HostGroup := range HostGroups {
+		if HostGroup.HostGroupNumber == 0 {
+			continue
+		}
+
+		var Template string
+		Template = "{name}"
+		for _, Token := range []string{"{port}", "{portshort}"} {
+			var Port string
+			Port = strings.Replace(Token, "{port}", HostGroup.PortID, 1)
+			Port = strings.Replace(Port, "{portshort}", HostPortShort(HostGroup.PortID), 1)
+			for _, Separator := range []string{"_", "-", "."} {
+				if strings.HasPrefix(HostGroup.HostGroupName, Port+Separator) {
+					Template = Token + Separator + "{name}"
+				}
+				if strings.HasSuffix(HostGroup.HostGroupName, Separator+Port) {
+					Template = "{name}" + Separator + Token
+				}
+			}
+		}
+		Votes[Template] = Votes[Template] + 1
+	}
+
+	var Templates []string
+	for Template := range Votes {
+		Templates = append(Templates, Template)
+	}
+	sort.Strings(Templates)
+
+	var Best string
+	Best = "{name}"
+	for _, Template := range Templates {
+		if Votes[Template] > Votes[Best] {
+			Best = Template
+		}
+	}
+	return Best
+}
+
+//HostGroupNameFormat returns the host group name of a host on a port
+//example: HostGroupNameFormat("{portshort}_{name}", "CL1-A", "esx01") -> "1A_esx01"
+func HostGroupNameFormat(Template string, Port string, Name string) string {
+	return strings.NewReplacer("{name}", Name, "{portshort}", HostPortShort(Port), "{port}", Port).Replace(Template)
+}
+
+//HostPortShort returns the short name of a port. example: "CL1-A" -> "1A"
+func HostPortShort(Port string) string {
+	return strings.Replace(strings.TrimPrefix(Port, "CL"), "-", "", -1)
+}
+
+//HostModeFormat returns the host mode with its options. example: "VMWARE_EX [54 63 114]"
+func HostModeFormat(HostGroup HostGroupInfo) string {
+	Options := append([]int{}, HostGroup.HostModeOptions...)
+	sort.Ints(Options)
+
+	var OptionStrings []string
+	for _, Option := range Options {
+		OptionStrings = append(OptionStrings, strconv.Itoa(Option))
+	}
+	return HostGroup.HostMode + " [" + strings.Join(OptionStrings, " ") + "]"
+}
+
+//HostWWNsListGet gets the WWNs registered in a host group. the WWNs are lower case without ":"
+//The function stops with exit status 41 ("JSON parsing error (Return Format is not correct).")
+//example: HostWWNsListGet(p, HostGroup)
+func HostWWNsListGet(p Params, HostGroup HostGroupInfo) ([]string, bool) {
+	//initial state is true that means NOK
+	State := true
+
+	/*
+	   https://10.70.5.104/ConfigurationManager/v1/objects/storages/834000470018/host-wwns?portId=CL1-A&hostGroupNumber=5
+	   {
+	       "data": [{
+	           "hostWwnId": "CL1-A,5,10000090fa000001",
+	           "portId": "CL1-A",
+	           "hostGroupNumber": 5,
+	           "hostGroupName": "1A_esx01",
+	           "hostWwn": "10000090fa000001",
+	           "wwnNickname": "-"
+	       }]
+	   }
+	*/
+
+	p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + "/ConfigurationManager/v1/objects/storages/" + p.StorageDeviceID + "/host-wwns?portId=" + HostGroup.PortID + "&hostGroupNumber=" + strconv.Itoa(HostGroup.HostGroupNumber)
+	p.RequestType = "GET"
+
+	var Data []interface{}
+	Data, State = RestDataGet(p, 41)
+
+	var WWNs []string
+	for _, Value1 := range Data {
+		ParsedMap := Value1.(map[string]interface{})
+		WWNs = append(WWNs, strings.ToLower(ElementString(ParsedMap, "hostWwn")))
+	}
+
+	//state to OK
+	State = false
+	return WWNs, State
+}
+
+//HostGroupCreate creates a host group with its host mode and host mode options and waits for the job. the new host group is the affected resource of the job.
+//example: HostGroupCreate(p, HostGroupInfo{PortID: "CL1-A", HostGroupName: "1A_esx01", HostMode: "VMWARE_EX", HostModeOptions: []int{54, 63}})
+func HostGroupCreate(p Params, HostGroup HostGroupInfo) (JobInfo, bool) {
+	Verbose.Println("Create the host group " + HostGroup.HostGroupName + " on " + HostGroup.PortID)
+
+	Body := map[string]interface{}{
+		"portId":        HostGroup.PortID,
+		"hostGroupName": HostGroup.HostGroupName,
+		"hostMode":      HostGroup.HostMode,
+	}
+	if len(HostGroup.HostModeOptions) > 0 {
+		Body["hostModeOptions"] = HostGroup.HostModeOptions
+	}
+	JSONByt, _ := json.Marshal(Body)
+
+	p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + "/ConfigurationManager/v1/objects/storages/" + p.StorageDeviceID + "/host-groups"
+	p.RequestType = "POST"
+	p.RequestBody = string(JSONByt)
+
+	return JobRequest(p)
+}
+
+//HostWWNAdd registers a WWN in a host group and waits for the job
+//example: HostWWNAdd(p, HostGroup, "10000090fa000001")
+func HostWWNAdd(p Params, HostGroup HostGroupInfo, WWN string) (JobInfo, bool) {
+	Verbose.Println("Add the WWN " + WWN + " to the host group " + HostGroup.HostGroupName + " on " + HostGroup.PortID)
+
+	JSONByt, _ := json.Marshal(map[string]interface{}{
+		"hostWwn":         WWN,
+		"portId":          HostGroup.PortID,
+		"hostGroupNumber": HostGroup.HostGroupNumber,
+	})
+
+	p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + "/ConfigurationManager/v1/objects/storages/" + p.StorageDeviceID + "/host-wwns"
+	p.RequestType = "POST"
+	p.RequestBody = string(JSONByt)
+
+	return JobRequest(p)
+}
+
+//HostDefinitionRead reads the host file (YAML). the WWNs are returned lower case without ":"
+//The function stops with exit status 78 ("The host file cannot be read.")
+//The function stops with exit status 79 ("The host file is not valid.")
+func HostDefinitionRead(FileName string) (HostDefinition, bool) {
+	Debug.Println("Function 'HostDefinitionRead' started.")
+
+	//initial state is true that means NOK
+	State := true
+
+	var Host HostDefinition
+
+	Content, err := ioutil.ReadFile(FileName)
+	if err != nil {
+		Error.Println("The host file (" + FileName + ") cannot be read: " + err.Error())
+		os.Exit(78)
+	}
+
+	if err := yaml.UnmarshalStrict(Content, &Host); err != nil {
+		Error.Println("The host file (" + FileName + ") is not valid: " + err.Error())
+		os.Exit(79)
+	}
+
+	if Host.Name == "" || len(Host.Fabrics) == 0 {
+		Error.Println("The host file (" + FileName + ") needs the name and at least one fabric.")
+		os.Exit(79)
+	}
+	if _, ok := HostOSModes[strings.ToLower(Host.OS)]; !ok && Host.HostMode == "" {
+		Error.Println("The host file (" + FileName + ") contains the unknown OS '" + Host.OS + "'. Specify the hostMode.")
+		os.Exit(79)
+	}
+
+	WWNPattern := regexp.MustCompile(`^[0-9a-f]{16}$`)
+	for Name, Fabric := range Host.Fabrics {
+		if len(Fabric.WWNs) == 0 || len(Fabric.Ports) == 0 {
+			Error.Println("The fabric " + Name + " of the host file (" + FileName + ") needs the wwns and the ports.")
+			os.Exit(79)
+		}
+		for i, WWN := range Fabric.WWNs {
+			Fabric.WWNs[i] = strings.ToLower(strings.Replace(strings.TrimSpace(WWN), ":", "", -1))
+			if !WWNPattern.MatchString(Fabric.WWNs[i]) {
+				Error.Println("The host file (" + FileName + ") contains the invalid WWN " + WWN + ".")
+				os.Exit(79)
+			}
+		}
+		for i, Port := range Fabric.Ports {
+			Fabric.Ports[i] = strings.ToUpper(strings.TrimSpace(Port))
+		}
+	}
+
+	Debug.Println("Function 'HostDefinitionRead' return values Host:", Host)
+	Debug.Println("Function 'HostDefinitionRead' ended.")
+
+	//state to OK
+	State = false
+	return Host, State
+}
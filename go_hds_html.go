@@ -0,0 +1,278 @@
+package main
+
+import (
+	"html"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//HTMLSections are the sections of the html output. every report is one section. the file (-outputfile) is written after every section.
+var HTMLSections []string
+
+//HTMLCreated is the time of the first section of the html output
+var HTMLCreated time.Time
+
+//HTMLStyle is the style sheet of the html output. the file has no external assets.
+const HTMLStyle string = `body { font-family: Arial, Helvetica, sans-serif; font-size: 13px; color: #222; margin: 24px; }
+h1 { font-size: 20px; margin-bottom: 4px; }
+h2 { font-size: 16px; margin-top: 28px; border-bottom: 1px solid #ccc; }
+h3 { font-size: 13px; margin: 16px 0 4px 0; }
+.meta { color: #666; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 3px 8px; }
+th { background: #ddebf7; text-align: left; }
+td.num { text-align: right; }
+.bar { position: relative; width: 160px; height: 16px; background: #eee; }
+.bar div { height: 16px; }
+.bar span { position: absolute; top: 0; left: 4px; line-height: 16px; }
+.ok { background: #9fd89f; }
+.warning { background: #ffd966; }
+.depletion { background: #f4a09c; }`
+
+//HTMLChartWidth and HTMLChartHeight are the size of the trend charts [px]
+const HTMLChartWidth int = 640
+const HTMLChartHeight int = 200
+
+//OutputHTML adds the data as table to the html output and writes it to the output file (-outputfile). Every element is one row.
+//The section is named after the report (p.ReportName) or the type (-type). The descriptors of the first element without the type are the header.
+//"Utilization [%]" is shown as bar coloured by the "Warning threshold [%]" and the "Depletion threshold [%]" of the same row.
+func OutputHTML(Data [][]string, p Params) bool {
+	Debug.Println("Function 'OutputHTML' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	// if no data is available skip output
+	if len(Data) == 0 {
+		Error.Println("No Data to output.")
+		return false
+	}
+
+	//header and rows
+	var Header []string
+	var Types []string
+	var Rows [][]string
+	var Row []string
+	var HeaderDone bool
+	HeaderDone = false
+	for i := 0; i < len(Data); i++ {
+		switch Data[i][0] {
+		case p.ElementStringStart:
+			Row = []string{}
+		case p.ElementStringEnd:
+			HeaderDone = true
+			Rows = append(Rows, Row)
+		default:
+			if !HeaderDone {
+				Key, Type := HeaderSplit(Data[i][0])
+				Header = append(Header, Key)
+				Types = append(Types, Type)
+			}
+			Row = append(Row, Data[i][1])
+		}
+	}
+
+	UtilizationColumn := HTMLColumn(Header, "Utilization [%]")
+	WarningColumn := HTMLColumn(Header, "Warning threshold [%]")
+	DepletionColumn := HTMLColumn(Header, "Depletion threshold [%]")
+
+	var Table strings.Builder
+	Table.WriteString("<table>\n<tr>")
+	for _, Key := range Header {
+		Table.WriteString("<th>" + html.EscapeString(Key) + "</th>")
+	}
+	Table.WriteString("</tr>\n")
+	for _, Row := range Rows {
+		Table.WriteString("<tr>")
+		for Column, Value := range Row {
+			switch {
+			case Column == UtilizationColumn && WarningColumn >= 0 && DepletionColumn >= 0 && len(Row) == len(Header):
+				Utilization, _ := strconv.ParseFloat(Value, 64)
+				Warning, _ := strconv.ParseFloat(Row[WarningColumn], 64)
+				Depletion, _ := strconv.ParseFloat(Row[DepletionColumn], 64)
+				Table.WriteString("<td>" + HTMLBar(Utilization, Warning, Depletion, Value) + "</td>")
+			case Column < len(Types) && Types[Column] == "float64":
+				Table.WriteString("<td class=\"num\">" + html.EscapeString(Value) + "</td>")
+			default:
+				Table.WriteString("<td>" + html.EscapeString(Value) + "</td>")
+			}
+		}
+		Table.WriteString("</tr>\n")
+	}
+	Table.WriteString("</table>\n")
+
+	State := HTMLSectionAdd(ReportTitle(p), Table.String(), p)
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'OutputHTML' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'OutputHTML' return values State:", State)
+	Debug.Println("Function 'OutputHTML' ended.")
+	return State
+}
+
+//HTMLSectionAdd adds a section with a title and the html content to the html output and writes it to the output file (-outputfile)
+//if the file cannot be written the state is true. Otherwise false.
+func HTMLSectionAdd(Title string, Content string, p Params) bool {
+	if len(HTMLSections) == 0 {
+		HTMLCreated = time.Now()
+	}
+	HTMLSections = append(HTMLSections, "<h2>"+html.EscapeString(Title)+"</h2>\n"+Content)
+
+	var Document strings.Builder
+	Document.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	Document.WriteString("<title>HiCHPoolInfo " + html.EscapeString(p.OutputType) + " report</title>\n")
+	Document.WriteString("<style>\n" + HTMLStyle + "\n</style>\n</head>\n<body>\n")
+	Document.WriteString("<h1>HiCHPoolInfo " + html.EscapeString(p.OutputType) + " report</h1>\n")
+	Document.WriteString("<p class=\"meta\">Storage device ID: " + html.EscapeString(p.StorageDeviceID) + " - created: " + HTMLCreated.Format(time.RFC3339) + "</p>\n")
+	Document.WriteString(strings.Join(HTMLSections, "\n"))
+	Document.WriteString("</body>\n</html>\n")
+
+	if err := ioutil.WriteFile(p.OutputFile, []byte(Document.String()), 0644); err != nil {
+		Error.Println("The output file (" + p.OutputFile + ") cannot be written: " + err.Error())
+		return true
+	}
+	Info.Println("Section '" + Title + "' written to " + p.OutputFile)
+	return false
+}
+
+//HTMLColumn returns the index of a header. if the header does not exist -1 is returned.
+func HTMLColumn(Header []string, Key string) int {
+	for Column, Descriptor := range Header {
+		if Descriptor == Key {
+			return Column
+		}
+	}
+	return -1
+}
+
+//HTMLBar returns a bar of the utilization [%] with the text Value. it is red at the depletion threshold, yellow at the warning threshold and green below.
+//a threshold that is not set (<= 0) is skipped.
+func HTMLBar(Utilization float64, Warning float64, Depletion float64, Value string) string {
+	var Class string
+	switch {
+	case Depletion > 0 && Utilization >= Depletion:
+		Class = "depletion"
+	case Warning > 0 && Utilization >= Warning:
+		Class = "warning"
+	default:
+		Class = "ok"
+	}
+
+	var Width float64
+	Width = Utilization
+	if Width < 0 {
+		Width = 0
+	}
+	if Width > 100 {
+		Width = 100
+	}
+	return "<div class=\"bar\"><div class=\"" + Class + "\" style=\"width:" + strconv.FormatFloat(Width, 'f', 1, 64) + "%\"></div><span>" + html.EscapeString(Value) + "</span></div>"
+}
+
+//HTMLPoolTrends adds the utilization trend of every pool to the html output. the history are the snapshots with pools in the snapshot directory (-snapshotdir) and the current pools.
+//nothing is added if there is no snapshot with pools.
+//example: HTMLPoolTrends(Pools, p)
+func HTMLPoolTrends(Pools []PoolInfo, p Params) bool {
+	Debug.Println("Function 'HTMLPoolTrends' started.")
+
+	Snaps := SnapshotsRead(p.SnapshotDir, p.StorageDeviceID, func(Snap Snapshot) bool {
+		return len(Snap.Pools) > 0
+	})
+	if len(Snaps) == 0 {
+		Info.Println("No pool history in the snapshot directory (" + p.SnapshotDir + "). No trends are shown.")
+		return false
+	}
+
+	var Charts strings.Builder
+	for _, Pool := range Pools {
+		var Times []time.Time
+		var Values []float64
+		for _, Snap := range Snaps {
+			for _, Before := range Snap.Pools {
+				if Before.PoolID == Pool.PoolID && Before.PhysicalCapacityTotal > 0 {
+					Times = append(Times, Snap.Time)
+					Values = append(Values, (Before.PhysicalCapacityTotal-Before.PhysicalCapacityFree)/Before.PhysicalCapacityTotal*100)
+				}
+			}
+		}
+		if len(Times) == 0 || Pool.PhysicalCapacityTotal <= 0 {
+			continue
+		}
+		Times = append(Times, time.Now())
+		Values = append(Values, (Pool.PhysicalCapacityTotal-Pool.PhysicalCapacityFree)/Pool.PhysicalCapacityTotal*100)
+
+		Charts.WriteString("<h3>Pool " + html.EscapeString(Pool.PoolID+" ("+Pool.PoolName+")") + " - utilization [%]</h3>\n")
+		Charts.WriteString(HTMLTrendChart(Times, Values, Pool.WarningThreshold, Pool.DepletionThreshold))
+	}
+
+	Debug.Println("Function 'HTMLPoolTrends' ended.")
+	if Charts.Len() == 0 {
+		return false
+	}
+	return HTMLSectionAdd("Trends", Charts.String(), p)
+}
+
+//HTMLTrendChart returns an inline SVG line chart of percent values (0-100) over the time with the warning and the depletion threshold as dashed lines
+//a threshold that is not set (<= 0) has no line.
+func HTMLTrendChart(Times []time.Time, Values []float64, Warning float64, Depletion float64) string {
+	const Left = 40
+	const Right = 10
+	const Top = 10
+	const Bottom = 24
+	PlotWidth := float64(HTMLChartWidth - Left - Right)
+	PlotHeight := float64(HTMLChartHeight - Top - Bottom)
+
+	//y position of a percent value
+	Y := func(Value float64) string {
+		if Value < 0 {
+			Value = 0
+		}
+		if Value > 100 {
+			Value = 100
+		}
+		return strconv.FormatFloat(float64(Top)+PlotHeight*(1-Value/100), 'f', 1, 64)
+	}
+	//x position of a time
+	Span := Times[len(Times)-1].Sub(Times[0]).Seconds()
+	X := func(Time time.Time) string {
+		if Span <= 0 {
+			return strconv.FormatFloat(float64(Left)+PlotWidth/2, 'f', 1, 64)
+		}
+		return strconv.FormatFloat(float64(Left)+PlotWidth*Time.Sub(Times[0]).Seconds()/Span, 'f', 1, 64)
+	}
+
+	var Chart strings.Builder
+	Chart.WriteString("<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"" + strconv.Itoa(HTMLChartWidth) + "\" height=\"" + strconv.Itoa(HTMLChartHeight) + "\" font-size=\"10\">\n")
+
+	//grid and y axis labels
+	for Percent := 0; Percent <= 100; Percent = Percent + 25 {
+		Chart.WriteString("<line x1=\"" + strconv.Itoa(Left) + "\" x2=\"" + strconv.Itoa(HTMLChartWidth-Right) + "\" y1=\"" + Y(float64(Percent)) + "\" y2=\"" + Y(float64(Percent)) + "\" stroke=\"#ddd\"/>\n")
+		Chart.WriteString("<text x=\"" + strconv.Itoa(Left-4) + "\" y=\"" + Y(float64(Percent)) + "\" text-anchor=\"end\" dominant-baseline=\"middle\">" + strconv.Itoa(Percent) + "</text>\n")
+	}
+
+	//thresholds
+	if Warning > 0 {
+		Chart.WriteString("<line x1=\"" + strconv.Itoa(Left) + "\" x2=\"" + strconv.Itoa(HTMLChartWidth-Right) + "\" y1=\"" + Y(Warning) + "\" y2=\"" + Y(Warning) + "\" stroke=\"#e0a800\" stroke-dasharray=\"4 3\"/>\n")
+	}
+	if Depletion > 0 {
+		Chart.WriteString("<line x1=\"" + strconv.Itoa(Left) + "\" x2=\"" + strconv.Itoa(HTMLChartWidth-Right) + "\" y1=\"" + Y(Depletion) + "\" y2=\"" + Y(Depletion) + "\" stroke=\"#c00000\" stroke-dasharray=\"4 3\"/>\n")
+	}
+
+	//utilization
+	var Points []string
+	for i := range Times {
+		Points = append(Points, X(Times[i])+","+Y(Values[i]))
+	}
+	Chart.WriteString("<polyline fill=\"none\" stroke=\"#1f77b4\" stroke-width=\"2\" points=\"" + strings.Join(Points, " ") + "\"/>\n")
+	for i := range Times {
+		Chart.WriteString("<circle cx=\"" + X(Times[i]) + "\" cy=\"" + Y(Values[i]) + "\" r=\"2.5\" fill=\"#1f77b4\"><title>" + Times[i].Format("2006-01-02 15:04") + ": " + strconv.FormatFloat(Values[i], 'f', 2, 64) + "%</title></circle>\n")
+	}
+
+	//x axis labels
+	Chart.WriteString("<text x=\"" + strconv.Itoa(Left) + "\" y=\"" + strconv.Itoa(HTMLChartHeight-6) + "\">" + Times[0].Format("2006-01-02") + "</text>\n")
+	Chart.WriteString("<text x=\"" + strconv.Itoa(HTMLChartWidth-Right) + "\" y=\"" + strconv.Itoa(HTMLChartHeight-6) + "\" text-anchor=\"end\">" + Times[len(Times)-1].Format("2006-01-02") + "</text>\n")
+	Chart.WriteString("</svg>\n")
+	return Chart.String()
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//InfluxMeasurement is the measurement of the pool metrics in the line protocol
+const InfluxMeasurement string = "hitachi_pool"
+
+//InfluxBatchSize is the maximum number of lines of one write request
+const InfluxBatchSize int = 5000
+
+//InfluxRetries is the number of retries of a write request if InfluxDB is not reachable or answers with 429 or 5xx
+const InfluxRetries int = 3
+
+//InfluxRetryWait is the wait before the first retry. it is doubled for every retry unless InfluxDB sends Retry-After.
+const InfluxRetryWait time.Duration = 2 * time.Second
+
+//InfluxSleep waits before a retry. it is replaced in the tests to not wait.
+var InfluxSleep = time.Sleep
+
+//PoolsInfluxFormat formats the metrics of the pools as InfluxDB line protocol (one line per pool)
+//the tags are the serial number, the model, the pool id, name and type and fmc (true if the pool has FMC compression). the capacities are [GB], the rates [%].
+//the compression ratios are only added if the pool has one. the time is in seconds (precision s).
+//example: PoolsInfluxFormat(Storage, Pools, time.Now())
+func PoolsInfluxFormat(Storage StorageInfo, Pools []PoolInfo, Time time.Time) []string {
+	Debug.Println("Function 'PoolsInfluxFormat' started.")
+
+	var Mb2Gb float64
+	Mb2Gb = 1024.0
+
+	var Lines []string
+	for _, Pool := range Pools {
+		var Utilization float64
+		Utilization = 0
+		if Pool.PhysicalCapacityTotal > 0 {
+			Utilization = (Pool.PhysicalCapacityTotal - Pool.PhysicalCapacityFree) / Pool.PhysicalCapacityTotal * 100
+		}
+
+		//the tags are sorted by key as recommended by InfluxDB
+		Tags := [][]string{
+			{"fmc", strconv.FormatBool(Pool.FMC)},
+			{"model", Storage.Model},
+			{"pool_id", Pool.PoolID},
+			{"pool_name", Pool.PoolName},
+			{"pool_type", Pool.PoolType},
+			{"serial", Storage.Serial},
+		}
+		Fields := [][]string{
+			{"physical_total_gb", InfluxFloat(Pool.PhysicalCapacityTotal / Mb2Gb)},
+			{"physical_used_gb", InfluxFloat((Pool.PhysicalCapacityTotal - Pool.PhysicalCapacityFree) / Mb2Gb)},
+			{"physical_free_gb", InfluxFloat(Pool.PhysicalCapacityFree / Mb2Gb)},
+			{"utilization_percent", InfluxFloat(Utilization)},
+			{"used_capacity_rate_percent", InfluxFloat(Pool.UsedCapacityRate)},
+			{"pool_capacity_gb", InfluxFloat(Pool.TotalPoolCapacity / Mb2Gb)},
+			{"located_gb", InfluxFloat(Pool.TotalLocatedCapacity / Mb2Gb)},
+			{"snapshot_used_gb", InfluxFloat(Pool.SnapshotUsedCapacity / Mb2Gb)},
+			{"warning_threshold_percent", InfluxFloat(Pool.WarningThreshold)},
+			{"depletion_threshold_percent", InfluxFloat(Pool.DepletionThreshold)},
+		}
+		//EffectiveGBFree is formatted [GB]
+		if EffectiveFree, err := strconv.ParseFloat(Pool.EffectiveGBFree, 64); err == nil {
+			Fields = append(Fields, []string{"effective_free_gb", InfluxFloat(EffectiveFree)})
+		}
+		if FMCRatio, err := strconv.ParseFloat(Pool.FMCCompressionRatio, 64); err == nil && FMCRatio > 0 {
+			Fields = append(Fields, []string{"compression_ratio_fmc", InfluxFloat(FMCRatio)})
+		}
+		if Pool.CompressionRatio > 0 {
+			Fields = append(Fields, []string{"compression_ratio_total", InfluxFloat(Pool.CompressionRatio)})
+		}
+
+		var Line strings.Builder
+		Line.WriteString(InfluxEscape(InfluxMeasurement, ", "))
+		for _, Tag := range Tags {
+			//empty tag values are not allowed
+			if Tag[1] == "" {
+				continue
+			}
+			Line.WriteString("," + Tag[0] + "=" + InfluxEscape(Tag[1], ", ="))
+		}
+		for i, Field := range Fields {
+			if i == 0 {
+				Line.WriteString(" ")
+			} else {
+				Line.WriteString(",")
+			}
+			Line.WriteString(Field[0] + "=" + Field[1])
+		}
+		Line.WriteString(" " + strconv.FormatInt(Time.Unix(), 10))
+		Lines = append(Lines, Line.String())
+	}
+
+	Debug.Println("Function 'PoolsInfluxFormat' return values number of lines:", len(Lines))
+	Debug.Println("Function 'PoolsInfluxFormat' ended.")
+	return Lines
+}
+
+//OutputInflux outputs the line protocol to the command line or writes it to InfluxDB if the URL (-influxurl) is set
+//if the line protocol cannot be written the state is true. Otherwise false.
+func OutputInflux(Lines []string, p Params) bool {
+	Debug.Println("Function 'OutputInflux' started.")
+
+	//true -> NOK
+	//false -> OK
+	State := false
+
+	// if no data is available skip output
+	if len(Lines) == 0 {
+		Error.Println("No Data to output.")
+		return State
+	}
+
+	if p.InfluxURL == "" {
+		for _, Line := range Lines {
+			fmt.Println(Line)
+		}
+	} else if err := InfluxWrite(Lines, p); err != nil {
+		Error.Println(err.Error())
+		State = true
+	}
+
+	Debug.Println("Function 'OutputInflux' return values State:", State)
+	Debug.Println("Function 'OutputInflux' ended.")
+	return State
+}
+
+//InfluxWrite posts the line protocol in batches (InfluxBatchSize) to the write endpoint of InfluxDB v2 (-influxurl, -influxorg, -influxbucket, -influxtoken)
+//a batch is retried (InfluxRetries) if InfluxDB is not reachable or answers with 429 or 5xx. the certificate is verified unless -influxinsecure is set. a certificate that cannot be verified is not retried.
+//return value is nil if all lines are written. Otherwise the error of the batch that cannot be written.
+//example: InfluxWrite(Lines, p)
+func InfluxWrite(Lines []string, p Params) error {
+	Debug.Println("Function 'InfluxWrite' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	Query := url.Values{}
+	Query.Set("org", p.InfluxOrg)
+	Query.Set("bucket", p.InfluxBucket)
+	Query.Set("precision", "s")
+	WriteURL := strings.TrimRight(p.InfluxURL, "/") + "/api/v2/write?" + Query.Encode()
+	Debug.Println("URL: " + WriteURL)
+
+	tr := &http.Transport{}
+	if p.InfluxInsecure {
+		Warning.Println("The certificate of the InfluxDB (" + p.InfluxURL + ") is not verified (-influxinsecure).")
+		tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	client := &http.Client{Transport: tr, Timeout: 30 * time.Second}
+
+	for Start := 0; Start < len(Lines); Start += InfluxBatchSize {
+		End := Start + InfluxBatchSize
+		if End > len(Lines) {
+			End = len(Lines)
+		}
+		Body := strings.Join(Lines[Start:End], "\n") + "\n"
+
+		Wait := InfluxRetryWait
+		for Try := 0; ; Try++ {
+			StatusCode, Status, RetryAfter, err := InfluxPost(client, WriteURL, Body, p)
+			if err != nil {
+				return err
+			}
+			if StatusCode >= 200 && StatusCode < 300 {
+				Verbose.Println("Lines " + strconv.Itoa(Start+1) + "-" + strconv.Itoa(End) + " written to InfluxDB (" + p.InfluxURL + ")")
+				break
+			}
+			//client errors (ex: 400 invalid line, 401 invalid token, 404 bucket not found) are not retried
+			if (StatusCode >= 400 && StatusCode < 500 && StatusCode != http.StatusTooManyRequests) || Try >= InfluxRetries {
+				return errors.New("The line protocol cannot be written to InfluxDB (" + p.InfluxURL + "): " + Status)
+			}
+			if RetryAfter > 0 {
+				Wait = RetryAfter
+			}
+			Warning.Println("InfluxDB (" + p.InfluxURL + ") answered: " + Status + ". Retry " + strconv.Itoa(Try+1) + "/" + strconv.Itoa(InfluxRetries) + " in " + Wait.String())
+			InfluxSleep(Wait)
+			Wait = Wait * 2
+		}
+	}
+	Info.Println(strconv.Itoa(len(Lines)) + " lines written to InfluxDB (" + p.InfluxURL + ", bucket " + p.InfluxBucket + ")")
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'InfluxWrite' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'InfluxWrite' ended.")
+	return nil
+}
+
+//InfluxPost sends one write request. return values are the http status code (0 if InfluxDB is not reachable), the status with the error message and the wait of the Retry-After header.
+//the error is set if the request cannot be created or the certificate of InfluxDB cannot be verified (not retried).
+func InfluxPost(client *http.Client, WriteURL string, Body string, p Params) (int, string, time.Duration, error) {
+	req, err := http.NewRequest("POST", WriteURL, strings.NewReader(Body))
+	if err != nil {
+		return 0, "", 0, errors.New("The webrequest cannot be created ('" + WriteURL + "'): " + err.Error())
+	}
+	if p.InfluxToken != "" {
+		req.Header.Set("Authorization", "Token "+p.InfluxToken)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if InfluxCertificateError(err) {
+			return 0, err.Error(), 0, errors.New("The certificate of InfluxDB (" + p.InfluxURL + ") cannot be verified. Use a trusted certificate or -influxinsecure: " + err.Error())
+		}
+		return 0, err.Error(), 0, nil
+	}
+	defer resp.Body.Close()
+
+	Message, _ := ioutil.ReadAll(resp.Body)
+	Debug.Println("Response Status:", resp.Status)
+	Debug.Println("Response Body:", string(Message))
+
+	var RetryAfter time.Duration
+	if Seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && Seconds > 0 {
+		RetryAfter = time.Duration(Seconds) * time.Second
+	}
+	return resp.StatusCode, strings.TrimSpace(resp.Status + " " + string(Message)), RetryAfter, nil
+}
+
+//InfluxCertificateError returns true if the error is a TLS handshake or certificate verification error. it does not change with a retry.
+func InfluxCertificateError(err error) bool {
+	var VerificationError *tls.CertificateVerificationError
+	var UnknownAuthorityError x509.UnknownAuthorityError
+	var CertificateInvalidError x509.CertificateInvalidError
+	var HostnameError x509.HostnameError
+	var RecordHeaderError tls.RecordHeaderError
+	return errors.As(err, &VerificationError) || errors.As(err, &UnknownAuthorityError) || errors.As(err, &CertificateInvalidError) || errors.As(err, &HostnameError) || errors.As(err, &RecordHeaderError)
+}
+
+//InfluxEscape escapes the characters of a measurement (", "), a tag key or a tag value (", =") with a backslash
+func InfluxEscape(Value string, Special string) string {
+	var Escaped strings.Builder
+	for _, Character := range Value {
+		if strings.ContainsRune(Special, Character) {
+			Escaped.WriteString("\\")
+		}
+		Escaped.WriteRune(Character)
+	}
+	return Escaped.String()
+}
+
+//InfluxFloat formats a float field value of the line protocol
+func InfluxFloat(Value float64) string {
+	return strconv.FormatFloat(Value, 'f', -1, 64)
+}
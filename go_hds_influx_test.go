@@ -0,0 +1,225 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+//influxTestServer answers the write requests with the status codes in order (the last one is repeated) and counts the requests
+type influxTestServer struct {
+	sync.Mutex
+	Codes      []int
+	RetryAfter string
+	Requests   int
+	Bodies     []string
+	Tokens     []string
+}
+
+func (s *influxTestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	Body, _ := ioutil.ReadAll(r.Body)
+	s.Bodies = append(s.Bodies, string(Body))
+	s.Tokens = append(s.Tokens, r.Header.Get("Authorization"))
+
+	Code := s.Codes[len(s.Codes)-1]
+	if s.Requests < len(s.Codes) {
+		Code = s.Codes[s.Requests]
+	}
+	s.Requests = s.Requests + 1
+
+	if Code == http.StatusTooManyRequests && s.RetryAfter != "" {
+		w.Header().Set("Retry-After", s.RetryAfter)
+	}
+	w.WriteHeader(Code)
+	if Code >= 400 {
+		w.Write([]byte(`{"code":"error","message":"test"}`))
+	}
+}
+
+//influxTestSetup discards the logging and records the waits instead of sleeping
+func influxTestSetup(t *testing.T) *[]time.Duration {
+	Init(ioutil.Discard, ioutil.Discard, ioutil.Discard, ioutil.Discard, ioutil.Discard)
+
+	Waits := []time.Duration{}
+	Sleep := InfluxSleep
+	InfluxSleep = func(Wait time.Duration) {
+		Waits = append(Waits, Wait)
+	}
+	t.Cleanup(func() {
+		InfluxSleep = Sleep
+	})
+	return &Waits
+}
+
+func influxTestParams(URL string) Params {
+	var p Params
+	p.InfluxURL = URL
+	p.InfluxOrg = "org"
+	p.InfluxBucket = "storage"
+	p.InfluxToken = "secret"
+	return p
+}
+
+var influxTestLines = []string{
+	"hitachi_pool,pool_id=0 physical_total_gb=100 1700000000",
+	"hitachi_pool,pool_id=1 physical_total_gb=200 1700000000",
+}
+
+func TestInfluxWriteNoContent(t *testing.T) {
+	Waits := influxTestSetup(t)
+	Server := &influxTestServer{Codes: []int{http.StatusNoContent}}
+	ts := httptest.NewServer(Server)
+	defer ts.Close()
+
+	if err := InfluxWrite(influxTestLines, influxTestParams(ts.URL)); err != nil {
+		t.Fatalf("InfluxWrite returned an error: %v", err)
+	}
+	if Server.Requests != 1 {
+		t.Errorf("requests = %d, want 1", Server.Requests)
+	}
+	if len(*Waits) != 0 {
+		t.Errorf("waits = %v, want none", *Waits)
+	}
+	if Server.Bodies[0] != strings.Join(influxTestLines, "\n")+"\n" {
+		t.Errorf("body = %q", Server.Bodies[0])
+	}
+	if Server.Tokens[0] != "Token secret" {
+		t.Errorf("authorization = %q, want %q", Server.Tokens[0], "Token secret")
+	}
+}
+
+func TestInfluxWriteTooManyRequestsRetryAfter(t *testing.T) {
+	Waits := influxTestSetup(t)
+	Server := &influxTestServer{Codes: []int{http.StatusTooManyRequests, http.StatusNoContent}, RetryAfter: "7"}
+	ts := httptest.NewServer(Server)
+	defer ts.Close()
+
+	if err := InfluxWrite(influxTestLines, influxTestParams(ts.URL)); err != nil {
+		t.Fatalf("InfluxWrite returned an error: %v", err)
+	}
+	if Server.Requests != 2 {
+		t.Errorf("requests = %d, want 2", Server.Requests)
+	}
+	if len(*Waits) != 1 || (*Waits)[0] != 7*time.Second {
+		t.Errorf("waits = %v, want [7s] of Retry-After", *Waits)
+	}
+}
+
+func TestInfluxWriteServerErrorRetry(t *testing.T) {
+	Waits := influxTestSetup(t)
+	Server := &influxTestServer{Codes: []int{http.StatusServiceUnavailable}}
+	ts := httptest.NewServer(Server)
+	defer ts.Close()
+
+	err := InfluxWrite(influxTestLines, influxTestParams(ts.URL))
+	if err == nil {
+		t.Fatal("InfluxWrite returned no error after all retries")
+	}
+	if !strings.Contains(err.Error(), "503") {
+		t.Errorf("error = %q, want the status 503", err.Error())
+	}
+	if Server.Requests != InfluxRetries+1 {
+		t.Errorf("requests = %d, want %d", Server.Requests, InfluxRetries+1)
+	}
+	Want := []time.Duration{InfluxRetryWait, 2 * InfluxRetryWait, 4 * InfluxRetryWait}
+	if len(*Waits) != len(Want) {
+		t.Fatalf("waits = %v, want %v", *Waits, Want)
+	}
+	for i := range Want {
+		if (*Waits)[i] != Want[i] {
+			t.Errorf("waits = %v, want %v", *Waits, Want)
+			break
+		}
+	}
+}
+
+func TestInfluxWriteServerErrorRecovers(t *testing.T) {
+	Waits := influxTestSetup(t)
+	Server := &influxTestServer{Codes: []int{http.StatusInternalServerError, http.StatusNoContent}}
+	ts := httptest.NewServer(Server)
+	defer ts.Close()
+
+	if err := InfluxWrite(influxTestLines, influxTestParams(ts.URL)); err != nil {
+		t.Fatalf("InfluxWrite returned an error: %v", err)
+	}
+	if Server.Requests != 2 || len(*Waits) != 1 {
+		t.Errorf("requests = %d, waits = %v, want 2 requests and 1 wait", Server.Requests, *Waits)
+	}
+}
+
+func TestInfluxWriteBadRequestNoRetry(t *testing.T) {
+	Waits := influxTestSetup(t)
+	Server := &influxTestServer{Codes: []int{http.StatusBadRequest, http.StatusNoContent}}
+	ts := httptest.NewServer(Server)
+	defer ts.Close()
+
+	err := InfluxWrite(influxTestLines, influxTestParams(ts.URL))
+	if err == nil {
+		t.Fatal("InfluxWrite returned no error for 400")
+	}
+	if !strings.Contains(err.Error(), "400") {
+		t.Errorf("error = %q, want the status 400", err.Error())
+	}
+	if Server.Requests != 1 {
+		t.Errorf("requests = %d, want 1 (no retry)", Server.Requests)
+	}
+	if len(*Waits) != 0 {
+		t.Errorf("waits = %v, want none", *Waits)
+	}
+}
+
+func TestInfluxWriteCertificateErrorNoRetry(t *testing.T) {
+	Waits := influxTestSetup(t)
+	Server := &influxTestServer{Codes: []int{http.StatusNoContent}}
+	ts := httptest.NewUnstartedServer(Server)
+	//the failed handshakes are expected
+	ts.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	ts.StartTLS()
+	defer ts.Close()
+
+	err := InfluxWrite(influxTestLines, influxTestParams(ts.URL))
+	if err == nil {
+		t.Fatal("InfluxWrite accepted a certificate that is not trusted")
+	}
+	if !strings.Contains(err.Error(), "certificate") {
+		t.Errorf("error = %q, want the certificate error", err.Error())
+	}
+	if len(*Waits) != 0 {
+		t.Errorf("waits = %v, want none (a certificate error is not retried)", *Waits)
+	}
+}
+
+func TestInfluxWriteVerifiesCertificate(t *testing.T) {
+	influxTestSetup(t)
+	Server := &influxTestServer{Codes: []int{http.StatusNoContent}}
+	ts := httptest.NewUnstartedServer(Server)
+	//the failed handshakes are expected
+	ts.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	ts.StartTLS()
+	defer ts.Close()
+
+	//the test server has a self-signed certificate
+	p := influxTestParams(ts.URL)
+	if err := InfluxWrite(influxTestLines, p); err == nil {
+		t.Fatal("InfluxWrite accepted a certificate that is not trusted")
+	}
+	if Server.Requests != 0 {
+		t.Errorf("requests = %d, want 0 (the token must not be sent)", Server.Requests)
+	}
+
+	p.InfluxInsecure = true
+	if err := InfluxWrite(influxTestLines, p); err != nil {
+		t.Fatalf("InfluxWrite with -influxinsecure returned an error: %v", err)
+	}
+	if Server.Requests != 1 {
+		t.Errorf("requests = %d, want 1", Server.Requests)
+	}
+}
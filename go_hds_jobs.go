@@ -0,0 +1,255 @@
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//JobInfo type is the asynchronous job returned by all POST/PATCH/PUT/DELETE requests of the Configuration Manager
+//the error values are only set if the job failed
+type JobInfo struct {
+	JobID             int
+	Self              string
+	Status            string
+	State             string
+	AffectedResources []string
+	ErrorSource       string
+	MessageID         string
+	Message           string
+	Cause             string
+	Solution          string
+}
+
+//JobRequest sends a POST/PATCH/PUT/DELETE request and waits for its job (JobWait)
+//a rejected request does not stop the program. its error is returned in the job so that the caller can record it, unlock the resources and close the session.
+//return value (JobInfo) is the last state of the job. if the request was rejected or the job did not succeed or timed out the state is true. Otherwise false.
+//example: JobRequest(p)
+func JobRequest(p Params) (JobInfo, bool) {
+	Debug.Println("Function 'JobRequest' started.")
+
+	Out, Status, State := HTTPRequestResult(p)
+	if State {
+		Job := JobErrorParse(Out, Status)
+		Warning.Println("The request with the URL:\"" + p.URL + "\" with requesttype:\"" + p.RequestType + "\" was rejected: " + JobErrorFormat(Job))
+		if Job.Solution != "" {
+			Warning.Println("Solution: " + Job.Solution)
+		}
+		Debug.Println("Function 'JobRequest' ended.")
+		return Job, State
+	}
+
+	Debug.Println("Function 'JobRequest' ended.")
+	return JobWait(p, Out)
+}
+
+//JobWait waits until the job returned by a request is completed or the job timeout (-jobtimeout) is reached
+//Out is the response of the POST/PATCH/PUT/DELETE request. The job is polled at its "self" URL (/jobs/{jobId}).
+//The progress is logged every 10 seconds. The error of a failed job is logged with its message id and solution.
+//return value (JobInfo) is the last state of the job. if the response does not contain a job or the job did not succeed, timed out or cannot be polled the state is true. Otherwise false.
+//the caller unlocks the resources.
+//example: JobWait(p, Out)
+func JobWait(p Params, Out string) (JobInfo, bool) {
+	Debug.Println("Function 'JobWait' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//initial state is true that means NOK
+	State := true
+
+	/*
+	   {
+	       "jobId": 12,
+	       "self": "/ConfigurationManager/v1/objects/storages/834000470018/jobs/12",
+	       "userId": "restuser",
+	       "status": "Completed",
+	       "state": "Failed",
+	       "createdTime": "2018-05-20T10:00:01Z",
+	       "updatedTime": "2018-05-20T10:00:03Z",
+	       "completedTime": "2018-05-20T10:00:03Z",
+	       "affectedResources": [],
+	       "error": {
+	           "errorSource": "/ConfigurationManager/v1/objects/storages/834000470018/snapshots/2816,3",
+	           "messageId": "KART40050-E",
+	           "message": "The specified pair does not exist.",
+	           "cause": "...",
+	           "solution": "Specify an existing pair, and then retry the operation."
+	       }
+	   }
+	*/
+
+	var Job JobInfo
+	var ParseState bool
+	Job, ParseState = JobParse(Out)
+	if ParseState {
+		Debug.Println("Function 'JobWait' ended.")
+		return Job, State
+	}
+
+	var Timeout time.Duration
+	Timeout = time.Duration(p.JobTimeout) * time.Second
+
+	//the job is polled every second. after 10 polls the polling interval is 5 seconds
+	var Polls int
+	Polls = 0
+	var LastProgress time.Time
+	LastProgress = TimeStart
+	for Job.Status != "Completed" {
+		if time.Since(TimeStart) > Timeout {
+			Warning.Println("Job: " + strconv.Itoa(Job.JobID) + " is not completed after " + strconv.Itoa(p.JobTimeout) + " seconds (status: " + Job.Status + " state: " + Job.State + "). It keeps running on the storage.")
+			Debug.Println("Function 'JobWait' ended.")
+			return Job, State
+		}
+
+		if Polls < 10 {
+			time.Sleep(time.Second)
+		} else {
+			time.Sleep(5 * time.Second)
+		}
+		Polls = Polls + 1
+
+		if Job.Self != "" {
+			p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + Job.Self
+		} else {
+			p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + "/ConfigurationManager/v1/objects/storages/" + p.StorageDeviceID + "/jobs/" + strconv.Itoa(Job.JobID)
+		}
+		p.RequestType = "GET"
+		p.RequestBody = ""
+		Out, Status, PollState := HTTPRequestResult(p)
+		if PollState {
+			Warning.Println("Job: " + strconv.Itoa(Job.JobID) + " cannot be polled (" + Status + "). It keeps running on the storage.")
+			Debug.Println("Function 'JobWait' ended.")
+			return Job, State
+		}
+		var PolledJob JobInfo
+		PolledJob, ParseState = JobParse(Out)
+		if ParseState {
+			Warning.Println("Job: " + strconv.Itoa(Job.JobID) + " cannot be polled. It keeps running on the storage.")
+			Debug.Println("Function 'JobWait' ended.")
+			return Job, State
+		}
+		Job = PolledJob
+
+		if time.Since(LastProgress) >= 10*time.Second {
+			Info.Println("Job: " + strconv.Itoa(Job.JobID) + " status: " + Job.Status + " state: " + Job.State + " (" + time.Since(TimeStart).Round(time.Second).String() + ")")
+			LastProgress = time.Now()
+		}
+	}
+
+	if Job.State == "Succeeded" {
+		Verbose.Println("Job: " + strconv.Itoa(Job.JobID) + " succeeded.")
+		//state to OK
+		State = false
+	} else {
+		Warning.Println("Job: " + strconv.Itoa(Job.JobID) + " ended with the state " + Job.State + ".")
+		if Job.MessageID != "" || Job.Message != "" {
+			Warning.Println("Job: " + strconv.Itoa(Job.JobID) + " error: " + JobErrorFormat(Job))
+		}
+		if Job.Solution != "" {
+			Warning.Println("Job: " + strconv.Itoa(Job.JobID) + " solution: " + Job.Solution)
+		}
+	}
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'JobWait' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'JobWait' return values Job:", Job)
+	Debug.Println("Function 'JobWait' ended.")
+
+	return Job, State
+}
+
+//JobParse returns the job of a response. if the response does not contain a job the state is true and the message of the job is set. Otherwise false.
+func JobParse(Out string) (JobInfo, bool) {
+	var Job JobInfo
+
+	var JSONUnmarshalOut map[string]interface{}
+	JSONUnmarshalOut, _ = JSONUnmarshal(Out)
+
+	if JSONUnmarshalOut["jobId"] == nil {
+		Error.Println("The response does not contain a job: " + Out)
+		Job.Message = "The response does not contain a job."
+		return Job, true
+	}
+
+	Job.JobID = int(ElementFloat64(JSONUnmarshalOut, "jobId"))
+	Job.Self = ElementString(JSONUnmarshalOut, "self")
+	Job.Status = ElementString(JSONUnmarshalOut, "status")
+	Job.State = ElementString(JSONUnmarshalOut, "state")
+	if Resources, ok := JSONUnmarshalOut["affectedResources"].([]interface{}); ok {
+		for _, Resource := range Resources {
+			if ResourceString, ok := Resource.(string); ok {
+				Job.AffectedResources = append(Job.AffectedResources, ResourceString)
+			}
+		}
+	}
+
+	//older versions return the "errorResource" instead of the "errorSource"
+	if JobError, ok := JSONUnmarshalOut["error"].(map[string]interface{}); ok {
+		Job.ErrorSource = ElementString(JobError, "errorSource")
+		if Job.ErrorSource == "" {
+			Job.ErrorSource = ElementString(JobError, "errorResource")
+		}
+		Job.MessageID = ElementString(JobError, "messageId")
+		Job.Message = ElementString(JobError, "message")
+		Job.Cause = ElementString(JobError, "cause")
+		Job.Solution = ElementString(JobError, "solution")
+	}
+
+	Debug.Println("Job: " + strconv.Itoa(Job.JobID) + " status: " + Job.Status + " state: " + Job.State)
+	return Job, false
+}
+
+//JobErrorParse returns the error of a rejected request as job. the body is the message if it is no JSON.
+func JobErrorParse(Out string, Status string) JobInfo {
+	var Job JobInfo
+	Job.Status = "Rejected"
+	Job.State = Status
+
+	var JSONUnmarshalOut map[string]interface{}
+	if json.Unmarshal([]byte(Out), &JSONUnmarshalOut) != nil {
+		Job.Message = strings.TrimSpace(Status + " " + Out)
+		return Job
+	}
+	Job.ErrorSource = ElementString(JSONUnmarshalOut, "errorSource")
+	if Job.ErrorSource == "" {
+		Job.ErrorSource = ElementString(JSONUnmarshalOut, "errorResource")
+	}
+	Job.MessageID = ElementString(JSONUnmarshalOut, "messageId")
+	Job.Message = ElementString(JSONUnmarshalOut, "message")
+	Job.Cause = ElementString(JSONUnmarshalOut, "cause")
+	Job.Solution = ElementString(JSONUnmarshalOut, "solution")
+	if Job.MessageID == "" && Job.Message == "" {
+		Job.Message = Status
+	}
+	return Job
+}
+
+//JobErrorFormat returns the error of a failed job in one line "<messageId> <message> (<cause>) [<errorSource>]"
+func JobErrorFormat(Job JobInfo) string {
+	var Parts []string
+	if Job.MessageID != "" {
+		Parts = append(Parts, Job.MessageID)
+	}
+	if Job.Message != "" {
+		Parts = append(Parts, Job.Message)
+	}
+	if Job.Cause != "" {
+		Parts = append(Parts, "("+Job.Cause+")")
+	}
+	if Job.ErrorSource != "" {
+		Parts = append(Parts, "["+Job.ErrorSource+"]")
+	}
+	return strings.Join(Parts, " ")
+}
+
+//JobResourceID returns the id of the resource created by a job. This is the last part of the first affected resource.
+//ex: "/ConfigurationManager/v1/objects/storages/834000470018" -> "834000470018". if there is no affected resource "" is returned
+func JobResourceID(Job JobInfo) string {
+	if len(Job.AffectedResources) == 0 {
+		return ""
+	}
+	Resource := strings.TrimSuffix(Job.AffectedResources[0], "/")
+	return Resource[strings.LastIndex(Resource, "/")+1:]
+}
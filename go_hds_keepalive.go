@@ -0,0 +1,150 @@
+package main
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+//SessionDefaultAliveTime is the alive time [s] of a session if -alivetime is not set (default of the Configuration Manager)
+const SessionDefaultAliveTime int = 300
+
+//SessionCurrent is the session of this run. It is shared with the keep-alive and replaced if the session expired during the run.
+var SessionCurrent struct {
+	sync.Mutex
+	Token     string
+	SessionID float64
+	Stop      chan bool
+}
+
+//SessionRequestBody returns the body to create a session with the alive time (-alivetime) and the authentication timeout (-authtimeout)
+//values that are not set are not sent. the storage uses its defaults then.
+//example: {"aliveTime": 300, "authenticationTimeout": 120}
+func SessionRequestBody(p Params) string {
+	var Body string
+	if p.AliveTime > 0 {
+		Body = `"aliveTime": ` + strconv.Itoa(p.AliveTime)
+	}
+	if p.AuthTimeout > 0 {
+		if Body != "" {
+			Body = Body + ", "
+		}
+		Body = Body + `"authenticationTimeout": ` + strconv.Itoa(p.AuthTimeout)
+	}
+	if Body == "" {
+		return ""
+	}
+	return "{" + Body + "}"
+}
+
+//SessionStart sets the current session and starts the keep-alive of the session
+//the session is requested every half of the alive time. So it does not expire while the tool is working without requests (ex: the output of a long LDEV scan).
+//example: SessionStart(p)
+func SessionStart(p Params) {
+	SessionCurrent.Lock()
+	defer SessionCurrent.Unlock()
+
+	SessionCurrent.Token = p.Token
+	SessionCurrent.SessionID = p.SessionID
+
+	var AliveTime int
+	AliveTime = p.AliveTime
+	if AliveTime <= 0 {
+		AliveTime = SessionDefaultAliveTime
+	}
+
+	//a session is started only once
+	if SessionCurrent.Stop == nil {
+		SessionCurrent.Stop = make(chan bool)
+		go SessionKeepAlive(p, time.Duration(AliveTime)*time.Second/2, SessionCurrent.Stop)
+	}
+}
+
+//SessionStop stops the keep-alive and returns the current token and session id. They differ from the created ones if the session was renewed.
+//example: p.Token, p.SessionID = SessionStop(p)
+func SessionStop(p Params) (string, float64) {
+	SessionCurrent.Lock()
+	defer SessionCurrent.Unlock()
+
+	if SessionCurrent.Stop != nil {
+		close(SessionCurrent.Stop)
+		SessionCurrent.Stop = nil
+	}
+	if SessionCurrent.Token == "" {
+		return p.Token, p.SessionID
+	}
+	return SessionCurrent.Token, SessionCurrent.SessionID
+}
+
+//SessionTokenCurrent returns the token of the current session. if no session is started the token is returned unchanged.
+func SessionTokenCurrent(Token string) string {
+	SessionCurrent.Lock()
+	defer SessionCurrent.Unlock()
+
+	if SessionCurrent.Token == "" {
+		return Token
+	}
+	return SessionCurrent.Token
+}
+
+//SessionRenew creates a new session after the session of the token expired. with -tokencache the new session is cached.
+//if another request renewed the session already its token is returned.
+//return value (string) is the token of the new session
+//example: p.Token = SessionRenew(p)
+func SessionRenew(p Params) string {
+	Debug.Println("Function 'SessionRenew' started.")
+
+	SessionCurrent.Lock()
+	defer SessionCurrent.Unlock()
+
+	if SessionCurrent.Token != "" && SessionCurrent.Token != p.Token {
+		Debug.Println("Function 'SessionRenew' session already renewed.")
+		return SessionCurrent.Token
+	}
+
+	//the new session is requested with user and password
+	p.Token = ""
+	p.Token, p.SessionID, _ = TokenGet(p)
+	SessionCurrent.Token = p.Token
+	SessionCurrent.SessionID = p.SessionID
+
+	if p.TokenCache != "" {
+		TokenCacheStore(p)
+	}
+
+	Info.Println("New session ID: " + strconv.FormatFloat(p.SessionID, 'g', -1, 64) + " created after the session expired.")
+	Debug.Println("Function 'SessionRenew' ended.")
+	return p.Token
+}
+
+//SessionKeepAlive requests the current session every interval until stop is closed
+//a failed request is only logged. an expired session is renewed by the next request of the tool.
+func SessionKeepAlive(p Params, Interval time.Duration, Stop chan bool) {
+	Debug.Println("Function 'SessionKeepAlive' started with the interval ", Interval)
+
+	Ticker := time.NewTicker(Interval)
+	defer Ticker.Stop()
+
+	for {
+		select {
+		case <-Stop:
+			Debug.Println("Function 'SessionKeepAlive' ended.")
+			return
+		case <-Ticker.C:
+			SessionCurrent.Lock()
+			p.Token = SessionCurrent.Token
+			p.SessionID = SessionCurrent.SessionID
+			SessionCurrent.Unlock()
+
+			p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + "/ConfigurationManager/v1/objects/storages/" + p.StorageDeviceID + "/sessions/" + strconv.FormatFloat(p.SessionID, 'g', -1, 64)
+			p.RequestType = "GET"
+			p.RequestBody = ""
+			StatusCode, Status, _ := HTTPRequestStatus(p)
+			if StatusCode != 200 {
+				Verbose.Println("Keep-alive of the session ID: " + strconv.FormatFloat(p.SessionID, 'g', -1, 64) + " failed (" + Status + ").")
+			} else {
+				Debug.Println("Keep-alive of the session ID: " + strconv.FormatFloat(p.SessionID, 'g', -1, 64))
+			}
+		}
+	}
+}
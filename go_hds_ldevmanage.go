@@ -0,0 +1,367 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//LdevBlocker type is one reason why an LDEV cannot be deleted
+type LdevBlocker struct {
+	Type    string
+	Details string
+}
+
+//LdevExpand expands a DP volume (-ldevid) by the additional capacity (-capacity)
+//the pool of the volume must have enough free physical capacity and must stay below its depletion threshold. the free capacity is the effective free capacity of the pool output (physical free capacity * compression ratio).
+//without -execute nothing is changed (dry-run). With -execute the expansion must be confirmed (or -yes), the resources are locked and the job is waited for.
+//return value (bool) is true if the expansion is refused or not confirmed (exit status 98). Nothing is changed then. if the expansion failed the state is true (exit status 99). Otherwise false.
+//example: Refused, State := LdevExpand(p)
+func LdevExpand(p Params) (bool, bool) {
+	Debug.Println("Function 'LdevExpand' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//initial state is true that means NOK
+	State := true
+
+	var Mb2Gb float64
+	Mb2Gb = 1024.0
+
+	if p.Execute {
+		Info.Println("Expand LDEV start")
+	} else {
+		Info.Println("Expand LDEV start (dry-run. use -execute to expand the LDEV)")
+	}
+
+	var Capacity float64
+	Capacity, _ = CapacityParse(p.Capacity)
+	//the storage expects the unit in upper case
+	p.Capacity = strings.ToUpper(strings.TrimSpace(p.Capacity))
+
+	var Ldev LdevInfo
+	var FindState bool
+	Ldev, FindState = LdevFind(p)
+	if FindState {
+		return true, false
+	}
+	if !LdevIsDpVolume(Ldev) {
+		Error.Println("The LDEV " + LdevIDFormat(Ldev.LdevID) + " is not a DP volume. Only DP volumes can be expanded. No action will take place.")
+		return true, false
+	}
+
+	//the pool of the DP volume
+	var Pools []PoolInfo
+	Pools, State = PoolsListGet(p)
+	var Pool PoolInfo
+	var PoolFound bool
+	PoolFound = false
+	for _, PoolElement := range Pools {
+		if PoolElement.PoolID == strconv.Itoa(Ldev.PoolID) {
+			Pool = PoolElement
+			PoolFound = true
+		}
+	}
+	if !PoolFound || Pool.PhysicalCapacityTotal <= 0 {
+		Error.Println("The pool " + strconv.Itoa(Ldev.PoolID) + " of the LDEV " + LdevIDFormat(Ldev.LdevID) + " cannot be found. No action will take place.")
+		return true, false
+	}
+
+	//effective free capacity and physical usage after the expansion if the whole additional capacity is written
+	var EffectiveFree float64
+	var PhysicalAdded float64
+	EffectiveFree = Pool.PhysicalCapacityFree
+	PhysicalAdded = Capacity
+	if Pool.CompressionRatio > 0 {
+		EffectiveFree = Pool.PhysicalCapacityFree * Pool.CompressionRatio
+		PhysicalAdded = Capacity / Pool.CompressionRatio
+	}
+	var UsageAfter float64
+	UsageAfter = (Pool.PhysicalCapacityTotal - Pool.PhysicalCapacityFree + PhysicalAdded) / Pool.PhysicalCapacityTotal * 100
+
+	var Check string
+	Check = "ok"
+	if Pool.WarningThreshold > 0 && UsageAfter >= Pool.WarningThreshold {
+		Check = "warning threshold reached"
+	}
+	if Pool.DepletionThreshold > 0 && UsageAfter >= Pool.DepletionThreshold {
+		Check = "refused: depletion threshold reached"
+	}
+	if Capacity > EffectiveFree {
+		Check = "refused: not enough free capacity"
+	}
+
+	OutData := [][]string{}
+	OutData = append(OutData, []string{p.ElementStringStart})
+	OutData = append(OutData, []string{HeaderFormat("LDEV ID", "string", p), LdevIDFormat(Ldev.LdevID)})
+	OutData = append(OutData, []string{HeaderFormat("Label", "string", p), Ldev.Label})
+	OutData = append(OutData, []string{HeaderFormat("Pool ID", "string", p), Pool.PoolID})
+	OutData = append(OutData, []string{HeaderFormat("Pool name", "string", p), Pool.PoolName})
+	OutData = append(OutData, []string{HeaderFormat("Capacity before [GB]", "float64", p), strconv.FormatFloat(Ldev.Capacity/Mb2Gb, 'f', p.RoundPrecision, 64)})
+	OutData = append(OutData, []string{HeaderFormat("Capacity after [GB]", "float64", p), strconv.FormatFloat((Ldev.Capacity+Capacity)/Mb2Gb, 'f', p.RoundPrecision, 64)})
+	OutData = append(OutData, []string{HeaderFormat("Pool physical free [GB]", "float64", p), strconv.FormatFloat(Pool.PhysicalCapacityFree/Mb2Gb, 'f', p.RoundPrecision, 64)})
+	OutData = append(OutData, []string{HeaderFormat("Pool effective free [GB]", "float64", p), strconv.FormatFloat(EffectiveFree/Mb2Gb, 'f', p.RoundPrecision, 64)})
+	OutData = append(OutData, []string{HeaderFormat("Pool usage before [%]", "float64", p), strconv.FormatFloat((Pool.PhysicalCapacityTotal-Pool.PhysicalCapacityFree)/Pool.PhysicalCapacityTotal*100, 'f', p.RoundPrecision, 64)})
+	OutData = append(OutData, []string{HeaderFormat("Pool usage after [%]", "float64", p), strconv.FormatFloat(UsageAfter, 'f', p.RoundPrecision, 64)})
+	OutData = append(OutData, []string{HeaderFormat("Warning threshold [%]", "float64", p), strconv.FormatFloat(Pool.WarningThreshold, 'f', 0, 64)})
+	OutData = append(OutData, []string{HeaderFormat("Depletion threshold [%]", "float64", p), strconv.FormatFloat(Pool.DepletionThreshold, 'f', 0, 64)})
+	OutData = append(OutData, []string{HeaderFormat("Check", "string", p), Check})
+	OutData = append(OutData, []string{p.ElementStringEnd})
+	if OutputListFormat(OutData, p) {
+		Warning.Println("The function 'OutputListFormat' returned an Error.")
+	}
+
+	if strings.HasPrefix(Check, "refused") {
+		Error.Println("The pool " + Pool.PoolID + " (" + Pool.PoolName + ") cannot take " + p.Capacity + " more (" + strings.TrimPrefix(Check, "refused: ") + "). No action will take place.")
+		return true, false
+	}
+	if Check != "ok" {
+		Warning.Println("The pool " + Pool.PoolID + " (" + Pool.PoolName + ") would be used " + strconv.FormatFloat(UsageAfter, 'f', 0, 64) + "% if the whole LDEV is written. The warning threshold is " + strconv.FormatFloat(Pool.WarningThreshold, 'f', 0, 64) + "%.")
+	}
+
+	if !p.Execute {
+		Info.Println("Expand LDEV end (dry-run)")
+		return false, false
+	}
+
+	if LdevConfirm(p, Ldev, "expand the LDEV by "+p.Capacity) {
+		return true, false
+	}
+
+	if _, LockState := ResourceLock(p); LockState {
+		Warning.Println("The LDEV " + LdevIDFormat(Ldev.LdevID) + " could not be expanded.")
+		return false, true
+	}
+
+	var Job JobInfo
+	Job, State = LdevExpandInvoke(p, strconv.Itoa(Ldev.LdevID), p.Capacity)
+
+	ResourceUnlock(p)
+
+	if State {
+		Warning.Println("The LDEV " + LdevIDFormat(Ldev.LdevID) + " could not be expanded. " + JobErrorFormat(Job))
+	} else {
+		Info.Println("The LDEV " + LdevIDFormat(Ldev.LdevID) + " is expanded to " + strconv.FormatFloat((Ldev.Capacity+Capacity)/Mb2Gb, 'f', p.RoundPrecision, 64) + " GB.")
+	}
+
+	Info.Println("Expand LDEV end")
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'LdevExpand' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'LdevExpand' return values State:", State)
+	Debug.Println("Function 'LdevExpand' ended.")
+
+	return false, State
+}
+
+//LdevDelete deletes an LDEV (-ldevid)
+//the deletion is refused if the LDEV still has LUN paths, copy pairs (local or remote) or reservations on a LUN (luHostReserve as in LunsGetReserve).
+//without -execute nothing is changed (dry-run). With -execute the deletion must be confirmed (or -yes), the resources are locked and the job is waited for.
+//return value (bool) is true if the deletion is refused or not confirmed (exit status 98). Nothing is changed then. if the deletion failed the state is true (exit status 86). Otherwise false.
+//example: Refused, State := LdevDelete(p)
+func LdevDelete(p Params) (bool, bool) {
+	Debug.Println("Function 'LdevDelete' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//initial state is true that means NOK
+	State := true
+
+	if p.Execute {
+		Info.Println("Delete LDEV start")
+	} else {
+		Info.Println("Delete LDEV start (dry-run. use -execute to delete the LDEV)")
+	}
+
+	var Ldev LdevInfo
+	var FindState bool
+	Ldev, FindState = LdevFind(p)
+	if FindState {
+		return true, false
+	}
+
+	var Blockers []LdevBlocker
+	Blockers, State = LdevBlockersGet(p, Ldev)
+
+	OutData := [][]string{}
+	for _, Blocker := range Blockers {
+		OutData = append(OutData, []string{p.ElementStringStart})
+		OutData = append(OutData, []string{HeaderFormat("LDEV ID", "string", p), LdevIDFormat(Ldev.LdevID)})
+		OutData = append(OutData, []string{HeaderFormat("Blocker", "string", p), Blocker.Type})
+		OutData = append(OutData, []string{HeaderFormat("Details", "string", p), Blocker.Details})
+		OutData = append(OutData, []string{p.ElementStringEnd})
+	}
+	if len(Blockers) == 0 {
+		OutData = append(OutData, []string{p.ElementStringStart})
+		OutData = append(OutData, []string{HeaderFormat("LDEV ID", "string", p), LdevIDFormat(Ldev.LdevID)})
+		OutData = append(OutData, []string{HeaderFormat("Blocker", "string", p), "none"})
+		OutData = append(OutData, []string{HeaderFormat("Details", "string", p), "label: " + Ldev.Label + " capacity: " + strconv.FormatFloat(Ldev.Capacity/1024, 'f', p.RoundPrecision, 64) + " GB pool: " + strconv.Itoa(Ldev.PoolID)})
+		OutData = append(OutData, []string{p.ElementStringEnd})
+	}
+	if OutputListFormat(OutData, p) {
+		Warning.Println("The function 'OutputListFormat' returned an Error.")
+	}
+
+	if len(Blockers) > 0 {
+		Error.Println("The LDEV " + LdevIDFormat(Ldev.LdevID) + " is still in use (" + strconv.Itoa(len(Blockers)) + " blocker(s)). No action will take place.")
+		return true, false
+	}
+
+	if !p.Execute {
+		Info.Println("Delete LDEV end (dry-run)")
+		return false, false
+	}
+
+	if LdevConfirm(p, Ldev, "delete the LDEV") {
+		return true, false
+	}
+
+	if _, LockState := ResourceLock(p); LockState {
+		Warning.Println("The LDEV " + LdevIDFormat(Ldev.LdevID) + " could not be deleted.")
+		return false, true
+	}
+
+	var Job JobInfo
+	Job, State = LdevDeleteInvoke(p, strconv.Itoa(Ldev.LdevID))
+
+	ResourceUnlock(p)
+
+	if State {
+		Warning.Println("The LDEV " + LdevIDFormat(Ldev.LdevID) + " could not be deleted. " + JobErrorFormat(Job))
+	} else {
+		Info.Println("The LDEV " + LdevIDFormat(Ldev.LdevID) + " is deleted.")
+	}
+
+	Info.Println("Delete LDEV end")
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'LdevDelete' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'LdevDelete' return values State:", State)
+	Debug.Println("Function 'LdevDelete' ended.")
+
+	return false, State
+}
+
+//LdevFind returns the LDEV of -ldevid. if it does not exist the state is true. Otherwise false.
+func LdevFind(p Params) (LdevInfo, bool) {
+	Debug.Println("Function 'LdevFind' started.")
+
+	//initial state is true that means NOK
+	State := true
+
+	var LdevID int
+	LdevID, _ = LdevIDParse(p.LdevID)
+
+	//all LDEVs and not only the ones of a pool
+	p.PoolID = -1
+	var Ldevs []LdevInfo
+	Ldevs, State = LdevsListGet(p)
+	for _, Ldev := range Ldevs {
+		if Ldev.LdevID == LdevID {
+			Debug.Println("Function 'LdevFind' ended.")
+			return Ldev, false
+		}
+	}
+
+	Error.Println("The LDEV " + LdevIDFormat(LdevID) + " does not exist. No action will take place.")
+	State = true
+	return LdevInfo{}, State
+}
+
+//LdevBlockersGet returns the LUN paths, copy pairs and reservations that prevent the deletion of an LDEV
+func LdevBlockersGet(p Params, Ldev LdevInfo) ([]LdevBlocker, bool) {
+	Debug.Println("Function 'LdevBlockersGet' started.")
+
+	//initial state is true that means NOK
+	State := true
+
+	var Blockers []LdevBlocker
+	for _, Port := range Ldev.Ports {
+		Blockers = append(Blockers, LdevBlocker{Type: "LUN path", Details: Port.PortID + "," + strconv.Itoa(Port.HostGroupNumber) + " (" + Port.HostGroupName + ") LUN " + strconv.Itoa(Port.Lun)})
+	}
+
+	var LocalPairs []CopyPairInfo
+	LocalPairs, State = LocalCopyPairsListGet(p)
+	for _, Pair := range LocalPairs {
+		if Pair.PvolLdevID == Ldev.LdevID || Pair.SvolLdevID == Ldev.LdevID {
+			Blockers = append(Blockers, LdevBlocker{Type: "copy pair", Details: Pair.ReplicationType + " " + Pair.CopyGroupName + " P-VOL " + LdevIDFormat(Pair.PvolLdevID) + " S-VOL " + LdevIDFormat(Pair.SvolLdevID) + " " + Pair.Status})
+		}
+	}
+
+	var RemotePairs []RemoteCopyPairInfo
+	RemotePairs, State = RemoteCopyPairsListGet(p)
+	for _, Pair := range RemotePairs {
+		if Pair.PvolLdevID == Ldev.LdevID || Pair.SvolLdevID == Ldev.LdevID {
+			Blockers = append(Blockers, LdevBlocker{Type: "copy pair", Details: Pair.ReplicationType + " " + Pair.CopyGroupName + " " + Pair.CopyPairName + " P-VOL " + LdevIDFormat(Pair.PvolLdevID) + " S-VOL " + LdevIDFormat(Pair.SvolLdevID) + " remote serial " + Pair.RemoteSerialNumber})
+		}
+	}
+
+	var Luns []LunInfo
+	Luns, State = LunsListGet(p)
+	for _, Lun := range Luns {
+		if Lun.LdevID == Ldev.LdevID && len(Lun.Reserves) > 0 {
+			Blockers = append(Blockers, LdevBlocker{Type: "reservation", Details: Lun.PortID + "," + strconv.Itoa(Lun.HostGroupNumber) + " (" + Lun.HostGroupName + ") LUN " + strconv.Itoa(Lun.Lun) + " " + LunReservesFormat(Lun)})
+		}
+	}
+
+	Debug.Println("Function 'LdevBlockersGet' return values number of blockers:", len(Blockers))
+	Debug.Println("Function 'LdevBlockersGet' ended.")
+
+	//state to OK
+	State = false
+	return Blockers, State
+}
+
+//LdevConfirm asks to confirm a change of an LDEV by typing its LDEV ID (ex: 00:04:00). with -yes it is not asked.
+//return value is true if the change is not confirmed. Otherwise false.
+func LdevConfirm(p Params, Ldev LdevInfo, Action string) bool {
+	if p.Yes {
+		Verbose.Println("Confirmed with -yes: " + Action + " " + LdevIDFormat(Ldev.LdevID))
+		return false
+	}
+
+	fmt.Fprint(os.Stderr, "Type the LDEV ID "+LdevIDFormat(Ldev.LdevID)+" to "+Action+" ("+Ldev.Label+"): ")
+	Input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	InputID, InputState := LdevIDParse(strings.TrimSpace(Input))
+	if InputState || InputID != Ldev.LdevID {
+		Error.Println("The LDEV ID is not confirmed. No action will take place.")
+		return true
+	}
+	return false
+}
+
+//LdevExpandInvoke expands a DP volume by the additional capacity and waits for the job
+//example: LdevExpandInvoke(p, "1024", "10G")
+func LdevExpandInvoke(p Params, LdevID string, Capacity string) (JobInfo, bool) {
+	Verbose.Println("Expand the LDEV " + LdevID + " by " + Capacity)
+
+	JSONByt, _ := json.Marshal(map[string]interface{}{
+		"parameters": map[string]interface{}{
+			"additionalByteFormatCapacity": Capacity,
+		},
+	})
+
+	p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + "/ConfigurationManager/v1/objects/storages/" + p.StorageDeviceID + "/ldevs/" + LdevID + "/actions/expand/invoke"
+	p.RequestType = "POST"
+	p.RequestBody = string(JSONByt)
+
+	return JobRequest(p)
+}
+
+//LdevDeleteInvoke deletes an LDEV and waits for the job
+//example: LdevDeleteInvoke(p, "1024")
+func LdevDeleteInvoke(p Params, LdevID string) (JobInfo, bool) {
+	Verbose.Println("Delete the LDEV " + LdevID)
+
+	p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + "/ConfigurationManager/v1/objects/storages/" + p.StorageDeviceID + "/ldevs/" + LdevID
+	p.RequestType = "DELETE"
+	p.RequestBody = ""
+
+	return JobRequest(p)
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+//LdevInfo type is used for all LDEV Element actions
+//Capacity and UsedCapacity are in [MB]. PoolID is -1 if the LDEV is not in a pool. ParityGroupIDs is only set for LDEVs of parity groups (ex: pool volumes).
+type LdevInfo struct {
+	LdevID            int
+	EmulationType     string
+	Capacity          float64
+	UsedCapacity      float64
+	PoolID            int
+	Label             string
+	Attributes        []string
+	DataReductionMode string
+	Ports             []LdevPort
+	Status            string
+	ParityGroupIDs    []string
+}
+
+//LdevPort type is one LUN path of a LDEV
+type LdevPort struct {
+	PortID          string
+	HostGroupNumber int
+	HostGroupName   string
+	Lun             int
+}
+
+//LdevsListGet gets all defined LDEVs of the storage page by page (headLdevId). if p.PoolID is set only the dp volumes of this pool are returned.
+//return value ([]LdevInfo) are all LDEVs. if an error happened the state is true. Otherwise false.
+//The function stops with exit status 52 ("JSON parsing error (Return Format is not correct).")
+//example: LdevsListGet(p)
+func LdevsListGet(p Params) ([]LdevInfo, bool) {
+	Debug.Println("Function 'LdevsListGet' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//initial state is true that means NOK
+	State := true
+
+	var ConfigurationManagerString string
+	ConfigurationManagerString = "/ConfigurationManager/v1/objects/storages/"
+	var LdevsString string
+	LdevsString = "/ldevs?ldevOption=defined"
+	if p.PoolID >= 0 {
+		LdevsString = "/ldevs?ldevOption=dpVolume&poolId=" + strconv.Itoa(p.PoolID)
+	}
+
+	/*
+	   https://10.70.5.104/ConfigurationManager/v1/objects/storages/834000470018/ldevs?ldevOption=defined&headLdevId=0&count=16384
+	   {
+	       "data": [{
+	           "ldevId": 2816,
+	           "clprId": 0,
+	           "emulationType": "OPEN-V-CVS",
+	           "byteFormatCapacity": "1.00 T",
+	           "blockCapacity": 2147483648,
+	           "numOfPorts": 2,
+	           "ports": [{
+	               "portId": "CL1-E",
+	               "hostGroupNumber": 1,
+	               "hostGroupName": "CB500_blade3_lpa",
+	               "lun": 1
+	           }, {
+	           ...
+	           }],
+	           "attributes": ["CVS", "HDP"],
+	           "label": "FMC_HDP_TEST",
+	           "status": "NML",
+	           "poolId": 20,
+	           "numOfUsedBlock": 533729280,
+	           "dataReductionMode": "disabled"
+	       }, {
+	*/
+
+	var Ldevs []LdevInfo
+
+	//the rest api returns at most MaxElementCount LDEVs per request. the next page starts after the last LDEV returned.
+	var HeadLdevID int
+	HeadLdevID = 0
+	for {
+		p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + ConfigurationManagerString + p.StorageDeviceID + LdevsString + "&headLdevId=" + strconv.Itoa(HeadLdevID) + "&count=" + strconv.FormatInt(p.MaxElementCount, 10)
+		p.RequestType = "GET"
+
+		var Data []interface{}
+		Data, State = RestDataGet(p, 52)
+		Verbose.Println("LDEVs from LDEV ID " + strconv.Itoa(HeadLdevID) + ": " + strconv.Itoa(len(Data)))
+
+		for _, Value1 := range Data {
+			ParsedMap := Value1.(map[string]interface{})
+
+			var LdevElement LdevInfo
+			LdevElement.LdevID = int(ElementFloat64(ParsedMap, "ldevId"))
+			LdevElement.EmulationType = ElementString(ParsedMap, "emulationType")
+			//blocks of 512 bytes to MB
+			LdevElement.Capacity = ElementFloat64(ParsedMap, "blockCapacity") * 512 / 1024 / 1024
+			LdevElement.UsedCapacity = ElementFloat64(ParsedMap, "numOfUsedBlock") * 512 / 1024 / 1024
+			LdevElement.PoolID = -1
+			if ParsedMap["poolId"] != nil {
+				LdevElement.PoolID = int(ElementFloat64(ParsedMap, "poolId"))
+			}
+			LdevElement.Label = ElementString(ParsedMap, "label")
+			if Attributes, ok := ParsedMap["attributes"].([]interface{}); ok {
+				for _, Attribute := range Attributes {
+					LdevElement.Attributes = append(LdevElement.Attributes, Attribute.(string))
+				}
+			}
+			LdevElement.DataReductionMode = ElementString(ParsedMap, "dataReductionMode")
+			if Ports, ok := ParsedMap["ports"].([]interface{}); ok {
+				for _, Port := range Ports {
+					ParsedPortMap := Port.(map[string]interface{})
+					LdevElement.Ports = append(LdevElement.Ports, LdevPort{
+						PortID:          ElementString(ParsedPortMap, "portId"),
+						HostGroupNumber: int(ElementFloat64(ParsedPortMap, "hostGroupNumber")),
+						HostGroupName:   ElementString(ParsedPortMap, "hostGroupName"),
+						Lun:             int(ElementFloat64(ParsedPortMap, "lun")),
+					})
+				}
+			}
+			LdevElement.Status = ElementString(ParsedMap, "status")
+			if ParityGroupIDs, ok := ParsedMap["parityGroupIds"].([]interface{}); ok {
+				for _, ParityGroupID := range ParityGroupIDs {
+					LdevElement.ParityGroupIDs = append(LdevElement.ParityGroupIDs, ParityGroupID.(string))
+				}
+			}
+
+			Ldevs = append(Ldevs, LdevElement)
+			HeadLdevID = LdevElement.LdevID + 1
+		}
+
+		//last page
+		if int64(len(Data)) < p.MaxElementCount {
+			break
+		}
+	}
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'LdevsListGet' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'LdevsListGet' return values number of LDEVs:", len(Ldevs))
+	Debug.Println("Function 'LdevsListGet' ended.")
+
+	//state to OK
+	State = false
+	return Ldevs, State
+}
+
+//LdevsGet shows all LDEVs matching the pool, label and attribute filters
+//return value (string) is empty. if an error happened the state is true. Otherwise false.
+//example: LdevsGet(p)
+func LdevsGet(p Params) (string, bool) {
+	Debug.Println("Function 'LdevsGet' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//initial state is true that means NOK
+	State := true
+
+	Info.Println("Get LDEV information start")
+
+	var Ldevs []LdevInfo
+	Ldevs, State = LdevsListGet(p)
+
+	//add empty string of strings to collect all ldev data to output
+	OutData := [][]string{}
+
+	var Count int
+	Count = 0
+	for _, Ldev := range Ldevs {
+		if !LdevFilter(Ldev, p) {
+			continue
+		}
+		Count = Count + 1
+		OutData, State = LdevInfoFormat(OutData, Ldev, p)
+	}
+
+	Info.Println("LDEVs: " + strconv.Itoa(Count) + " of " + strconv.Itoa(len(Ldevs)) + " match the filters")
+
+	if OutputListFormat(OutData, p) {
+		Warning.Println("The function 'OutputListFormat' returned an Error.")
+	}
+
+	Info.Println("Get LDEV information end")
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'LdevsGet' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'LdevsGet' return values State:", State)
+	Debug.Println("Function 'LdevsGet' end")
+
+	//state to OK
+	State = false
+	return "", State
+}
+
+//LdevFilter checks if a LDEV matches the label and attribute filters. The pool filter is already applied by the request.
+//the label filter is a regular expression compiled once at the start (nil without -label), the attribute filter is not case sensitive
+func LdevFilter(Ldev LdevInfo, p Params) bool {
+	if p.LabelFilter != nil && !p.LabelFilter.MatchString(Ldev.Label) {
+		return false
+	}
+
+	if p.AttributeFilter != "" {
+		var Found bool
+		Found = false
+		for _, Attribute := range Ldev.Attributes {
+			if strings.EqualFold(Attribute, p.AttributeFilter) {
+				Found = true
+			}
+		}
+		if !Found {
+			return false
+		}
+	}
+
+	return true
+}
+
+//LdevPortsFormat formats the LUN paths of a LDEV ex: "CL1-E:CB500_blade3_lpa:1 CL2-E:CB500_blade3_lpa:1"
+func LdevPortsFormat(Ports []LdevPort) string {
+	var PortStrings []string
+	for _, Port := range Ports {
+		PortStrings = append(PortStrings, Port.PortID+":"+Port.HostGroupName+":"+strconv.Itoa(Port.Lun))
+	}
+	if len(PortStrings) == 0 {
+		return "-"
+	}
+	return strings.Join(PortStrings, " ")
+}
+
+//LdevInfoFormat adds the LDEV data to the output data
+func LdevInfoFormat(OutData [][]string, LdevDataSet LdevInfo, p Params) ([][]string, bool) {
+	//initial state is true that means NOK
+	State := true
+
+	var TempData [][]string
+	TempData = OutData
+
+	var PoolID string
+	PoolID = "-"
+	if LdevDataSet.PoolID >= 0 {
+		PoolID = strconv.Itoa(LdevDataSet.PoolID)
+	}
+
+	var Mb2Gb float64
+	Mb2Gb = 1024.0
+
+	//table start line
+	TempData = append(TempData, []string{p.ElementStringStart})
+
+	TempData = append(TempData, []string{HeaderFormat("LDEV ID", "string", p), LdevIDFormat(LdevDataSet.LdevID)})
+	TempData = append(TempData, []string{HeaderFormat("Capacity [GB]", "float64", p), strconv.FormatFloat(LdevDataSet.Capacity/Mb2Gb, 'f', p.RoundPrecision, 64)})
+	TempData = append(TempData, []string{HeaderFormat("Used capacity [GB]", "float64", p), strconv.FormatFloat(LdevDataSet.UsedCapacity/Mb2Gb, 'f', p.RoundPrecision, 64)})
+	TempData = append(TempData, []string{HeaderFormat("Pool ID", "string", p), PoolID})
+	TempData = append(TempData, []string{HeaderFormat("Emulation", "string", p), LdevDataSet.EmulationType})
+	TempData = append(TempData, []string{HeaderFormat("Label", "string", p), LdevDataSet.Label})
+	TempData = append(TempData, []string{HeaderFormat("Attributes", "string", p), strings.Join(LdevDataSet.Attributes, " ")})
+	TempData = append(TempData, []string{HeaderFormat("Data reduction", "string", p), LdevDataSet.DataReductionMode})
+	TempData = append(TempData, []string{HeaderFormat("Ports", "string", p), LdevPortsFormat(LdevDataSet.Ports)})
+	TempData = append(TempData, []string{HeaderFormat("Status", "string", p), LdevDataSet.Status})
+
+	//table end line
+	TempData = append(TempData, []string{p.ElementStringEnd})
+
+	State = false
+	return TempData, State
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"strconv"
+	"time"
+)
+
+//CopyPairInfo type is used for all local copy pair (ShadowImage / Thin Image) actions
+//SvolLdevID and SnapshotPoolID are -1 if not set. CopyProgressRate is -1 if not available.
+type CopyPairInfo struct {
+	ReplicationType  string
+	CopyGroupName    string
+	PvolLdevID       int
+	SvolLdevID       int
+	MuNumber         int
+	Status           string
+	SplitTime        string
+	CopyProgressRate float64
+	SnapshotPoolID   int
+}
+
+//LocalCopyPairsListGet gets all ShadowImage pairs (local-clone-copypairs) and all Thin Image pairs (snapshots)
+//return value ([]CopyPairInfo) are all pairs. if an error happened the state is true. Otherwise false.
+//The function stops with exit status 81 ("JSON parsing error (Return Format is not correct).")
+//example: LocalCopyPairsListGet(p)
+func LocalCopyPairsListGet(p Params) ([]CopyPairInfo, bool) {
+	Debug.Println("Function 'LocalCopyPairsListGet' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//initial state is true that means NOK
+	State := true
+
+	var ConfigurationManagerString string
+	ConfigurationManagerString = "/ConfigurationManager/v1/objects/storages/"
+
+	var Pairs []CopyPairInfo
+
+	/*
+	   https://10.70.5.104/ConfigurationManager/v1/objects/storages/834000470018/local-clone-copypairs
+	   {
+	       "data": [{
+	           "localCloneCopypairId": "SI_GROUP,SI_PAIR01,P-VOL,0",
+	           "copyGroupName": "SI_GROUP",
+	           "copyPairName": "SI_PAIR01",
+	           "replicationType": "SI",
+	           "pvolLdevId": 2816,
+	           "pvolMuNumber": 0,
+	           "pvolStatus": "PAIR",
+	           "svolLdevId": 2900,
+	           "svolStatus": "PAIR",
+	           "copyProgressRate": 100
+	       }, {
+	*/
+
+	Verbose.Println("Get all ShadowImage pairs")
+	p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + ConfigurationManagerString + p.StorageDeviceID + "/local-clone-copypairs"
+	p.RequestType = "GET"
+
+	var Data []interface{}
+	Data, State = RestDataGet(p, 81)
+	for _, Value1 := range Data {
+		ParsedMap := Value1.(map[string]interface{})
+
+		PairElement := CopyPairInfo{
+			ReplicationType:  ElementString(ParsedMap, "replicationType"),
+			CopyGroupName:    ElementString(ParsedMap, "copyGroupName"),
+			PvolLdevID:       int(ElementFloat64(ParsedMap, "pvolLdevId")),
+			SvolLdevID:       -1,
+			MuNumber:         int(ElementFloat64(ParsedMap, "pvolMuNumber")),
+			Status:           ElementString(ParsedMap, "pvolStatus"),
+			SplitTime:        ElementString(ParsedMap, "splitTime"),
+			CopyProgressRate: -1,
+			SnapshotPoolID:   -1,
+		}
+		if ParsedMap["svolLdevId"] != nil {
+			PairElement.SvolLdevID = int(ElementFloat64(ParsedMap, "svolLdevId"))
+		}
+		if ParsedMap["copyProgressRate"] != nil {
+			PairElement.CopyProgressRate = ElementFloat64(ParsedMap, "copyProgressRate")
+		}
+		if PairElement.ReplicationType == "" {
+			PairElement.ReplicationType = "SI"
+		}
+		Pairs = append(Pairs, PairElement)
+	}
+
+	/*
+	   https://10.70.5.104/ConfigurationManager/v1/objects/storages/834000470018/snapshots
+	   {
+	       "data": [{
+	           "snapshotReplicationId": "2816,3",
+	           "snapshotGroupName": "TI_DAILY",
+	           "primaryOrSecondary": "P-VOL",
+	           "status": "PSUS",
+	           "pvolLdevId": 2816,
+	           "muNumber": 3,
+	           "svolLdevId": 2950,
+	           "snapshotPoolId": 30,
+	           "concordanceRate": 100,
+	           "splitTime": "2018-05-20T02:00:01"
+	       }, {
+	*/
+
+	Verbose.Println("Get all Thin Image pairs")
+	p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + ConfigurationManagerString + p.StorageDeviceID + "/snapshots"
+	p.RequestType = "GET"
+
+	Data, State = RestDataGet(p, 81)
+	for _, Value1 := range Data {
+		ParsedMap := Value1.(map[string]interface{})
+
+		PairElement := CopyPairInfo{
+			ReplicationType:  "TI",
+			CopyGroupName:    ElementString(ParsedMap, "snapshotGroupName"),
+			PvolLdevID:       int(ElementFloat64(ParsedMap, "pvolLdevId")),
+			SvolLdevID:       -1,
+			MuNumber:         int(ElementFloat64(ParsedMap, "muNumber")),
+			Status:           ElementString(ParsedMap, "status"),
+			SplitTime:        ElementString(ParsedMap, "splitTime"),
+			CopyProgressRate: -1,
+			SnapshotPoolID:   -1,
+		}
+		if ParsedMap["svolLdevId"] != nil {
+			PairElement.SvolLdevID = int(ElementFloat64(ParsedMap, "svolLdevId"))
+		}
+		if ParsedMap["concordanceRate"] != nil {
+			PairElement.CopyProgressRate = ElementFloat64(ParsedMap, "concordanceRate")
+		}
+		if ParsedMap["snapshotPoolId"] != nil {
+			PairElement.SnapshotPoolID = int(ElementFloat64(ParsedMap, "snapshotPoolId"))
+		}
+		Pairs = append(Pairs, PairElement)
+	}
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'LocalCopyPairsListGet' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'LocalCopyPairsListGet' return values number of pairs:", len(Pairs))
+	Debug.Println("Function 'LocalCopyPairsListGet' ended.")
+
+	//state to OK
+	State = false
+	return Pairs, State
+}
+
+//LocalReplicationGet shows all ShadowImage and Thin Image pairs and the capacity used by snapshots per pool
+//pairs in an abnormal state for their type (CopyPairSuspended) are reported as warning
+//return value (string) is empty. if an error happened the state is true. Otherwise false.
+//example: LocalReplicationGet(p)
+func LocalReplicationGet(p Params) (string, bool) {
+	Debug.Println("Function 'LocalReplicationGet' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//initial state is true that means NOK
+	State := true
+
+	var Mb2Gb float64
+	Mb2Gb = 1024.0
+
+	Info.Println("Get local replication information start")
+
+	var Pairs []CopyPairInfo
+	Pairs, State = LocalCopyPairsListGet(p)
+
+	//the pairs and the snapshot pools are one output
+	OutputReportsStart()
+
+	//add empty string of strings to collect all pair data to output
+	OutData := [][]string{}
+
+	//number of snapshots per pool
+	SnapshotCount := map[int]int{}
+
+	var Suspended int
+	Suspended = 0
+	for _, Pair := range Pairs {
+		var SvolLdevID string
+		SvolLdevID = "-"
+		if Pair.SvolLdevID >= 0 {
+			SvolLdevID = LdevIDFormat(Pair.SvolLdevID)
+		}
+		var SnapshotPoolID string
+		SnapshotPoolID = "-"
+		if Pair.SnapshotPoolID >= 0 {
+			SnapshotPoolID = strconv.Itoa(Pair.SnapshotPoolID)
+			SnapshotCount[Pair.SnapshotPoolID] = SnapshotCount[Pair.SnapshotPoolID] + 1
+		}
+		var CopyProgressRate string
+		CopyProgressRate = "-"
+		if Pair.CopyProgressRate >= 0 {
+			CopyProgressRate = strconv.FormatFloat(Pair.CopyProgressRate, 'f', 0, 64)
+		}
+		var SplitTime string
+		SplitTime = Pair.SplitTime
+		if SplitTime == "" {
+			SplitTime = "-"
+		}
+
+		if CopyPairSuspended(Pair.ReplicationType, Pair.Status) {
+			Warning.Println(Pair.ReplicationType + " pair: " + Pair.CopyGroupName + " P-VOL: " + LdevIDFormat(Pair.PvolLdevID) + " S-VOL: " + SvolLdevID + " is suspended by error (status: " + Pair.Status + ").")
+			Suspended = Suspended + 1
+		}
+
+		OutData = append(OutData, []string{p.ElementStringStart})
+		OutData = append(OutData, []string{HeaderFormat("Type", "string", p), Pair.ReplicationType})
+		OutData = append(OutData, []string{HeaderFormat("Copy group", "string", p), Pair.CopyGroupName})
+		OutData = append(OutData, []string{HeaderFormat("P-VOL", "string", p), LdevIDFormat(Pair.PvolLdevID)})
+		OutData = append(OutData, []string{HeaderFormat("S-VOL", "string", p), SvolLdevID})
+		OutData = append(OutData, []string{HeaderFormat("MU", "float64", p), strconv.Itoa(Pair.MuNumber)})
+		OutData = append(OutData, []string{HeaderFormat("Status", "string", p), Pair.Status})
+		OutData = append(OutData, []string{HeaderFormat("Split time", "string", p), SplitTime})
+		OutData = append(OutData, []string{HeaderFormat("Progress [%]", "float64", p), CopyProgressRate})
+		OutData = append(OutData, []string{HeaderFormat("Snapshot pool", "string", p), SnapshotPoolID})
+		OutData = append(OutData, []string{p.ElementStringEnd})
+	}
+
+	if len(Pairs) == 0 {
+		Info.Println("No ShadowImage or Thin Image pair found.")
+	} else {
+		p.ReportName = "pairs"
+		if OutputListFormat(OutData, p) {
+			Warning.Println("The function 'OutputListFormat' returned an Error.")
+		}
+	}
+
+	//snapshot capacity per pool
+	var Pools []PoolInfo
+	Pools, State = PoolsListGet(p)
+
+	OutData = [][]string{}
+	for _, Pool := range Pools {
+		PoolID, _ := strconv.Atoi(Pool.PoolID)
+		if Pool.PoolType != "HTI" && SnapshotCount[PoolID] == 0 {
+			continue
+		}
+		OutData = append(OutData, []string{p.ElementStringStart})
+		OutData = append(OutData, []string{HeaderFormat("Pool ID", "string", p), Pool.PoolID})
+		OutData = append(OutData, []string{HeaderFormat("Pool name", "string", p), Pool.PoolName})
+		OutData = append(OutData, []string{HeaderFormat("Pool type", "string", p), Pool.PoolType})
+		OutData = append(OutData, []string{HeaderFormat("Snapshots", "float64", p), strconv.Itoa(SnapshotCount[PoolID])})
+		OutData = append(OutData, []string{HeaderFormat("Snapshot used capacity [GB]", "float64", p), strconv.FormatFloat(Pool.SnapshotUsedCapacity/Mb2Gb, 'f', p.RoundPrecision, 64)})
+		OutData = append(OutData, []string{HeaderFormat("Pool used [%]", "float64", p), strconv.FormatFloat(Pool.UsedCapacityRate, 'f', 0, 64)})
+		OutData = append(OutData, []string{p.ElementStringEnd})
+	}
+
+	if len(OutData) > 0 {
+		p.ReportName = "snapshotPools"
+		if OutputListFormat(OutData, p) {
+			Warning.Println("The function 'OutputListFormat' returned an Error.")
+		}
+	}
+	OutputReportsEnd(p)
+
+	if Suspended > 0 {
+		Info.Println("Pairs suspended by error: " + strconv.Itoa(Suspended) + " of " + strconv.Itoa(len(Pairs)))
+	}
+
+	Info.Println("Get local replication information end")
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'LocalReplicationGet' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'LocalReplicationGet' return values State:", State)
+	Debug.Println("Function 'LocalReplicationGet' end")
+
+	//state to OK
+	State = false
+	return "", State
+}
+
+//CopyPairSuspended checks if a pair status is abnormal for the replication type of the pair
+//Thin Image (TI): "PSUS" is a stored snapshot. only "PSUE" (suspended by error) and "PFUS" (suspended as the pool is full) are abnormal.
+//ShadowImage (SI): "PSUS"/"SSUS" is a split pair. only "PSUE", "PFUS" and "SSWS" (suspended swapping) are abnormal.
+//remote replication (TC, UR, GAD): every suspended state ("PSUS", "SSUS", "PSUE", "PFUS", "SSWS") is abnormal.
+func CopyPairSuspended(ReplicationType string, Status string) bool {
+	switch ReplicationType {
+	case "TI":
+		switch Status {
+		case "PSUE", "PFUS":
+			return true
+		}
+	case "SI":
+		switch Status {
+		case "PSUE", "PFUS", "SSWS":
+			return true
+		}
+	default:
+		switch Status {
+		case "PSUS", "SSUS", "PSUE", "PFUS", "SSWS":
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,388 @@
+package main
+
+import (
+	"encoding/csv"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//LdevIDMax is the highest LDEV ID (00:FE:FF)
+const LdevIDMax int = 65279
+
+//LunMapRow type is one line of the map file "<LDEV ID>,<port>,<host group number or name>,<LUN>"
+//Change is "add" (new LUN path), "exists" (the LUN path is already mapped) or "applied" (by an earlier run, see the progress file)
+//Validation is "ok" (with a warning if the LDEV has another LUN on an existing path or in another row) or the reason why the row cannot be applied
+type LunMapRow struct {
+	Row        int
+	LdevID     int
+	PortID     string
+	HostGroup  string
+	Lun        int
+	Target     HostGroupInfo
+	Change     string
+	Validation string
+	Status     string
+}
+
+//LunMap maps the LDEVs of the map file (-mapfile, read by LunMapRead before the session is opened) to the host groups in the order of the file
+//every row is validated against the storage: the LDEV exists, the host group exists, the LUN is free and the LDEV has the same LUN on all paths.
+//the diff is shown first. without -execute nothing is changed (dry-run). if a row is not valid nothing is changed.
+//With -execute the resources are locked and every job is waited for. The last applied row is stored in the progress file (-progressfile).
+//after a failure the next run resumes after this row. the progress file is deleted when all rows are applied.
+//return value (int) is the number of rows that are not valid (exit status 95). Nothing is changed then. if a mapping failed the state is true. Otherwise false.
+//example: Invalid, State := LunMap(p, Rows)
+func LunMap(p Params, Rows []LunMapRow) (int, bool) {
+	Debug.Println("Function 'LunMap' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//initial state is true that means NOK
+	State := true
+
+	if p.Execute {
+		Info.Println("Map LUNs start")
+	} else {
+		Info.Println("Map LUNs start (dry-run. use -execute to map the LUNs)")
+	}
+
+	//rows applied by an earlier run
+	var ProgressFile string
+	ProgressFile = p.ProgressFile
+	if ProgressFile == "" {
+		ProgressFile = p.MapFile + ".progress"
+	}
+	var LastRow int
+	LastRow = LunMapProgressRead(ProgressFile)
+	if LastRow > 0 {
+		Info.Println("Resume after row " + strconv.Itoa(LastRow) + " (progress file: " + ProgressFile + ")")
+	}
+
+	var Invalid int
+	Invalid = LunMapValidate(p, Rows, LastRow)
+
+	//the diff and the result are one output
+	OutputReportsStart()
+
+	//diff
+	OutData := [][]string{}
+	var Add int
+	Add = 0
+	for _, Row := range Rows {
+		if Row.Change == "add" {
+			Add = Add + 1
+		}
+		OutData = append(OutData, []string{p.ElementStringStart})
+		OutData = append(OutData, []string{HeaderFormat("Row", "float64", p), strconv.Itoa(Row.Row)})
+		OutData = append(OutData, []string{HeaderFormat("Change", "string", p), LunMapChangeFormat(Row.Change)})
+		OutData = append(OutData, []string{HeaderFormat("LDEV ID", "string", p), LdevIDFormat(Row.LdevID)})
+		OutData = append(OutData, []string{HeaderFormat("Host group", "string", p), LunMapHostGroupFormat(Row)})
+		OutData = append(OutData, []string{HeaderFormat("LUN", "float64", p), strconv.Itoa(Row.Lun)})
+		OutData = append(OutData, []string{HeaderFormat("Validation", "string", p), Row.Validation})
+		OutData = append(OutData, []string{p.ElementStringEnd})
+	}
+	p.ReportName = "diff"
+	if OutputListFormat(OutData, p) {
+		Warning.Println("The function 'OutputListFormat' returned an Error.")
+	}
+
+	if Invalid > 0 {
+		Error.Println(strconv.Itoa(Invalid) + " row(s) of the map file are not valid. No action will take place.")
+		OutputReportsEnd(p)
+		return Invalid, false
+	}
+
+	if !p.Execute {
+		OutputReportsEnd(p)
+		Info.Println("LUN paths to add: " + strconv.Itoa(Add))
+		Info.Println("Map LUNs end (dry-run)")
+		return 0, false
+	}
+
+	_, LockState := ResourceLock(p)
+
+	//the rows are applied in order. after a failure the remaining rows are skipped. all rows are skipped if the resources cannot be locked
+	var Applied int
+	Applied = 0
+	var Failed bool
+	Failed = LockState
+	for i := range Rows {
+		switch {
+		case Rows[i].Change != "add":
+			Rows[i].Status = Rows[i].Change
+		case Failed:
+			Rows[i].Status = "skipped"
+		default:
+			Job, JobState := LunCreate(p, Rows[i].Target, strconv.Itoa(Rows[i].LdevID), Rows[i].Lun)
+			if JobState {
+				Rows[i].Status = "failed " + JobErrorFormat(Job)
+				Failed = true
+				continue
+			}
+			Rows[i].Status = "done"
+			Applied = Applied + 1
+		}
+
+		//all rows up to this one are applied
+		if !Failed {
+			LunMapProgressWrite(ProgressFile, Rows[i].Row)
+		}
+	}
+
+	if !LockState {
+		ResourceUnlock(p)
+	}
+
+	OutData = [][]string{}
+	for _, Row := range Rows {
+		OutData = append(OutData, []string{p.ElementStringStart})
+		OutData = append(OutData, []string{HeaderFormat("Row", "float64", p), strconv.Itoa(Row.Row)})
+		OutData = append(OutData, []string{HeaderFormat("LDEV ID", "string", p), LdevIDFormat(Row.LdevID)})
+		OutData = append(OutData, []string{HeaderFormat("Host group", "string", p), LunMapHostGroupFormat(Row)})
+		OutData = append(OutData, []string{HeaderFormat("LUN", "float64", p), strconv.Itoa(Row.Lun)})
+		OutData = append(OutData, []string{HeaderFormat("Status", "string", p), Row.Status})
+		OutData = append(OutData, []string{p.ElementStringEnd})
+	}
+	p.ReportName = "result"
+	if OutputListFormat(OutData, p) {
+		Warning.Println("The function 'OutputListFormat' returned an Error.")
+	}
+	OutputReportsEnd(p)
+
+	if Failed {
+		Warning.Println("The mapping stopped after a failure. The next run resumes after the last applied row (progress file: " + ProgressFile + ").")
+	} else {
+		os.Remove(ProgressFile)
+	}
+
+	Info.Println("LUN paths added: " + strconv.Itoa(Applied) + " of " + strconv.Itoa(Add))
+	Info.Println("Map LUNs end")
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'LunMap' - Elapsed time ", TimeDiff)
+
+	//state to NOK if a mapping failed
+	State = Failed
+
+	Debug.Println("Function 'LunMap' return values Applied:", Applied, "State:", State)
+	Debug.Println("Function 'LunMap' ended.")
+
+	return 0, State
+}
+
+//LunMapValidate checks every row against the LDEVs, host groups and LUNs of the storage and sets the change and the validation of the rows
+//rows up to LastRow were applied by an earlier run and are not validated again
+//return value (int) is the number of rows that are not valid
+func LunMapValidate(p Params, Rows []LunMapRow, LastRow int) int {
+	Debug.Println("Function 'LunMapValidate' started.")
+
+	//all defined LDEVs with their LUN paths
+	p.PoolID = -1
+	Ldevs, _ := LdevsListGet(p)
+	LdevsByID := map[int]LdevInfo{}
+	for _, Ldev := range Ldevs {
+		LdevsByID[Ldev.LdevID] = Ldev
+	}
+
+	HostGroups, _ := HostGroupsListGet(p)
+
+	//the LUNs of the host groups are only requested once. key: "<port>,<host group number>"
+	HostGroupLuns := map[string]map[int]int{}
+
+	//the LUN of every LDEV in the map file. all paths of an LDEV must have the same LUN
+	LdevLuns := map[int]int{}
+
+	var Invalid int
+	Invalid = 0
+	for i := range Rows {
+		Row := &Rows[i]
+		Row.Validation = "ok"
+
+		if Row.Row <= LastRow {
+			Row.Change = "applied"
+			continue
+		}
+
+		Ldev, ok := LdevsByID[Row.LdevID]
+		if !ok {
+			Row.Validation = "the LDEV does not exist"
+			Invalid = Invalid + 1
+			continue
+		}
+
+		var Found bool
+		Found = false
+		for _, HostGroup := range HostGroups {
+			if HostGroup.PortID == Row.PortID && (strconv.Itoa(HostGroup.HostGroupNumber) == Row.HostGroup || HostGroup.HostGroupName == Row.HostGroup) {
+				Row.Target = HostGroup
+				Found = true
+				break
+			}
+		}
+		if !Found {
+			Row.Validation = "the host group does not exist"
+			Invalid = Invalid + 1
+			continue
+		}
+
+		HostGroupID := Row.Target.PortID + "," + strconv.Itoa(Row.Target.HostGroupNumber)
+		if _, ok := HostGroupLuns[HostGroupID]; !ok {
+			HostGroupLuns[HostGroupID] = map[int]int{}
+			Luns, _ := HostGroupLunsListGet(p, Row.Target)
+			for _, Lun := range Luns {
+				HostGroupLuns[HostGroupID][Lun.Lun] = Lun.LdevID
+			}
+		}
+
+		//the same LUN on all existing paths and all rows of the LDEV is recommended (ex: for clusters). a different LUN is a warning only
+		if Lun, ok := LdevLuns[Row.LdevID]; ok && Lun != Row.Lun {
+			Row.Validation = "ok (the LDEV has the LUN " + strconv.Itoa(Lun) + " in another row)"
+			Warning.Println("Row " + strconv.Itoa(Row.Row) + ": the LDEV " + LdevIDFormat(Row.LdevID) + " has the LUN " + strconv.Itoa(Lun) + " in another row.")
+		}
+		for _, Port := range Ldev.Ports {
+			if Port.Lun != Row.Lun {
+				Row.Validation = "ok (the LDEV has the LUN " + strconv.Itoa(Port.Lun) + " on an existing path)"
+				Warning.Println("Row " + strconv.Itoa(Row.Row) + ": the LDEV " + LdevIDFormat(Row.LdevID) + " has the LUN " + strconv.Itoa(Port.Lun) + " on the existing path " + Port.PortID + ".")
+				break
+			}
+		}
+		if _, ok := LdevLuns[Row.LdevID]; !ok {
+			LdevLuns[Row.LdevID] = Row.Lun
+		}
+
+		if LdevID, ok := HostGroupLuns[HostGroupID][Row.Lun]; ok {
+			if LdevID != Row.LdevID {
+				Row.Validation = "the LUN is used by the LDEV " + LdevIDFormat(LdevID)
+				Invalid = Invalid + 1
+				continue
+			}
+			Row.Change = "exists"
+			continue
+		}
+
+		//the LUN is used by the rows that follow
+		Row.Change = "add"
+		HostGroupLuns[HostGroupID][Row.Lun] = Row.LdevID
+	}
+
+	Debug.Println("Function 'LunMapValidate' return values Invalid:", Invalid)
+	Debug.Println("Function 'LunMapValidate' ended.")
+	return Invalid
+}
+
+//LunMapRead reads the map file. every line is "<LDEV ID>,<port>,<host group number or name>,<LUN>". lines starting with # and a header line starting with "ldev" are skipped.
+//the LDEV ID is decimal (ex: 1024) or hex with ":" (ex: 04:00 or 00:04:00)
+//The function stops with exit status 76 ("The map file cannot be read.")
+//The function stops with exit status 77 ("The map file contains an invalid line.")
+func LunMapRead(FileName string) ([]LunMapRow, bool) {
+	Debug.Println("Function 'LunMapRead' started.")
+
+	//initial state is true that means NOK
+	State := true
+
+	File, err := os.Open(FileName)
+	if err != nil {
+		Error.Println("The map file (" + FileName + ") cannot be read: " + err.Error())
+		os.Exit(76)
+	}
+	defer File.Close()
+
+	Reader := csv.NewReader(File)
+	Reader.Comment = '#'
+	Reader.FieldsPerRecord = 4
+	Records, err := Reader.ReadAll()
+	if err != nil {
+		Error.Println("The map file (" + FileName + ") contains an invalid line: " + err.Error())
+		os.Exit(77)
+	}
+
+	var Rows []LunMapRow
+	for i, Record := range Records {
+		//header line
+		if i == 0 && strings.HasPrefix(strings.ToLower(strings.TrimSpace(Record[0])), "ldev") {
+			continue
+		}
+
+		LdevID, LdevState := LdevIDParse(strings.TrimSpace(Record[0]))
+		Lun, err := strconv.Atoi(strings.TrimSpace(Record[3]))
+		if LdevState || err != nil || Lun < 0 || Lun > ProvisionMaxLun {
+			Error.Println("The map file (" + FileName + ") contains an invalid line: " + strings.Join(Record, ","))
+			os.Exit(77)
+		}
+
+		Rows = append(Rows, LunMapRow{
+			Row:       len(Rows) + 1,
+			LdevID:    LdevID,
+			PortID:    strings.TrimSpace(Record[1]),
+			HostGroup: strings.TrimSpace(Record[2]),
+			Lun:       Lun,
+		})
+	}
+
+	Debug.Println("Function 'LunMapRead' return values number of rows:", len(Rows))
+	Debug.Println("Function 'LunMapRead' ended.")
+
+	//state to OK
+	State = false
+	return Rows, State
+}
+
+//LunMapProgressRead returns the last applied row of the progress file. if there is no progress file 0 is returned.
+func LunMapProgressRead(FileName string) int {
+	Content, err := ioutil.ReadFile(FileName)
+	if err != nil {
+		return 0
+	}
+	LastRow, err := strconv.Atoi(strings.TrimSpace(string(Content)))
+	if err != nil {
+		Warning.Println("The progress file (" + FileName + ") is not valid. All rows are validated.")
+		return 0
+	}
+	return LastRow
+}
+
+//LunMapProgressWrite stores the last applied row in the progress file
+func LunMapProgressWrite(FileName string, LastRow int) {
+	if err := ioutil.WriteFile(FileName, []byte(strconv.Itoa(LastRow)+"\n"), 0644); err != nil {
+		Warning.Println("The progress file (" + FileName + ") cannot be written: " + err.Error())
+	}
+}
+
+//LunMapChangeFormat returns the change of a row as diff: "+" add, "=" exists, "applied" by an earlier run. rows that are not valid are "invalid"
+func LunMapChangeFormat(Change string) string {
+	switch Change {
+	case "add":
+		return "+"
+	case "exists":
+		return "="
+	case "":
+		return "invalid"
+	}
+	return Change
+}
+
+//LunMapHostGroupFormat returns the host group of a row "<port>,<host group number> (<host group name>)". if the host group was not found it is returned as in the map file
+func LunMapHostGroupFormat(Row LunMapRow) string {
+	if Row.Target.PortID == "" {
+		return Row.PortID + "," + Row.HostGroup
+	}
+	return Row.Target.PortID + "," + strconv.Itoa(Row.Target.HostGroupNumber) + " (" + Row.Target.HostGroupName + ")"
+}
+
+//LdevIDParse returns the LDEV ID of a decimal (ex: 1024) or hex LDEV ID with ":" (ex: 04:00 or 00:04:00). if it is not valid or not 0 to LdevIDMax the state is true. Otherwise false.
+func LdevIDParse(LdevID string) (int, bool) {
+	var Value int64
+	var err error
+	if strings.Contains(LdevID, ":") {
+		Value, err = strconv.ParseInt(strings.Replace(LdevID, ":", "", -1), 16, 64)
+	} else {
+		Value, err = strconv.ParseInt(LdevID, 10, 64)
+	}
+	if err != nil || Value < 0 || Value > int64(LdevIDMax) {
+		return int(Value), true
+	}
+	return int(Value), false
+}
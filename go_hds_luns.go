@@ -0,0 +1,222 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//HostGroupInfo type is used for all HostGroup Element actions
+type HostGroupInfo struct {
+	PortID          string
+	HostGroupNumber int
+	HostGroupName   string
+	HostMode        string
+	HostModeOptions []int
+}
+
+//LunInfo type is one LUN path of a HostGroup
+//Reserves contains the names of all "luHostReserve" elements that are set (ex: persistent)
+type LunInfo struct {
+	PortID          string
+	HostGroupNumber int
+	HostGroupName   string
+	HostMode        string
+	Lun             int
+	LdevID          int
+	HostModeOptions []int
+	Reserves        []string
+}
+
+//HostGroupsListGet gets all HostGroups of the storage
+//return value ([]HostGroupInfo) are all HostGroups. if an error happened the state is true. Otherwise false.
+//The function stops with exit status 41 ("JSON parsing error (Return Format is not correct).")
+//example: HostGroupsListGet(p)
+func HostGroupsListGet(p Params) ([]HostGroupInfo, bool) {
+	Debug.Println("Function 'HostGroupsListGet' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//initial state is true that means NOK
+	State := true
+
+	var ConfigurationManagerString string
+	ConfigurationManagerString = "/ConfigurationManager/v1/objects/storages/"
+	var PostString string
+	PostString = "/host-groups?count=" + strconv.FormatInt(p.MaxElementCount, 10)
+
+	Verbose.Println("Get general information of all HostGroups")
+
+	/*
+	   http://10.70.4.145/ConfigurationManager/v1/objects/storages/800000058068/host-groups?count=16384
+	   {
+	       "data": [{
+	           "hostGroupId": "CL1-A,0",
+	           "portId": "CL1-A",
+	           "hostGroupNumber": 0,
+	           "hostGroupName": "1A-G00",
+	           "hostMode": "LINUX/IRIX",
+	           "hostModeOptions": [2, 22]
+	       }, {
+
+	*/
+
+	p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + ConfigurationManagerString + p.StorageDeviceID + PostString
+	p.RequestType = "GET"
+
+	var Data []interface{}
+	Data, State = RestDataGet(p, 41)
+
+	var HostGroups []HostGroupInfo
+	for _, Value1 := range Data {
+		ParsedHostGroupMap := Value1.(map[string]interface{})
+		HostGroup := HostGroupInfo{
+			PortID:          ElementString(ParsedHostGroupMap, "portId"),
+			HostGroupNumber: int(ElementFloat64(ParsedHostGroupMap, "hostGroupNumber")),
+			HostGroupName:   ElementString(ParsedHostGroupMap, "hostGroupName"),
+			HostMode:        ElementString(ParsedHostGroupMap, "hostMode"),
+		}
+
+		//the host mode options are only returned if they are set
+		if HostModeOptions, ok := ParsedHostGroupMap["hostModeOptions"].([]interface{}); ok {
+			for _, HostModeOption := range HostModeOptions {
+				HostGroup.HostModeOptions = append(HostGroup.HostModeOptions, int(HostModeOption.(float64)))
+			}
+		}
+
+		HostGroups = append(HostGroups, HostGroup)
+	}
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'HostGroupsListGet' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'HostGroupsListGet' return values number of HostGroups:", len(HostGroups))
+	Debug.Println("Function 'HostGroupsListGet' ended.")
+
+	//state to OK
+	State = false
+	return HostGroups, State
+}
+
+//LunsListGet walks through all HostGroups and gets all their LUN paths
+//return value ([]LunInfo) are all LUN paths. if an error happened the state is true. Otherwise false.
+//The function stops with exit status 41 ("JSON parsing error (Return Format is not correct).")
+//example: LunsListGet(p)
+func LunsListGet(p Params) ([]LunInfo, bool) {
+	Debug.Println("Function 'LunsListGet' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//initial state is true that means NOK
+	State := true
+
+	var HostGroups []HostGroupInfo
+	HostGroups, State = HostGroupsListGet(p)
+	Debug.Println("Number of HostGroups", len(HostGroups))
+
+	var Luns []LunInfo
+	for _, HostGroup := range HostGroups {
+		var HostGroupLuns []LunInfo
+		HostGroupLuns, State = HostGroupLunsListGet(p, HostGroup)
+		Luns = append(Luns, HostGroupLuns...)
+	}
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'LunsListGet' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'LunsListGet' return values number of LUNs:", len(Luns))
+	Debug.Println("Function 'LunsListGet' ended.")
+
+	//state to OK
+	State = false
+	return Luns, State
+}
+
+//HostGroupLunsListGet gets all LUN paths of one HostGroup
+//return value ([]LunInfo) are the LUN paths. if an error happened the state is true. Otherwise false.
+//The function stops with exit status 41 ("JSON parsing error (Return Format is not correct).")
+//example: HostGroupLunsListGet(p, HostGroup)
+func HostGroupLunsListGet(p Params, HostGroup HostGroupInfo) ([]LunInfo, bool) {
+	//initial state is true that means NOK
+	State := true
+
+	var ConfigurationManagerString string
+	ConfigurationManagerString = "/ConfigurationManager/v1/objects/storages/"
+
+	Verbose.Println("Get the LUNs of the HostGroup: " + HostGroup.PortID + " " + HostGroup.HostGroupName + "(" + strconv.Itoa(HostGroup.HostGroupNumber) + ")")
+
+	//------------------------------------
+	//get lun information
+	p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + ConfigurationManagerString + p.StorageDeviceID + "/luns?portId=" + HostGroup.PortID + "&hostGroupNumber=" + strconv.Itoa(HostGroup.HostGroupNumber)
+	p.RequestType = "GET"
+
+	var Data []interface{}
+	Data, State = RestDataGet(p, 41)
+
+	Debug.Println("Number of LUNs", len(Data))
+	var Luns []LunInfo
+	for _, Value1 := range Data {
+		ParsedLunsMap := Value1.(map[string]interface{})
+
+		/*
+		   http://10.70.4.145/ConfigurationManager/v1/objects/storages/800000058068/luns?portId=CL1-B&hostGroupNumber=1
+
+		   				"data": [{
+		   			"lunId": "CL1-B,1,1",
+		   			"portId": "CL1-B",
+		   			"hostGroupNumber": 1,
+		   			"hostMode": "WIN_EX",
+		   			"lun": 1,
+		   			"ldevId": 13312,
+		   			"isCommandDevice": false,
+		   			"luHostReserve": {
+		   				"openSystem": false,
+		   				"persistent": false,
+		   				"pgrKey": false,
+		   				"mainframe": false,
+		   				"acaReserve": false
+		   			},
+		   			"hostModeOptions": [40, 73]
+		*/
+
+		LunElement := LunInfo{
+			PortID:          HostGroup.PortID,
+			HostGroupNumber: HostGroup.HostGroupNumber,
+			HostGroupName:   HostGroup.HostGroupName,
+			HostMode:        ElementString(ParsedLunsMap, "hostMode"),
+			Lun:             int(ElementFloat64(ParsedLunsMap, "lun")),
+			LdevID:          int(ElementFloat64(ParsedLunsMap, "ldevId")),
+		}
+
+		if HostModeOptions, ok := ParsedLunsMap["hostModeOptions"].([]interface{}); ok {
+			for _, HostModeOption := range HostModeOptions {
+				LunElement.HostModeOptions = append(LunElement.HostModeOptions, int(HostModeOption.(float64)))
+			}
+		}
+
+		//map[persistent:false pgrKey:false mainframe:false acaReserve:false openSystem:false]
+		if Reserves, ok := ParsedLunsMap["luHostReserve"].(map[string]interface{}); ok {
+			for Key3, Reserve := range Reserves {
+				if Set, ok := Reserve.(bool); ok && Set {
+					LunElement.Reserves = append(LunElement.Reserves, Key3)
+				}
+			}
+			sort.Strings(LunElement.Reserves)
+		}
+
+		Luns = append(Luns, LunElement)
+	}
+
+	//state to OK
+	State = false
+	return Luns, State
+}
+
+//LunReservesFormat returns the "luHostReserve" elements that are set of a LUN (ex: "(persistent=true; mainframe=true)"). it is empty without reservations.
+func LunReservesFormat(Lun LunInfo) string {
+	if len(Lun.Reserves) == 0 {
+		return ""
+	}
+	return "(" + strings.Join(Lun.Reserves, "=true; ") + "=true)"
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+//MarkdownHeaderDone is true after the header block of the markdown output is written. the header is written once before the first report.
+var MarkdownHeaderDone bool
+
+//MarkdownStorage is the storage of the header block of the markdown output. it is requested when the session is opened (MarkdownStorageGet) or taken from the snapshots (type 'snapshot-diff').
+var MarkdownStorage StorageInfo
+
+//MarkdownRestVersion is the REST API version of the header block of the markdown output if the type does not get it (p.RestVersion)
+var MarkdownRestVersion string
+
+//MarkdownCollected is the collection time of the header block of the markdown output. the time of the output is used if it is not set.
+var MarkdownCollected time.Time
+
+//OutputMarkdown outputs the data as GitHub flavoured Markdown table to the command line. Every element is one row.
+//The table has the report (p.ReportName) or the type (-type) as title. The descriptors of the first element without the type are the header. float64 columns are right aligned.
+//The first table is preceded by the header block with the storage, the REST API version and the collection time.
+func OutputMarkdown(Data [][]string, p Params) bool {
+	Debug.Println("Function 'OutputMarkdown' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//true -> NOK
+	//false -> OK
+	State := false
+
+	// if no data is available skip output
+	if len(Data) == 0 {
+		Error.Println("No Data to output.")
+		return State
+	}
+
+	if !MarkdownHeaderDone {
+		fmt.Print(MarkdownHeader(p))
+		MarkdownHeaderDone = true
+	}
+
+	var Header []string
+	var Alignments []string
+	var Rows []string
+	var Row []string
+	var HeaderDone bool
+	HeaderDone = false
+	for i := 0; i < len(Data); i++ {
+		switch Data[i][0] {
+		case p.ElementStringStart:
+			Row = []string{}
+		case p.ElementStringEnd:
+			HeaderDone = true
+			Rows = append(Rows, "| "+strings.Join(Row, " | ")+" |")
+		default:
+			if !HeaderDone {
+				Key, Type := HeaderSplit(Data[i][0])
+				Header = append(Header, MarkdownCell(Key))
+				if Type == "float64" {
+					Alignments = append(Alignments, "---:")
+				} else {
+					Alignments = append(Alignments, "---")
+				}
+			}
+			Row = append(Row, MarkdownCell(Data[i][1]))
+		}
+	}
+
+	fmt.Println("## " + ReportTitle(p))
+	fmt.Println("")
+	fmt.Println("| " + strings.Join(Header, " | ") + " |")
+	fmt.Println("| " + strings.Join(Alignments, " | ") + " |")
+	for _, Line := range Rows {
+		fmt.Println(Line)
+	}
+	fmt.Println("")
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'OutputMarkdown' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'OutputMarkdown' return values State:", State)
+	Debug.Println("Function 'OutputMarkdown' ended.")
+	return State
+}
+
+//MarkdownHeader returns the header block of the markdown output. the title and a list with the model and the serial number of the storage, the REST API version and the collection time.
+//no request is sent. the storage is MarkdownStorage or the StorageDeviceID of the session (p.StorageDeviceID). the REST API version is p.RestVersion or MarkdownRestVersion. values that are not known are shown as "-".
+func MarkdownHeader(p Params) string {
+	var Storage StorageInfo
+	Storage = MarkdownStorage
+	if Storage.StorageDeviceID == "" {
+		Storage.StorageDeviceID = p.StorageDeviceID
+	}
+
+	var RestVersion string
+	RestVersion = p.RestVersion
+	if RestVersion == "" {
+		RestVersion = MarkdownRestVersion
+	}
+
+	var Collected time.Time
+	Collected = MarkdownCollected
+	if Collected.IsZero() {
+		Collected = time.Now()
+	}
+
+	var Header strings.Builder
+	Header.WriteString("# HiCHPoolInfo " + MarkdownCell(p.OutputType) + " report\n\n")
+	Header.WriteString("- **Storage:** " + MarkdownValue(StorageName(Storage)) + "\n")
+	Header.WriteString("- **Storage device ID:** " + MarkdownValue(Storage.StorageDeviceID) + "\n")
+	Header.WriteString("- **Microcode:** " + MarkdownValue(Storage.MicroVersion) + "\n")
+	Header.WriteString("- **REST API version:** " + MarkdownValue(RestVersion) + "\n")
+	Header.WriteString("- **Collected:** " + Collected.Format(time.RFC3339) + "\n\n")
+	return Header.String()
+}
+
+//MarkdownStorageGet gets the storage and the REST API version (if p.RestVersion is not set by the type) of the header block of the markdown output (-output markdown) when the session is opened. the output itself does not send requests.
+//example: MarkdownStorageGet(p)
+func MarkdownStorageGet(p Params) {
+	if p.OutputStyle != "markdown" {
+		return
+	}
+	MarkdownStorage, _ = StorageInfoGet(p)
+	if p.RestVersion == "" {
+		MarkdownRestVersion, _ = StorageRestAPIVersionGet(p)
+	}
+	MarkdownCollected = time.Now()
+}
+
+//MarkdownValue escapes a value of the header block. an empty value is shown as "-".
+func MarkdownValue(Value string) string {
+	if Value == "" {
+		return "-"
+	}
+	return MarkdownCell(Value)
+}
+
+//MarkdownCell escapes a value for a markdown table cell. "|" is escaped and line breaks are replaced by <br>.
+func MarkdownCell(Value string) string {
+	Value = strings.Replace(Value, "|", "\\|", -1)
+	Value = strings.Replace(Value, "\r\n", "<br>", -1)
+	return strings.Replace(Value, "\n", "<br>", -1)
+}
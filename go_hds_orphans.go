@@ -0,0 +1,121 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"time"
+)
+
+//LdevsOrphanGet shows all DP volumes without any LUN path. They still consume pool capacity.
+//The LUN paths are taken from the HostGroup walk (LunsListGet). The list is sorted by the used (reclaimable) capacity.
+//return value (string) is empty. if an error happened the state is true. Otherwise false.
+//example: LdevsOrphanGet(p)
+func LdevsOrphanGet(p Params) (string, bool) {
+	Debug.Println("Function 'LdevsOrphanGet' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//initial state is true that means NOK
+	State := true
+
+	var Mb2Gb float64
+	Mb2Gb = 1024.0
+
+	Info.Println("Get orphaned LDEV information start")
+
+	var Ldevs []LdevInfo
+	Ldevs, State = LdevsListGet(p)
+
+	var Luns []LunInfo
+	Luns, State = LunsListGet(p)
+
+	//all LDEVs that have at least one LUN path
+	Mapped := map[int]bool{}
+	for _, Lun := range Luns {
+		Mapped[Lun.LdevID] = true
+	}
+
+	var Orphans []LdevInfo
+	var Reclaimable float64
+	Reclaimable = 0
+	for _, Ldev := range Ldevs {
+		if Mapped[Ldev.LdevID] || !LdevIsDpVolume(Ldev) || !LdevFilter(Ldev, p) {
+			continue
+		}
+		Orphans = append(Orphans, Ldev)
+		Reclaimable = Reclaimable + Ldev.UsedCapacity
+	}
+
+	//most reclaimable space first
+	sort.SliceStable(Orphans, func(i, j int) bool {
+		return Orphans[i].UsedCapacity > Orphans[j].UsedCapacity
+	})
+
+	//add empty string of strings to collect all orphan data to output
+	OutData := [][]string{}
+	for _, Ldev := range Orphans {
+		OutData = append(OutData, []string{p.ElementStringStart})
+		OutData = append(OutData, []string{HeaderFormat("LDEV ID", "string", p), LdevIDFormat(Ldev.LdevID)})
+		OutData = append(OutData, []string{HeaderFormat("Pool ID", "string", p), strconv.Itoa(Ldev.PoolID)})
+		OutData = append(OutData, []string{HeaderFormat("Label", "string", p), Ldev.Label})
+		OutData = append(OutData, []string{HeaderFormat("Capacity [GB]", "float64", p), strconv.FormatFloat(Ldev.Capacity/Mb2Gb, 'f', p.RoundPrecision, 64)})
+		OutData = append(OutData, []string{HeaderFormat("Reclaimable capacity [GB]", "float64", p), strconv.FormatFloat(Ldev.UsedCapacity/Mb2Gb, 'f', p.RoundPrecision, 64)})
+		OutData = append(OutData, []string{HeaderFormat("Status", "string", p), Ldev.Status})
+		OutData = append(OutData, []string{p.ElementStringEnd})
+	}
+
+	if len(Orphans) == 0 {
+		Info.Println("No DP volume without LUN path found.")
+	} else {
+		if OutputListFormat(OutData, p) {
+			Warning.Println("The function 'OutputListFormat' returned an Error.")
+		}
+		Info.Println("DP volumes without LUN path: " + strconv.Itoa(len(Orphans)) + " reclaimable capacity: " + strconv.FormatFloat(Reclaimable/Mb2Gb, 'f', p.RoundPrecision, 64) + "GB")
+	}
+
+	Info.Println("Get orphaned LDEV information end")
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'LdevsOrphanGet' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'LdevsOrphanGet' return values State:", State)
+	Debug.Println("Function 'LdevsOrphanGet' end")
+
+	//state to OK
+	State = false
+	return "", State
+}
+
+//LdevIsDpVolume checks if a LDEV is a DP volume (HDP or HDT) and not a pool volume
+func LdevIsDpVolume(Ldev LdevInfo) bool {
+	if Ldev.PoolID < 0 {
+		return false
+	}
+
+	var DpVolume bool
+	DpVolume = false
+	for _, Attribute := range Ldev.Attributes {
+		switch Attribute {
+		case "HDP", "HDT":
+			DpVolume = true
+		case "POOL":
+			//pool volumes have a pool id too
+			return false
+		}
+	}
+	return DpVolume
+}
+
+//LdevIsPoolVolume checks if a LDEV is a pool volume of its pool
+func LdevIsPoolVolume(Ldev LdevInfo) bool {
+	if Ldev.PoolID < 0 {
+		return false
+	}
+
+	for _, Attribute := range Ldev.Attributes {
+		if Attribute == "POOL" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"time"
+)
+
+//PoolConsumersGet shows all DP volumes of a pool (-poolid) with their LUN paths ranked by the used capacity.
+//if a snapshot directory is set the growth since the last stored snapshot is shown and a new snapshot is stored.
+//return value (string) is empty. if an error happened the state is true. Otherwise false.
+//example: PoolConsumersGet(p)
+func PoolConsumersGet(p Params) (string, bool) {
+	Debug.Println("Function 'PoolConsumersGet' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//initial state is true that means NOK
+	State := true
+
+	var Mb2Gb float64
+	Mb2Gb = 1024.0
+
+	Info.Println("Get consumers of pool " + strconv.Itoa(p.PoolID) + " start")
+
+	//pool usage against the thresholds
+	var Pools []PoolInfo
+	Pools, State = PoolsListGet(p)
+	for _, Pool := range Pools {
+		if Pool.PoolID == strconv.Itoa(p.PoolID) {
+			Info.Println("Pool: " + Pool.PoolID + " (" + Pool.PoolName + ") used: " + strconv.FormatFloat(Pool.UsedCapacityRate, 'f', 0, 64) + "% warning threshold: " + strconv.FormatFloat(Pool.WarningThreshold, 'f', 0, 64) + "% depletion threshold: " + strconv.FormatFloat(Pool.DepletionThreshold, 'f', 0, 64) + "%")
+		}
+	}
+
+	//the request returns only the dp volumes of the pool
+	var Ldevs []LdevInfo
+	Ldevs, State = LdevsListGet(p)
+
+	//used capacity of the last snapshot
+	var Previous Snapshot
+	var NoPrevious bool
+	NoPrevious = true
+	PreviousUsed := map[int]float64{}
+	if p.SnapshotDir != "" {
+		Previous, NoPrevious = SnapshotLatestRead(p.SnapshotDir, p.StorageDeviceID, func(Snap Snapshot) bool {
+			for _, Ldev := range Snap.Ldevs {
+				if Ldev.PoolID == p.PoolID {
+					return true
+				}
+			}
+			return false
+		})
+		if !NoPrevious {
+			Info.Println("Growth since the snapshot of " + Previous.Time.Format(time.RFC3339))
+			for _, Ldev := range Previous.Ldevs {
+				PreviousUsed[Ldev.LdevID] = Ldev.UsedCapacity
+			}
+		}
+	}
+
+	//most used capacity first
+	sort.SliceStable(Ldevs, func(i, j int) bool {
+		return Ldevs[i].UsedCapacity > Ldevs[j].UsedCapacity
+	})
+
+	//add empty string of strings to collect all consumer data to output
+	OutData := [][]string{}
+	for _, Ldev := range Ldevs {
+		if !LdevIsDpVolume(Ldev) {
+			continue
+		}
+
+		var Growth string
+		Growth = "n/a"
+		if Used, ok := PreviousUsed[Ldev.LdevID]; ok {
+			Growth = strconv.FormatFloat((Ldev.UsedCapacity-Used)/Mb2Gb, 'f', p.RoundPrecision, 64)
+		}
+
+		OutData = append(OutData, []string{p.ElementStringStart})
+		OutData = append(OutData, []string{HeaderFormat("LDEV ID", "string", p), LdevIDFormat(Ldev.LdevID)})
+		OutData = append(OutData, []string{HeaderFormat("Label", "string", p), Ldev.Label})
+		OutData = append(OutData, []string{HeaderFormat("Used capacity [GB]", "float64", p), strconv.FormatFloat(Ldev.UsedCapacity/Mb2Gb, 'f', p.RoundPrecision, 64)})
+		OutData = append(OutData, []string{HeaderFormat("Growth [GB]", "float64", p), Growth})
+		OutData = append(OutData, []string{HeaderFormat("Capacity [GB]", "float64", p), strconv.FormatFloat(Ldev.Capacity/Mb2Gb, 'f', p.RoundPrecision, 64)})
+		OutData = append(OutData, []string{HeaderFormat("LUN paths (port:host group:LUN)", "string", p), LdevPortsFormat(Ldev.Ports)})
+		OutData = append(OutData, []string{p.ElementStringEnd})
+	}
+
+	if len(OutData) == 0 {
+		Info.Println("No DP volume found in pool " + strconv.Itoa(p.PoolID) + ".")
+	} else {
+		if OutputListFormat(OutData, p) {
+			Warning.Println("The function 'OutputListFormat' returned an Error.")
+		}
+	}
+
+	//store the used capacity for the next run
+	if p.SnapshotDir != "" {
+		SnapshotWrite(p.SnapshotDir, Snapshot{Time: TimeStart, StorageDeviceID: p.StorageDeviceID, Ldevs: Ldevs})
+	}
+
+	Info.Println("Get consumers of pool " + strconv.Itoa(p.PoolID) + " end")
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'PoolConsumersGet' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'PoolConsumersGet' return values State:", State)
+	Debug.Println("Function 'PoolConsumersGet' end")
+
+	//state to OK
+	State = false
+	return "", State
+}
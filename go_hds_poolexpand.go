@@ -0,0 +1,259 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//PoolExpandCandidate type is one unused parity group that can be added to the pool
+//Capacity and EffectiveCapacity are in [MB]. FreeAfter [%] is only set if the parity group is selected.
+type PoolExpandCandidate struct {
+	ParityGroup       ParityGroupInfo
+	Capacity          float64
+	EffectiveCapacity float64
+	Selected          bool
+	FreeAfter         float64
+}
+
+//PoolExpandPlan proposes the unused parity groups to add to a pool (-poolid) until its free capacity reaches -targetfree [%]
+//only parity groups without LDEVs and with the drive type name and RAID level of the pool volumes are used. the fewest parity groups are selected and of these the smallest ones that reach the target.
+//the effective capacity is the capacity * the compression ratio total of the pool.
+//return value (bool) is true if the pool or the drive type of its pool volumes cannot be found (exit status 87). if the target cannot be reached the state is true (exit status 88). Otherwise false.
+//example: NotFound, State := PoolExpandPlan(p)
+func PoolExpandPlan(p Params) (bool, bool) {
+	Debug.Println("Function 'PoolExpandPlan' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//initial state is true that means NOK
+	State := true
+
+	var Mb2Gb float64
+	Mb2Gb = 1024.0
+
+	Info.Println("Pool expansion plan start")
+
+	var Pools []PoolInfo
+	Pools, State = PoolsListGet(p)
+	var Pool PoolInfo
+	var PoolFound bool
+	PoolFound = false
+	for _, PoolElement := range Pools {
+		if PoolElement.PoolID == strconv.Itoa(p.PoolID) {
+			Pool = PoolElement
+			PoolFound = true
+		}
+	}
+	if !PoolFound || Pool.PhysicalCapacityTotal <= 0 {
+		Error.Println("The pool " + strconv.Itoa(p.PoolID) + " does not exist or has no capacity.")
+		return true, false
+	}
+
+	var ParityGroups []ParityGroupInfo
+	ParityGroups, State = ParityGroupsListGet(p)
+
+	//drive type name and RAID level of the parity groups of the pool volumes (ex: "SSD RAID5")
+	var PoolID int
+	PoolID = p.PoolID
+	var Ldevs []LdevInfo
+	p.PoolID = -1
+	Ldevs, State = LdevsListGet(p)
+	var DriveClasses []string
+	DriveClasses = PoolDriveClassesGet(Ldevs, ParityGroups)[PoolID]
+	if len(DriveClasses) == 0 {
+		Error.Println("The drive type of the pool volumes of the pool " + Pool.PoolID + " (" + Pool.PoolName + ") cannot be found.")
+		return true, false
+	}
+	PoolDriveClasses := map[string]bool{}
+	for _, DriveClass := range DriveClasses {
+		PoolDriveClasses[DriveClass] = true
+	}
+	Verbose.Println("Drive types of the pool " + Pool.PoolID + ": " + strings.Join(DriveClasses, ", "))
+
+	var CompressionRatio float64
+	CompressionRatio = 1
+	if Pool.CompressionRatio > 0 {
+		CompressionRatio = Pool.CompressionRatio
+	}
+
+	//unused parity groups of a compatible drive type. the largest first
+	var Candidates []PoolExpandCandidate
+	for _, ParityGroup := range ParityGroups {
+		if ParityGroup.NumOfLdevs > 0 || ParityGroup.TotalCapacity <= 0 || !PoolDriveClasses[ParityGroup.DriveTypeName+" "+ParityGroup.RaidLevel] {
+			continue
+		}
+		Candidates = append(Candidates, PoolExpandCandidate{ParityGroup: ParityGroup, Capacity: ParityGroup.TotalCapacity, EffectiveCapacity: ParityGroup.TotalCapacity * CompressionRatio})
+	}
+	sort.SliceStable(Candidates, func(i, j int) bool {
+		if Candidates[i].Capacity != Candidates[j].Capacity {
+			return Candidates[i].Capacity > Candidates[j].Capacity
+		}
+		return Candidates[i].ParityGroup.ParityGroupID < Candidates[j].ParityGroup.ParityGroupID
+	})
+
+	var FreeBefore float64
+	FreeBefore = Pool.PhysicalCapacityFree / Pool.PhysicalCapacityTotal * 100
+
+	//capacity [MB] to add to reach the target: (free + added) / (total + added) >= target
+	var Target float64
+	var Needed float64
+	Target = float64(p.TargetFree) / 100
+	Needed = (Target*Pool.PhysicalCapacityTotal - Pool.PhysicalCapacityFree) / (1 - Target)
+
+	//the fewest parity groups are the largest ones. with this number the smallest parity groups that still reach the target are selected
+	var Count int
+	var Sum float64
+	Count = 0
+	Sum = 0
+	for Count < len(Candidates) && Sum < Needed {
+		Sum = Sum + Candidates[Count].Capacity
+		Count = Count + 1
+	}
+	var Added float64
+	Added = 0
+	for Picked := 0; Picked < Count; Picked++ {
+		var Pick int
+		Pick = -1
+		for i := range Candidates {
+			if Candidates[i].Selected {
+				continue
+			}
+			//the largest other parity groups for the remaining picks
+			var Rest float64
+			var RestCount int
+			Rest = 0
+			RestCount = 0
+			for j := range Candidates {
+				if j != i && !Candidates[j].Selected && RestCount < Count-Picked-1 {
+					Rest = Rest + Candidates[j].Capacity
+					RestCount = RestCount + 1
+				}
+			}
+			//the candidates are sorted from the largest, the last one that reaches the target is the smallest
+			if Pick < 0 || Added+Candidates[i].Capacity+Rest >= Needed {
+				Pick = i
+			}
+		}
+		Candidates[Pick].Selected = true
+		Added = Added + Candidates[Pick].Capacity
+	}
+
+	var EffectiveAdded float64
+	var FreeAfter float64
+	var Selected []string
+	EffectiveAdded = 0
+	FreeAfter = FreeBefore
+	var Cumulated float64
+	Cumulated = 0
+	for i := range Candidates {
+		if !Candidates[i].Selected {
+			continue
+		}
+		Cumulated = Cumulated + Candidates[i].Capacity
+		EffectiveAdded = EffectiveAdded + Candidates[i].EffectiveCapacity
+		FreeAfter = (Pool.PhysicalCapacityFree + Cumulated) / (Pool.PhysicalCapacityTotal + Cumulated) * 100
+		Candidates[i].FreeAfter = FreeAfter
+		Selected = append(Selected, Candidates[i].ParityGroup.ParityGroupID)
+	}
+
+	OutData := [][]string{}
+	for _, Candidate := range Candidates {
+		var SelectedString string
+		var FreeAfterString string
+		SelectedString = "no"
+		FreeAfterString = "-"
+		if Candidate.Selected {
+			SelectedString = "yes"
+			FreeAfterString = strconv.FormatFloat(Candidate.FreeAfter, 'f', p.RoundPrecision, 64)
+		}
+		OutData = append(OutData, []string{p.ElementStringStart})
+		OutData = append(OutData, []string{HeaderFormat("Parity group", "string", p), Candidate.ParityGroup.ParityGroupID})
+		OutData = append(OutData, []string{HeaderFormat("Drive type name", "string", p), Candidate.ParityGroup.DriveTypeName})
+		OutData = append(OutData, []string{HeaderFormat("Drive type", "string", p), Candidate.ParityGroup.DriveType})
+		OutData = append(OutData, []string{HeaderFormat("RAID", "string", p), Candidate.ParityGroup.RaidLevel + " " + Candidate.ParityGroup.RaidType})
+		OutData = append(OutData, []string{HeaderFormat("Capacity [GB]", "float64", p), strconv.FormatFloat(Candidate.Capacity/Mb2Gb, 'f', p.RoundPrecision, 64)})
+		OutData = append(OutData, []string{HeaderFormat("Effective capacity [GB]", "float64", p), strconv.FormatFloat(Candidate.EffectiveCapacity/Mb2Gb, 'f', p.RoundPrecision, 64)})
+		OutData = append(OutData, []string{HeaderFormat("Selected", "string", p), SelectedString})
+		OutData = append(OutData, []string{HeaderFormat("Pool free after [%]", "float64", p), FreeAfterString})
+		OutData = append(OutData, []string{p.ElementStringEnd})
+	}
+	//the candidates and the plan are one output
+	OutputReportsStart()
+	p.ReportName = "candidates"
+	if len(Candidates) == 0 {
+		Warning.Println("No unused parity group with the drive type " + strings.Join(DriveClasses, " or ") + " found.")
+	} else if OutputListFormat(OutData, p) {
+		Warning.Println("The function 'OutputListFormat' returned an Error.")
+	}
+
+	OutData = [][]string{}
+	OutData = append(OutData, []string{p.ElementStringStart})
+	OutData = append(OutData, []string{HeaderFormat("Pool ID", "string", p), Pool.PoolID})
+	OutData = append(OutData, []string{HeaderFormat("Pool name", "string", p), Pool.PoolName})
+	OutData = append(OutData, []string{HeaderFormat("Drive types", "string", p), strings.Join(DriveClasses, ", ")})
+	OutData = append(OutData, []string{HeaderFormat("Compression ratio total", "float64", p), strconv.FormatFloat(CompressionRatio, 'f', p.RoundPrecision, 64)})
+	OutData = append(OutData, []string{HeaderFormat("Free before [%]", "float64", p), strconv.FormatFloat(FreeBefore, 'f', p.RoundPrecision, 64)})
+	OutData = append(OutData, []string{HeaderFormat("Target free [%]", "float64", p), strconv.Itoa(p.TargetFree)})
+	OutData = append(OutData, []string{HeaderFormat("Free after [%]", "float64", p), strconv.FormatFloat(FreeAfter, 'f', p.RoundPrecision, 64)})
+	OutData = append(OutData, []string{HeaderFormat("Parity groups to add", "string", p), strings.Join(Selected, ",")})
+	OutData = append(OutData, []string{HeaderFormat("Capacity added [GB]", "float64", p), strconv.FormatFloat(Added/Mb2Gb, 'f', p.RoundPrecision, 64)})
+	OutData = append(OutData, []string{HeaderFormat("Effective capacity added [GB]", "float64", p), strconv.FormatFloat(EffectiveAdded/Mb2Gb, 'f', p.RoundPrecision, 64)})
+	OutData = append(OutData, []string{p.ElementStringEnd})
+	p.ReportName = "plan"
+	if OutputListFormat(OutData, p) {
+		Warning.Println("The function 'OutputListFormat' returned an Error.")
+	}
+	OutputReportsEnd(p)
+
+	State = false
+	if FreeAfter < float64(p.TargetFree) {
+		Warning.Println("The pool " + Pool.PoolID + " (" + Pool.PoolName + ") reaches only " + strconv.FormatFloat(FreeAfter, 'f', 0, 64) + "% free with all unused parity groups. The target is " + strconv.Itoa(p.TargetFree) + "%.")
+		State = true
+	} else if len(Selected) == 0 {
+		Info.Println("The pool " + Pool.PoolID + " (" + Pool.PoolName + ") is already " + strconv.FormatFloat(FreeBefore, 'f', 0, 64) + "% free. No parity group is needed.")
+	}
+
+	Info.Println("Pool expansion plan end")
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'PoolExpandPlan' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'PoolExpandPlan' return values State:", State)
+	Debug.Println("Function 'PoolExpandPlan' ended.")
+
+	return false, State
+}
+
+//PoolDriveClassesGet returns the drive type names and RAID levels (ex: "SSD RAID5") of the parity groups of the pool volumes per pool id. they are sorted.
+func PoolDriveClassesGet(Ldevs []LdevInfo, ParityGroups []ParityGroupInfo) map[int][]string {
+	ParityGroupClasses := map[string]string{}
+	for _, ParityGroup := range ParityGroups {
+		ParityGroupClasses[ParityGroup.ParityGroupID] = ParityGroup.DriveTypeName + " " + ParityGroup.RaidLevel
+	}
+
+	PoolClasses := map[int]map[string]bool{}
+	for _, Ldev := range Ldevs {
+		if !LdevIsPoolVolume(Ldev) {
+			continue
+		}
+		for _, ParityGroupID := range Ldev.ParityGroupIDs {
+			if DriveClass, ok := ParityGroupClasses[ParityGroupID]; ok {
+				if PoolClasses[Ldev.PoolID] == nil {
+					PoolClasses[Ldev.PoolID] = map[string]bool{}
+				}
+				PoolClasses[Ldev.PoolID][DriveClass] = true
+			}
+		}
+	}
+
+	DriveClasses := map[int][]string{}
+	for PoolID, Classes := range PoolClasses {
+		for DriveClass := range Classes {
+			DriveClasses[PoolID] = append(DriveClasses[PoolID], DriveClass)
+		}
+		sort.Strings(DriveClasses[PoolID])
+	}
+	return DriveClasses
+}
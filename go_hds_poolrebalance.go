@@ -0,0 +1,227 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//PoolRebalanceTolerance is the utilization difference [%] of the pools of a tier that is accepted
+const PoolRebalanceTolerance float64 = 5
+
+//PoolRebalanceMaxMoves is the maximum number of DP volumes moved in the recommendation
+const PoolRebalanceMaxMoves int = 100
+
+//PoolBalance type is the physical capacity of a pool before and after the recommended moves [MB]
+type PoolBalance struct {
+	Pool       PoolInfo
+	Tier       string
+	Ratio      float64
+	Total      float64
+	UsedBefore float64
+	Used       float64
+	MovesOut   int
+	MovesIn    int
+}
+
+//PoolMove type is one recommended migration of a DP volume. Physical is the physical used capacity in the source pool [MB].
+type PoolMove struct {
+	Ldev     LdevInfo
+	From     *PoolBalance
+	To       *PoolBalance
+	Physical float64
+}
+
+//PoolRebalance recommends DP volumes to migrate between the pools of the same tier (pool type, drive type names and RAID levels) to even out the physical utilization
+//the physical used capacity of a DP volume is its used capacity / the compression ratio total of the pool. the pool with the highest utilization gives its largest volume that fits to the pool with the lowest utilization until all pools of a tier are within PoolRebalanceTolerance.
+//nothing is changed.
+//return value (string) is empty. if an error happened the state is true. Otherwise false.
+//example: PoolRebalance(p)
+func PoolRebalance(p Params) (string, bool) {
+	Debug.Println("Function 'PoolRebalance' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//initial state is true that means NOK
+	State := true
+
+	var Mb2Gb float64
+	Mb2Gb = 1024.0
+
+	Info.Println("Pool rebalancing start")
+
+	var Pools []PoolInfo
+	Pools, State = PoolsListGet(p)
+
+	var ParityGroups []ParityGroupInfo
+	ParityGroups, State = ParityGroupsListGet(p)
+
+	var Ldevs []LdevInfo
+	p.PoolID = -1
+	Ldevs, State = LdevsListGet(p)
+	PoolDriveClasses := PoolDriveClassesGet(Ldevs, ParityGroups)
+
+	//the DP pools per tier
+	Tiers := map[string][]*PoolBalance{}
+	var Balances []*PoolBalance
+	for _, Pool := range Pools {
+		PoolID, _ := strconv.Atoi(Pool.PoolID)
+		if Pool.PoolType == "HTI" || Pool.PhysicalCapacityTotal <= 0 {
+			continue
+		}
+		if len(PoolDriveClasses[PoolID]) == 0 {
+			Verbose.Println("The drive type of the pool " + Pool.PoolID + " (" + Pool.PoolName + ") cannot be found. It is not rebalanced.")
+			continue
+		}
+
+		Balance := &PoolBalance{Pool: Pool, Tier: Pool.PoolType + " " + strings.Join(PoolDriveClasses[PoolID], "+"), Ratio: 1, Total: Pool.PhysicalCapacityTotal}
+		if Pool.CompressionRatio > 0 {
+			Balance.Ratio = Pool.CompressionRatio
+		}
+		Balance.UsedBefore = Pool.PhysicalCapacityTotal - Pool.PhysicalCapacityFree
+		Balance.Used = Balance.UsedBefore
+		Tiers[Balance.Tier] = append(Tiers[Balance.Tier], Balance)
+		Balances = append(Balances, Balance)
+	}
+
+	//the DP volumes per pool. the largest first
+	PoolLdevs := map[int][]LdevInfo{}
+	for _, Ldev := range Ldevs {
+		if LdevIsDpVolume(Ldev) && Ldev.UsedCapacity > 0 {
+			PoolLdevs[Ldev.PoolID] = append(PoolLdevs[Ldev.PoolID], Ldev)
+		}
+	}
+	for PoolID := range PoolLdevs {
+		PoolVolumes := PoolLdevs[PoolID]
+		sort.SliceStable(PoolVolumes, func(i, j int) bool {
+			return PoolVolumes[i].UsedCapacity > PoolVolumes[j].UsedCapacity
+		})
+	}
+
+	var TierNames []string
+	for Tier := range Tiers {
+		TierNames = append(TierNames, Tier)
+	}
+	sort.Strings(TierNames)
+
+	var Moves []PoolMove
+	Moved := map[int]bool{}
+	for _, Tier := range TierNames {
+		TierPools := Tiers[Tier]
+		if len(TierPools) < 2 {
+			Verbose.Println("The tier " + Tier + " has only one pool. It is not rebalanced.")
+			continue
+		}
+
+		//average utilization of the tier
+		var TierUsed float64
+		var TierTotal float64
+		for _, Balance := range TierPools {
+			TierUsed = TierUsed + Balance.Used
+			TierTotal = TierTotal + Balance.Total
+		}
+		var Average float64
+		Average = TierUsed / TierTotal
+
+		for len(Moves) < PoolRebalanceMaxMoves {
+			sort.SliceStable(TierPools, func(i, j int) bool {
+				return TierPools[i].Used/TierPools[i].Total > TierPools[j].Used/TierPools[j].Total
+			})
+			From := TierPools[0]
+			To := TierPools[len(TierPools)-1]
+			if (From.Used/From.Total-To.Used/To.Total)*100 <= PoolRebalanceTolerance {
+				break
+			}
+
+			//capacity the source has above and the target has below the average
+			var Excess float64
+			var Deficit float64
+			Excess = From.Used - Average*From.Total
+			Deficit = Average*To.Total - To.Used
+
+			var Found bool
+			Found = false
+			FromID, _ := strconv.Atoi(From.Pool.PoolID)
+			for _, Ldev := range PoolLdevs[FromID] {
+				if Moved[Ldev.LdevID] {
+					continue
+				}
+				var Physical float64
+				var PhysicalTo float64
+				Physical = Ldev.UsedCapacity / From.Ratio
+				PhysicalTo = Ldev.UsedCapacity / To.Ratio
+				if Physical > Excess || PhysicalTo > Deficit {
+					continue
+				}
+
+				Moves = append(Moves, PoolMove{Ldev: Ldev, From: From, To: To, Physical: Physical})
+				Moved[Ldev.LdevID] = true
+				From.Used = From.Used - Physical
+				From.MovesOut = From.MovesOut + 1
+				To.Used = To.Used + PhysicalTo
+				To.MovesIn = To.MovesIn + 1
+				Found = true
+				break
+			}
+			if !Found {
+				Verbose.Println("No DP volume of the pool " + From.Pool.PoolID + " fits into the pool " + To.Pool.PoolID + ".")
+				break
+			}
+		}
+	}
+
+	OutData := [][]string{}
+	for i, Move := range Moves {
+		OutData = append(OutData, []string{p.ElementStringStart})
+		OutData = append(OutData, []string{HeaderFormat("Move", "float64", p), strconv.Itoa(i + 1)})
+		OutData = append(OutData, []string{HeaderFormat("LDEV ID", "string", p), LdevIDFormat(Move.Ldev.LdevID)})
+		OutData = append(OutData, []string{HeaderFormat("Label", "string", p), Move.Ldev.Label})
+		OutData = append(OutData, []string{HeaderFormat("From pool", "string", p), Move.From.Pool.PoolID + " (" + Move.From.Pool.PoolName + ")"})
+		OutData = append(OutData, []string{HeaderFormat("To pool", "string", p), Move.To.Pool.PoolID + " (" + Move.To.Pool.PoolName + ")"})
+		OutData = append(OutData, []string{HeaderFormat("Capacity [GB]", "float64", p), strconv.FormatFloat(Move.Ldev.Capacity/Mb2Gb, 'f', p.RoundPrecision, 64)})
+		OutData = append(OutData, []string{HeaderFormat("Used [GB]", "float64", p), strconv.FormatFloat(Move.Ldev.UsedCapacity/Mb2Gb, 'f', p.RoundPrecision, 64)})
+		OutData = append(OutData, []string{HeaderFormat("Physical used [GB]", "float64", p), strconv.FormatFloat(Move.Physical/Mb2Gb, 'f', p.RoundPrecision, 64)})
+		OutData = append(OutData, []string{p.ElementStringEnd})
+	}
+	//the moves and the pools are one output
+	OutputReportsStart()
+	p.ReportName = "moves"
+	if len(Moves) == 0 {
+		Info.Println("No DP volume needs to be migrated. The pools of every tier are within " + strconv.FormatFloat(PoolRebalanceTolerance, 'f', 0, 64) + "% or no volume fits.")
+	} else if OutputListFormat(OutData, p) {
+		Warning.Println("The function 'OutputListFormat' returned an Error.")
+	}
+
+	//projected utilization of every pool
+	OutData = [][]string{}
+	for _, Balance := range Balances {
+		OutData = append(OutData, []string{p.ElementStringStart})
+		OutData = append(OutData, []string{HeaderFormat("Pool ID", "string", p), Balance.Pool.PoolID})
+		OutData = append(OutData, []string{HeaderFormat("Pool name", "string", p), Balance.Pool.PoolName})
+		OutData = append(OutData, []string{HeaderFormat("Tier", "string", p), Balance.Tier})
+		OutData = append(OutData, []string{HeaderFormat("Physical capacity [GB]", "float64", p), strconv.FormatFloat(Balance.Total/Mb2Gb, 'f', p.RoundPrecision, 64)})
+		OutData = append(OutData, []string{HeaderFormat("Utilization before [%]", "float64", p), strconv.FormatFloat(Balance.UsedBefore/Balance.Total*100, 'f', p.RoundPrecision, 64)})
+		OutData = append(OutData, []string{HeaderFormat("Utilization after [%]", "float64", p), strconv.FormatFloat(Balance.Used/Balance.Total*100, 'f', p.RoundPrecision, 64)})
+		OutData = append(OutData, []string{HeaderFormat("Volumes out", "float64", p), strconv.Itoa(Balance.MovesOut)})
+		OutData = append(OutData, []string{HeaderFormat("Volumes in", "float64", p), strconv.Itoa(Balance.MovesIn)})
+		OutData = append(OutData, []string{p.ElementStringEnd})
+	}
+	p.ReportName = "pools"
+	if OutputListFormat(OutData, p) {
+		Warning.Println("The function 'OutputListFormat' returned an Error.")
+	}
+	OutputReportsEnd(p)
+
+	Info.Println("Pool rebalancing end")
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'PoolRebalance' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'PoolRebalance' return values number of moves:", len(Moves))
+	Debug.Println("Function 'PoolRebalance' ended.")
+
+	//state to OK
+	State = false
+	return "", State
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"strconv"
+	"time"
+)
+
+//PoolsSummaryFormat formats the totals of all pools of a storage (one element) for the documents
+//the utilization is the used of the total physical capacity. the pools above the warning and the depletion threshold are counted.
+//example: PoolsSummaryFormat(Storage, Pools, p)
+func PoolsSummaryFormat(Storage StorageInfo, Pools []PoolInfo, p Params) [][]string {
+	Debug.Println("Function 'PoolsSummaryFormat' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	var Mb2Gb float64
+	Mb2Gb = 1024.0
+
+	var Total float64
+	var Free float64
+	var EffectiveFree float64
+	var AboveWarning int
+	var AboveDepletion int
+	for _, Pool := range Pools {
+		Total = Total + Pool.PhysicalCapacityTotal
+		Free = Free + Pool.PhysicalCapacityFree
+		//EffectiveGBFree is formatted [GB]
+		PoolEffectiveFree, _ := strconv.ParseFloat(Pool.EffectiveGBFree, 64)
+		EffectiveFree = EffectiveFree + PoolEffectiveFree
+
+		if Pool.PhysicalCapacityTotal <= 0 {
+			continue
+		}
+		var Utilization float64
+		Utilization = (Pool.PhysicalCapacityTotal - Pool.PhysicalCapacityFree) / Pool.PhysicalCapacityTotal * 100
+		//a threshold that is not set (0) is not counted
+		if Pool.DepletionThreshold > 0 && Utilization >= Pool.DepletionThreshold {
+			AboveDepletion = AboveDepletion + 1
+		} else if Pool.WarningThreshold > 0 && Utilization >= Pool.WarningThreshold {
+			AboveWarning = AboveWarning + 1
+		}
+	}
+
+	var Utilization float64
+	Utilization = 0
+	if Total > 0 {
+		Utilization = (Total - Free) / Total * 100
+	}
+
+	OutData := [][]string{}
+	OutData = append(OutData, []string{p.ElementStringStart})
+	OutData = append(OutData, []string{HeaderFormat("Storage", "string", p), StorageName(Storage)})
+	OutData = append(OutData, []string{HeaderFormat("Storage device ID", "string", p), Storage.StorageDeviceID})
+	OutData = append(OutData, []string{HeaderFormat("Pools", "float64", p), strconv.Itoa(len(Pools))})
+	OutData = append(OutData, []string{HeaderFormat("Total physical capacity [GB]", "float64", p), strconv.FormatFloat(Total/Mb2Gb, 'f', p.RoundPrecision, 64)})
+	OutData = append(OutData, []string{HeaderFormat("Used physical capacity [GB]", "float64", p), strconv.FormatFloat((Total-Free)/Mb2Gb, 'f', p.RoundPrecision, 64)})
+	OutData = append(OutData, []string{HeaderFormat("Free physical capacity [GB]", "float64", p), strconv.FormatFloat(Free/Mb2Gb, 'f', p.RoundPrecision, 64)})
+	OutData = append(OutData, []string{HeaderFormat("Effective total GB free [GB]", "float64", p), strconv.FormatFloat(EffectiveFree, 'f', p.RoundPrecision, 64)})
+	OutData = append(OutData, []string{HeaderFormat("Utilization [%]", "float64", p), strconv.FormatFloat(Utilization, 'f', p.RoundPrecision, 64)})
+	OutData = append(OutData, []string{HeaderFormat("Pools above warning threshold", "float64", p), strconv.Itoa(AboveWarning)})
+	OutData = append(OutData, []string{HeaderFormat("Pools above depletion threshold", "float64", p), strconv.Itoa(AboveDepletion)})
+	OutData = append(OutData, []string{p.ElementStringEnd})
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'PoolsSummaryFormat' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'PoolsSummaryFormat' ended.")
+	return OutData
+}
+
+//PoolTiersFormat formats the tiers of the HDT pools (one element per tier) for the documents
+//the result is empty if no pool has tiers.
+//example: PoolTiersFormat(Pools, p)
+func PoolTiersFormat(Pools []PoolInfo, p Params) [][]string {
+	var Mb2Gb float64
+	Mb2Gb = 1024.0
+
+	OutData := [][]string{}
+	for _, Pool := range Pools {
+		for _, Tier := range Pool.Tiers {
+			var Utilization float64
+			Utilization = 0
+			if Tier.TotalCapacity > 0 {
+				Utilization = Tier.UsedCapacity / Tier.TotalCapacity * 100
+			}
+			OutData = append(OutData, []string{p.ElementStringStart})
+			OutData = append(OutData, []string{HeaderFormat("Pool ID", "string", p), Pool.PoolID})
+			OutData = append(OutData, []string{HeaderFormat("Pool name", "string", p), Pool.PoolName})
+			OutData = append(OutData, []string{HeaderFormat("Tier", "float64", p), strconv.Itoa(Tier.TierNumber)})
+			OutData = append(OutData, []string{HeaderFormat("Total capacity [GB]", "float64", p), strconv.FormatFloat(Tier.TotalCapacity/Mb2Gb, 'f', p.RoundPrecision, 64)})
+			OutData = append(OutData, []string{HeaderFormat("Used capacity [GB]", "float64", p), strconv.FormatFloat(Tier.UsedCapacity/Mb2Gb, 'f', p.RoundPrecision, 64)})
+			OutData = append(OutData, []string{HeaderFormat("Utilization [%]", "float64", p), strconv.FormatFloat(Utilization, 'f', p.RoundPrecision, 64)})
+			OutData = append(OutData, []string{p.ElementStringEnd})
+		}
+	}
+	return OutData
+}
+
+//PoolsCompressionFormat formats the compression of the pools with a compression ratio (one element per pool and the total) for the documents
+//the effective used capacity is the physical used capacity * the compression ratio total. the result is empty if no pool has a compression ratio.
+//example: PoolsCompressionFormat(Pools, p)
+func PoolsCompressionFormat(Pools []PoolInfo, p Params) [][]string {
+	var Mb2Gb float64
+	Mb2Gb = 1024.0
+
+	var PhysicalTotal float64
+	var EffectiveTotal float64
+	OutData := [][]string{}
+	for _, Pool := range Pools {
+		if Pool.CompressionRatio <= 0 {
+			continue
+		}
+		var Physical float64
+		var Effective float64
+		Physical = Pool.PhysicalCapacityTotal - Pool.PhysicalCapacityFree
+		Effective = Physical * Pool.CompressionRatio
+		PhysicalTotal = PhysicalTotal + Physical
+		EffectiveTotal = EffectiveTotal + Effective
+
+		OutData = append(OutData, []string{p.ElementStringStart})
+		OutData = append(OutData, []string{HeaderFormat("Pool ID", "string", p), Pool.PoolID})
+		OutData = append(OutData, []string{HeaderFormat("Pool name", "string", p), Pool.PoolName})
+		OutData = append(OutData, []string{HeaderFormat("Compression ratio FMC", "float64", p), Pool.FMCCompressionRatio})
+		OutData = append(OutData, []string{HeaderFormat("Compression ratio total", "float64", p), Pool.CompressionRatioTotal})
+		OutData = append(OutData, []string{HeaderFormat("Physical used [GB]", "float64", p), strconv.FormatFloat(Physical/Mb2Gb, 'f', p.RoundPrecision, 64)})
+		OutData = append(OutData, []string{HeaderFormat("Effective used [GB]", "float64", p), strconv.FormatFloat(Effective/Mb2Gb, 'f', p.RoundPrecision, 64)})
+		OutData = append(OutData, []string{HeaderFormat("Saved [GB]", "float64", p), strconv.FormatFloat((Effective-Physical)/Mb2Gb, 'f', p.RoundPrecision, 64)})
+		OutData = append(OutData, []string{p.ElementStringEnd})
+	}
+	if len(OutData) == 0 || PhysicalTotal <= 0 {
+		return OutData
+	}
+
+	//all pools with a compression ratio
+	OutData = append(OutData, []string{p.ElementStringStart})
+	OutData = append(OutData, []string{HeaderFormat("Pool ID", "string", p), "total"})
+	OutData = append(OutData, []string{HeaderFormat("Pool name", "string", p), ""})
+	OutData = append(OutData, []string{HeaderFormat("Compression ratio FMC", "float64", p), ""})
+	OutData = append(OutData, []string{HeaderFormat("Compression ratio total", "float64", p), strconv.FormatFloat(EffectiveTotal/PhysicalTotal, 'f', p.RoundPrecision, 64)})
+	OutData = append(OutData, []string{HeaderFormat("Physical used [GB]", "float64", p), strconv.FormatFloat(PhysicalTotal/Mb2Gb, 'f', p.RoundPrecision, 64)})
+	OutData = append(OutData, []string{HeaderFormat("Effective used [GB]", "float64", p), strconv.FormatFloat(EffectiveTotal/Mb2Gb, 'f', p.RoundPrecision, 64)})
+	OutData = append(OutData, []string{HeaderFormat("Saved [GB]", "float64", p), strconv.FormatFloat((EffectiveTotal-PhysicalTotal)/Mb2Gb, 'f', p.RoundPrecision, 64)})
+	OutData = append(OutData, []string{p.ElementStringEnd})
+	return OutData
+}
@@ -0,0 +1,390 @@
+package main
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//ProvisionStep type is one step of the provisioning plan
+//Status is "planned" (dry-run), "done", "failed" or "skipped" (an earlier step failed). HostGroup is only set to map a LUN.
+type ProvisionStep struct {
+	Action    string
+	Target    string
+	Details   string
+	Status    string
+	HostGroup HostGroupInfo
+}
+
+//ProvisionMaxLun is the highest LUN number of a host group
+const ProvisionMaxLun int = 2047
+
+//Provision creates a DP volume in a pool (-poolid, -capacity, -label, -datareduction) and maps it to host groups (-hostgroups) with the same LUN (-lun or the lowest free one)
+//the plan and the subscription of the pool are shown first. The plan is refused if the subscription of the pool would exceed -maxsubscription.
+//without -execute nothing is changed (dry-run). With -execute the resources are locked and every job is waited for.
+//return value (bool) is true if the plan is refused (pool, host groups, LUN or subscription). Nothing is changed then. if a step failed the state is true. Otherwise false.
+//example: Refused, State := Provision(p)
+func Provision(p Params) (bool, bool) {
+	Debug.Println("Function 'Provision' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//initial state is true that means NOK
+	State := true
+
+	var Mb2Gb float64
+	Mb2Gb = 1024.0
+
+	if p.Execute {
+		Info.Println("Provision LDEV start")
+	} else {
+		Info.Println("Provision LDEV start (dry-run. use -execute to provision the LDEV)")
+	}
+
+	var Capacity float64
+	Capacity, _ = CapacityParse(p.Capacity)
+	//the storage expects the unit in upper case
+	p.Capacity = strings.ToUpper(strings.TrimSpace(p.Capacity))
+
+	//the pool of the new LDEV
+	var Pools []PoolInfo
+	Pools, State = PoolsListGet(p)
+	var Pool PoolInfo
+	var PoolFound bool
+	PoolFound = false
+	for _, PoolElement := range Pools {
+		if PoolElement.PoolID == strconv.Itoa(p.PoolID) {
+			Pool = PoolElement
+			PoolFound = true
+		}
+	}
+	if !PoolFound {
+		Error.Println("The pool " + strconv.Itoa(p.PoolID) + " does not exist.")
+		return true, false
+	}
+	if Pool.TotalPoolCapacity <= 0 {
+		Error.Println("The pool " + Pool.PoolID + " (" + Pool.PoolName + ") has no capacity.")
+		return true, false
+	}
+
+	var Targets []HostGroupInfo
+	var TargetsState bool
+	Targets, TargetsState = ProvisionTargetsGet(p)
+	if TargetsState {
+		return true, false
+	}
+
+	var Lun int
+	var LunState bool
+	Lun, LunState = ProvisionLunSelect(p, Targets)
+	if LunState {
+		return true, false
+	}
+
+	//the plan
+	var Steps []ProvisionStep
+	Steps = append(Steps, ProvisionStep{Action: "create LDEV", Target: "pool " + Pool.PoolID + " (" + Pool.PoolName + ")", Details: p.Capacity + " data reduction: " + p.DataReduction, Status: "planned"})
+	if p.Label != "" {
+		Steps = append(Steps, ProvisionStep{Action: "set label", Target: "new LDEV", Details: p.Label, Status: "planned"})
+	}
+	for _, Target := range Targets {
+		Steps = append(Steps, ProvisionStep{Action: "map LUN", Target: Target.PortID + "," + strconv.Itoa(Target.HostGroupNumber) + " (" + Target.HostGroupName + ")", Details: "LUN " + strconv.Itoa(Lun), Status: "planned", HostGroup: Target})
+	}
+	//the plan, the subscription and the result are one output
+	OutputReportsStart()
+	p.ReportName = "plan"
+	ProvisionStepsOutput(Steps, p)
+
+	//subscription of the pool before and after the new LDEV
+	var SubscriptionBefore float64
+	var SubscriptionAfter float64
+	SubscriptionBefore = Pool.TotalLocatedCapacity / Pool.TotalPoolCapacity * 100
+	SubscriptionAfter = (Pool.TotalLocatedCapacity + Capacity) / Pool.TotalPoolCapacity * 100
+
+	OutData := [][]string{}
+	OutData = append(OutData, []string{p.ElementStringStart})
+	OutData = append(OutData, []string{HeaderFormat("Pool ID", "string", p), Pool.PoolID})
+	OutData = append(OutData, []string{HeaderFormat("Pool name", "string", p), Pool.PoolName})
+	OutData = append(OutData, []string{HeaderFormat("Pool capacity [GB]", "float64", p), strconv.FormatFloat(Pool.TotalPoolCapacity/Mb2Gb, 'f', p.RoundPrecision, 64)})
+	OutData = append(OutData, []string{HeaderFormat("Subscribed before [GB]", "float64", p), strconv.FormatFloat(Pool.TotalLocatedCapacity/Mb2Gb, 'f', p.RoundPrecision, 64)})
+	OutData = append(OutData, []string{HeaderFormat("Subscribed after [GB]", "float64", p), strconv.FormatFloat((Pool.TotalLocatedCapacity+Capacity)/Mb2Gb, 'f', p.RoundPrecision, 64)})
+	OutData = append(OutData, []string{HeaderFormat("Subscription before [%]", "float64", p), strconv.FormatFloat(SubscriptionBefore, 'f', p.RoundPrecision, 64)})
+	OutData = append(OutData, []string{HeaderFormat("Subscription after [%]", "float64", p), strconv.FormatFloat(SubscriptionAfter, 'f', p.RoundPrecision, 64)})
+	OutData = append(OutData, []string{HeaderFormat("Max subscription [%]", "float64", p), strconv.Itoa(p.MaxSubscription)})
+	OutData = append(OutData, []string{p.ElementStringEnd})
+	p.ReportName = "subscription"
+	if OutputListFormat(OutData, p) {
+		Warning.Println("The function 'OutputListFormat' returned an Error.")
+	}
+
+	if SubscriptionAfter > float64(p.MaxSubscription) {
+		Error.Println("The pool " + Pool.PoolID + " (" + Pool.PoolName + ") would be subscribed " + strconv.FormatFloat(SubscriptionAfter, 'f', 0, 64) + "%. This is more than the max subscription of " + strconv.Itoa(p.MaxSubscription) + "% (-maxsubscription). No action will take place.")
+		OutputReportsEnd(p)
+		return true, false
+	}
+
+	if !p.Execute {
+		OutputReportsEnd(p)
+		Info.Println("Provision LDEV end (dry-run)")
+		return false, false
+	}
+
+	_, LockState := ResourceLock(p)
+
+	//all steps are skipped if the resources cannot be locked
+	var LdevID string
+	var Failed bool
+	Failed = LockState
+	for i := range Steps {
+		if Failed {
+			Steps[i].Status = "skipped"
+			continue
+		}
+
+		var Job JobInfo
+		var JobState bool
+		switch Steps[i].Action {
+		case "create LDEV":
+			Job, JobState = LdevCreate(p, p.PoolID, p.Capacity, p.DataReduction)
+			LdevID = JobResourceID(Job)
+			if !JobState && LdevID == "" {
+				Warning.Println("The creation job did not return the LDEV ID.")
+				JobState = true
+			}
+			//no mapping without an LDEV
+			Failed = JobState
+			if !JobState {
+				LdevNumber, _ := strconv.Atoi(LdevID)
+				Steps[i].Details = Steps[i].Details + " LDEV ID: " + LdevIDFormat(LdevNumber)
+			}
+		case "set label":
+			Job, JobState = LdevLabelSet(p, LdevID, p.Label)
+		case "map LUN":
+			Job, JobState = LunCreate(p, Steps[i].HostGroup, LdevID, Lun)
+		}
+
+		Steps[i].Status = "done"
+		if JobState {
+			Steps[i].Status = "failed"
+			Steps[i].Details = Steps[i].Details + " " + JobErrorFormat(Job)
+		}
+	}
+
+	if !LockState {
+		ResourceUnlock(p)
+	}
+
+	p.ReportName = "result"
+	ProvisionStepsOutput(Steps, p)
+	OutputReportsEnd(p)
+
+	//state to NOK if a step failed
+	State = false
+	for _, Step := range Steps {
+		if Step.Status != "done" {
+			State = true
+		}
+	}
+
+	Info.Println("Provision LDEV end")
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'Provision' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'Provision' return values LdevID:", LdevID, "State:", State)
+	Debug.Println("Function 'Provision' ended.")
+
+	return false, State
+}
+
+//ProvisionStepsOutput shows the steps of the provisioning plan
+func ProvisionStepsOutput(Steps []ProvisionStep, p Params) {
+	OutData := [][]string{}
+	for i, Step := range Steps {
+		OutData = append(OutData, []string{p.ElementStringStart})
+		OutData = append(OutData, []string{HeaderFormat("Step", "float64", p), strconv.Itoa(i + 1)})
+		OutData = append(OutData, []string{HeaderFormat("Action", "string", p), Step.Action})
+		OutData = append(OutData, []string{HeaderFormat("Target", "string", p), Step.Target})
+		OutData = append(OutData, []string{HeaderFormat("Details", "string", p), Step.Details})
+		OutData = append(OutData, []string{HeaderFormat("Status", "string", p), Step.Status})
+		OutData = append(OutData, []string{p.ElementStringEnd})
+	}
+
+	if OutputListFormat(OutData, p) {
+		Warning.Println("The function 'OutputListFormat' returned an Error.")
+	}
+}
+
+//ProvisionTargetsParse splits -hostgroups into port and host group number or name. every host group is "<port>:<host group number or name>" and they are separated by ",".
+//if a host group is not valid the state is true. Otherwise false. it is checked before the session is opened.
+//example: ProvisionTargetsParse("CL1-A:1,CL2-A:esx01")
+func ProvisionTargetsParse(HostGroups string) ([][]string, bool) {
+	var Targets [][]string
+	for _, Target := range strings.Split(HostGroups, ",") {
+		Parts := strings.SplitN(strings.TrimSpace(Target), ":", 2)
+		if len(Parts) != 2 || Parts[0] == "" || Parts[1] == "" {
+			Error.Println("The host group " + Target + " is not '<port>:<host group number or name>'.")
+			return Targets, true
+		}
+		Targets = append(Targets, Parts)
+	}
+	return Targets, false
+}
+
+//ProvisionTargetsGet returns the host groups of -hostgroups (see ProvisionTargetsParse)
+//if a host group does not exist the state is true. Otherwise false.
+//example: -hostgroups CL1-A:1,CL2-A:esx01
+func ProvisionTargetsGet(p Params) ([]HostGroupInfo, bool) {
+	Debug.Println("Function 'ProvisionTargetsGet' started.")
+
+	//initial state is true that means NOK
+	State := true
+
+	var HostGroups []HostGroupInfo
+	HostGroups, State = HostGroupsListGet(p)
+
+	var Targets []HostGroupInfo
+	var Parsed [][]string
+	Parsed, State = ProvisionTargetsParse(p.HostGroups)
+	if State {
+		return Targets, State
+	}
+	for _, Parts := range Parsed {
+		var Found bool
+		Found = false
+		for _, HostGroup := range HostGroups {
+			if HostGroup.PortID == Parts[0] && (strconv.Itoa(HostGroup.HostGroupNumber) == Parts[1] || HostGroup.HostGroupName == Parts[1]) {
+				Targets = append(Targets, HostGroup)
+				Found = true
+				break
+			}
+		}
+		if !Found {
+			Error.Println("The host group " + Parts[0] + ":" + Parts[1] + " does not exist.")
+			return Targets, true
+		}
+	}
+
+	Debug.Println("Function 'ProvisionTargetsGet' return values number of host groups:", len(Targets))
+	Debug.Println("Function 'ProvisionTargetsGet' ended.")
+
+	//state to OK
+	State = false
+	return Targets, State
+}
+
+//ProvisionLunSelect returns the LUN number used in all host groups. this is -lun or the lowest LUN number that is free in all host groups.
+//if the LUN is used or no LUN is free in all host groups the state is true. Otherwise false.
+func ProvisionLunSelect(p Params, Targets []HostGroupInfo) (int, bool) {
+	Debug.Println("Function 'ProvisionLunSelect' started.")
+
+	//initial state is true that means NOK
+	State := true
+
+	Used := map[int]string{}
+	for _, Target := range Targets {
+		var Luns []LunInfo
+		Luns, State = HostGroupLunsListGet(p, Target)
+		for _, Lun := range Luns {
+			Used[Lun.Lun] = Target.PortID + "," + strconv.Itoa(Target.HostGroupNumber) + " (" + Target.HostGroupName + ")"
+		}
+	}
+
+	if p.Lun >= 0 {
+		if HostGroup, ok := Used[p.Lun]; ok {
+			Error.Println("The LUN " + strconv.Itoa(p.Lun) + " is used in the host group " + HostGroup + ".")
+			return p.Lun, true
+		}
+		return p.Lun, false
+	}
+
+	for Lun := 0; Lun <= ProvisionMaxLun; Lun++ {
+		if _, ok := Used[Lun]; !ok {
+			Debug.Println("Function 'ProvisionLunSelect' return values Lun:", Lun)
+			Debug.Println("Function 'ProvisionLunSelect' ended.")
+			return Lun, false
+		}
+	}
+
+	Error.Println("No LUN is free in all host groups.")
+	State = true
+	return -1, State
+}
+
+//LdevCreate creates a DP volume in the pool and waits for the job. the new LDEV is the affected resource of the job.
+//DataReduction is "disabled", "compression" or "compression_deduplication"
+//example: LdevCreate(p, 1, "100G", "compression")
+func LdevCreate(p Params, PoolID int, Capacity string, DataReduction string) (JobInfo, bool) {
+	Verbose.Println("Create an LDEV of " + Capacity + " in the pool " + strconv.Itoa(PoolID))
+
+	JSONByt, _ := json.Marshal(map[string]interface{}{
+		"poolId":             PoolID,
+		"byteFormatCapacity": Capacity,
+		"dataReductionMode":  DataReduction,
+	})
+
+	p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + "/ConfigurationManager/v1/objects/storages/" + p.StorageDeviceID + "/ldevs"
+	p.RequestType = "POST"
+	p.RequestBody = string(JSONByt)
+
+	return JobRequest(p)
+}
+
+//LdevLabelSet sets the label of an LDEV and waits for the job
+//example: LdevLabelSet(p, "1024", "esx01_data")
+func LdevLabelSet(p Params, LdevID string, Label string) (JobInfo, bool) {
+	Verbose.Println("Set the label " + Label + " of the LDEV " + LdevID)
+
+	JSONByt, _ := json.Marshal(map[string]interface{}{
+		"label": Label,
+	})
+
+	p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + "/ConfigurationManager/v1/objects/storages/" + p.StorageDeviceID + "/ldevs/" + LdevID
+	p.RequestType = "PATCH"
+	p.RequestBody = string(JSONByt)
+
+	return JobRequest(p)
+}
+
+//LunCreate maps an LDEV to a host group with the LUN number and waits for the job
+//example: LunCreate(p, HostGroup, "1024", 12)
+func LunCreate(p Params, HostGroup HostGroupInfo, LdevID string, Lun int) (JobInfo, bool) {
+	Verbose.Println("Map the LDEV " + LdevID + " to " + HostGroup.PortID + "," + strconv.Itoa(HostGroup.HostGroupNumber) + " LUN " + strconv.Itoa(Lun))
+
+	LdevNumber, _ := strconv.Atoi(LdevID)
+	JSONByt, _ := json.Marshal(map[string]interface{}{
+		"portId":          HostGroup.PortID,
+		"hostGroupNumber": HostGroup.HostGroupNumber,
+		"ldevId":          LdevNumber,
+		"lun":             Lun,
+	})
+
+	p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + "/ConfigurationManager/v1/objects/storages/" + p.StorageDeviceID + "/luns"
+	p.RequestType = "POST"
+	p.RequestBody = string(JSONByt)
+
+	return JobRequest(p)
+}
+
+//CapacityParse returns the capacity [MB] of a capacity with the unit M, G or T (ex: "100G"). if it is not valid the state is true. Otherwise false.
+func CapacityParse(Capacity string) (float64, bool) {
+	Parts := regexp.MustCompile(`^([0-9]+(\.[0-9]+)?)([MGT])$`).FindStringSubmatch(strings.ToUpper(strings.TrimSpace(Capacity)))
+	if Parts == nil {
+		return 0, true
+	}
+
+	Value, err := strconv.ParseFloat(Parts[1], 64)
+	if err != nil || Value <= 0 {
+		return 0, true
+	}
+
+	switch Parts[3] {
+	case "G":
+		Value = Value * 1024
+	case "T":
+		Value = Value * 1024 * 1024
+	}
+	return Value, false
+}
@@ -0,0 +1,446 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+//RemoteCopyPairInfo type is used for all remote copy pair (TrueCopy / Universal Replicator / GAD) actions
+//journal ids and the quorum disk id are -1 if not set. CopyProgressRate is -1 if not available.
+type RemoteCopyPairInfo struct {
+	ReplicationType    string
+	CopyGroupName      string
+	CopyPairName       string
+	PvolLdevID         int
+	PvolStatus         string
+	SvolLdevID         int
+	SvolStatus         string
+	RemoteSerialNumber string
+	FenceLevel         string
+	CopyProgressRate   float64
+	PvolJournalID      int
+	SvolJournalID      int
+	QuorumDiskID       int
+}
+
+//JournalInfo type is used for all journal actions
+type JournalInfo struct {
+	JournalID     int
+	JournalStatus string
+	MuNumbers     []int
+	NumOfLdevs    int
+	UsageRate     float64
+	QCount        float64
+}
+
+//QuorumDiskInfo type is used for all GAD quorum disk actions. LdevID is -1 for quorum disks without LDEV.
+type QuorumDiskInfo struct {
+	QuorumDiskID       int
+	RemoteSerialNumber string
+	LdevID             int
+	Status             string
+}
+
+//RemoteCopyPairsListGet gets all TrueCopy, Universal Replicator and GAD pairs (remote-mirror-copypairs)
+//return value ([]RemoteCopyPairInfo) are all pairs. if an error happened the state is true. Otherwise false.
+//The function stops with exit status 83 ("JSON parsing error (Return Format is not correct).")
+//example: RemoteCopyPairsListGet(p)
+func RemoteCopyPairsListGet(p Params) ([]RemoteCopyPairInfo, bool) {
+	Debug.Println("Function 'RemoteCopyPairsListGet' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//initial state is true that means NOK
+	State := true
+
+	var ConfigurationManagerString string
+	ConfigurationManagerString = "/ConfigurationManager/v1/objects/storages/"
+
+	var Pairs []RemoteCopyPairInfo
+
+	/*
+	   https://10.70.5.104/ConfigurationManager/v1/objects/storages/834000470018/remote-mirror-copypairs
+	   {
+	       "data": [{
+	           "remoteMirrorCopyPairId": "410012,UR_GROUP,UR_GROUPP,UR_GROUPS,UR_PAIR01",
+	           "copyGroupName": "UR_GROUP",
+	           "copyPairName": "UR_PAIR01",
+	           "replicationType": "UR",
+	           "remoteSerialNumber": "410012",
+	           "pvolLdevId": 2816,
+	           "pvolStatus": "PAIR",
+	           "pvolJournalId": 0,
+	           "svolLdevId": 2816,
+	           "svolStatus": "PAIR",
+	           "svolJournalId": 0,
+	           "fenceLevel": "ASYNC",
+	           "copyProgressRate": 100
+	       }, {
+	*/
+
+	Verbose.Println("Get all remote copy pairs")
+	p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + ConfigurationManagerString + p.StorageDeviceID + "/remote-mirror-copypairs"
+	p.RequestType = "GET"
+
+	var Data []interface{}
+	Data, State = RestDataGet(p, 83)
+	for _, Value1 := range Data {
+		ParsedMap := Value1.(map[string]interface{})
+
+		PairElement := RemoteCopyPairInfo{
+			ReplicationType:    ElementString(ParsedMap, "replicationType"),
+			CopyGroupName:      ElementString(ParsedMap, "copyGroupName"),
+			CopyPairName:       ElementString(ParsedMap, "copyPairName"),
+			PvolLdevID:         int(ElementFloat64(ParsedMap, "pvolLdevId")),
+			PvolStatus:         ElementString(ParsedMap, "pvolStatus"),
+			SvolLdevID:         int(ElementFloat64(ParsedMap, "svolLdevId")),
+			SvolStatus:         ElementString(ParsedMap, "svolStatus"),
+			RemoteSerialNumber: ElementString(ParsedMap, "remoteSerialNumber"),
+			FenceLevel:         ElementString(ParsedMap, "fenceLevel"),
+			CopyProgressRate:   -1,
+			PvolJournalID:      -1,
+			SvolJournalID:      -1,
+			QuorumDiskID:       -1,
+		}
+		if ParsedMap["copyProgressRate"] != nil {
+			PairElement.CopyProgressRate = ElementFloat64(ParsedMap, "copyProgressRate")
+		}
+		if ParsedMap["pvolJournalId"] != nil {
+			PairElement.PvolJournalID = int(ElementFloat64(ParsedMap, "pvolJournalId"))
+		}
+		if ParsedMap["svolJournalId"] != nil {
+			PairElement.SvolJournalID = int(ElementFloat64(ParsedMap, "svolJournalId"))
+		}
+		if ParsedMap["quorumDiskId"] != nil {
+			PairElement.QuorumDiskID = int(ElementFloat64(ParsedMap, "quorumDiskId"))
+		}
+		Pairs = append(Pairs, PairElement)
+	}
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'RemoteCopyPairsListGet' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'RemoteCopyPairsListGet' return values number of pairs:", len(Pairs))
+	Debug.Println("Function 'RemoteCopyPairsListGet' ended.")
+
+	//state to OK
+	State = false
+	return Pairs, State
+}
+
+//JournalsListGet gets all journals
+//return value ([]JournalInfo) are all journals. if an error happened the state is true. Otherwise false.
+//The function stops with exit status 83 ("JSON parsing error (Return Format is not correct).")
+//example: JournalsListGet(p)
+func JournalsListGet(p Params) ([]JournalInfo, bool) {
+	Debug.Println("Function 'JournalsListGet' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//initial state is true that means NOK
+	State := true
+
+	var ConfigurationManagerString string
+	ConfigurationManagerString = "/ConfigurationManager/v1/objects/storages/"
+
+	var Journals []JournalInfo
+
+	/*
+	   https://10.70.5.104/ConfigurationManager/v1/objects/storages/834000470018/journals?journalInfo=basic
+	   {
+	       "data": [{
+	           "journalId": 0,
+	           "muNumber": 0,
+	           "consistencyGroupId": 0,
+	           "journalStatus": "PJNN",
+	           "numOfActivePaths": 2,
+	           "usageRate": 12,
+	           "qMarker": "00000001",
+	           "qCount": 0,
+	           "byteFormatCapacity": "1.88 G",
+	           "blockCapacity": 3956736,
+	           "numOfLdevs": 1,
+	           "firstLdevId": 2900
+	       }, {
+	*/
+
+	Verbose.Println("Get all journals")
+	p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + ConfigurationManagerString + p.StorageDeviceID + "/journals?journalInfo=basic"
+	p.RequestType = "GET"
+
+	//a journal is returned once per mirror unit
+	JournalIndex := map[int]int{}
+
+	var Data []interface{}
+	Data, State = RestDataGet(p, 83)
+	for _, Value1 := range Data {
+		ParsedMap := Value1.(map[string]interface{})
+
+		JournalID := int(ElementFloat64(ParsedMap, "journalId"))
+		MuNumber := int(ElementFloat64(ParsedMap, "muNumber"))
+		if Index, ok := JournalIndex[JournalID]; ok {
+			Journals[Index].MuNumbers = append(Journals[Index].MuNumbers, MuNumber)
+			continue
+		}
+
+		JournalElement := JournalInfo{
+			JournalID:     JournalID,
+			JournalStatus: ElementString(ParsedMap, "journalStatus"),
+			MuNumbers:     []int{MuNumber},
+			NumOfLdevs:    int(ElementFloat64(ParsedMap, "numOfLdevs")),
+			UsageRate:     ElementFloat64(ParsedMap, "usageRate"),
+			QCount:        ElementFloat64(ParsedMap, "qCount"),
+		}
+		JournalIndex[JournalID] = len(Journals)
+		Journals = append(Journals, JournalElement)
+	}
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'JournalsListGet' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'JournalsListGet' return values number of journals:", len(Journals))
+	Debug.Println("Function 'JournalsListGet' ended.")
+
+	//state to OK
+	State = false
+	return Journals, State
+}
+
+//QuorumDisksListGet gets all GAD quorum disks
+//return value ([]QuorumDiskInfo) are all quorum disks. if an error happened the state is true. Otherwise false.
+//The function stops with exit status 83 ("JSON parsing error (Return Format is not correct).")
+//example: QuorumDisksListGet(p)
+func QuorumDisksListGet(p Params) ([]QuorumDiskInfo, bool) {
+	Debug.Println("Function 'QuorumDisksListGet' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//initial state is true that means NOK
+	State := true
+
+	var ConfigurationManagerString string
+	ConfigurationManagerString = "/ConfigurationManager/v1/objects/storages/"
+
+	var QuorumDisks []QuorumDiskInfo
+
+	/*
+	   https://10.70.5.104/ConfigurationManager/v1/objects/storages/834000470018/quorum-disks
+	   {
+	       "data": [{
+	           "quorumDiskId": 0,
+	           "remoteSerialNumber": "410012",
+	           "remoteStorageTypeId": "R8",
+	           "ldevId": 3000,
+	           "status": "NORMAL",
+	           "readResponseGuaranteedTime": 40
+	       }, {
+	*/
+
+	Verbose.Println("Get all quorum disks")
+	p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + ConfigurationManagerString + p.StorageDeviceID + "/quorum-disks"
+	p.RequestType = "GET"
+
+	var Data []interface{}
+	Data, State = RestDataGet(p, 83)
+	for _, Value1 := range Data {
+		ParsedMap := Value1.(map[string]interface{})
+
+		QuorumDiskElement := QuorumDiskInfo{
+			QuorumDiskID:       int(ElementFloat64(ParsedMap, "quorumDiskId")),
+			RemoteSerialNumber: ElementString(ParsedMap, "remoteSerialNumber"),
+			LdevID:             -1,
+			Status:             ElementString(ParsedMap, "status"),
+		}
+		if ParsedMap["ldevId"] != nil {
+			QuorumDiskElement.LdevID = int(ElementFloat64(ParsedMap, "ldevId"))
+		}
+		QuorumDisks = append(QuorumDisks, QuorumDiskElement)
+	}
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'QuorumDisksListGet' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'QuorumDisksListGet' return values number of quorum disks:", len(QuorumDisks))
+	Debug.Println("Function 'QuorumDisksListGet' ended.")
+
+	//state to OK
+	State = false
+	return QuorumDisks, State
+}
+
+//RemoteReplicationGet shows all TrueCopy, Universal Replicator and GAD pairs, the journals and the GAD quorum disks
+//suspended pairs, suspended journals, journals used more than -journalthreshold and quorum disks not in the status NORMAL are findings
+//return value (int) is the number of findings. if an error happened the state is true. Otherwise false.
+//example: RemoteReplicationGet(p)
+func RemoteReplicationGet(p Params) (int, bool) {
+	Debug.Println("Function 'RemoteReplicationGet' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//initial state is true that means NOK
+	State := true
+
+	Info.Println("Get remote replication information start")
+
+	var Findings int
+	Findings = 0
+
+	//pairs
+	var Pairs []RemoteCopyPairInfo
+	Pairs, State = RemoteCopyPairsListGet(p)
+
+	//the pairs, the journals and the quorum disks are one output
+	OutputReportsStart()
+
+	//add empty string of strings to collect all pair data to output
+	OutData := [][]string{}
+	for _, Pair := range Pairs {
+		if CopyPairSuspended(Pair.ReplicationType, Pair.PvolStatus) || CopyPairSuspended(Pair.ReplicationType, Pair.SvolStatus) {
+			Warning.Println(Pair.ReplicationType + " pair: " + Pair.CopyGroupName + "/" + Pair.CopyPairName + " P-VOL: " + LdevIDFormat(Pair.PvolLdevID) + " (" + Pair.PvolStatus + ") S-VOL: " + LdevIDFormat(Pair.SvolLdevID) + " (" + Pair.SvolStatus + ") is suspended.")
+			Findings = Findings + 1
+		}
+
+		OutData = append(OutData, []string{p.ElementStringStart})
+		OutData = append(OutData, []string{HeaderFormat("Type", "string", p), Pair.ReplicationType})
+		OutData = append(OutData, []string{HeaderFormat("Copy group", "string", p), Pair.CopyGroupName})
+		OutData = append(OutData, []string{HeaderFormat("Copy pair", "string", p), Pair.CopyPairName})
+		OutData = append(OutData, []string{HeaderFormat("P-VOL", "string", p), LdevIDFormat(Pair.PvolLdevID)})
+		OutData = append(OutData, []string{HeaderFormat("P-VOL status", "string", p), Pair.PvolStatus})
+		OutData = append(OutData, []string{HeaderFormat("Remote serial", "string", p), Pair.RemoteSerialNumber})
+		OutData = append(OutData, []string{HeaderFormat("S-VOL", "string", p), LdevIDFormat(Pair.SvolLdevID)})
+		OutData = append(OutData, []string{HeaderFormat("S-VOL status", "string", p), Pair.SvolStatus})
+		OutData = append(OutData, []string{HeaderFormat("Fence level", "string", p), RemoteValueFormat(Pair.FenceLevel)})
+		OutData = append(OutData, []string{HeaderFormat("Progress [%]", "float64", p), RemoteNumberFormat(Pair.CopyProgressRate)})
+		OutData = append(OutData, []string{HeaderFormat("P-VOL journal", "string", p), RemoteIDFormat(Pair.PvolJournalID)})
+		OutData = append(OutData, []string{HeaderFormat("S-VOL journal", "string", p), RemoteIDFormat(Pair.SvolJournalID)})
+		OutData = append(OutData, []string{HeaderFormat("Quorum disk", "string", p), RemoteIDFormat(Pair.QuorumDiskID)})
+		OutData = append(OutData, []string{p.ElementStringEnd})
+	}
+
+	if len(Pairs) == 0 {
+		Info.Println("No TrueCopy, Universal Replicator or GAD pair found.")
+	} else {
+		p.ReportName = "pairs"
+		if OutputListFormat(OutData, p) {
+			Warning.Println("The function 'OutputListFormat' returned an Error.")
+		}
+	}
+
+	//journals
+	var Journals []JournalInfo
+	Journals, State = JournalsListGet(p)
+
+	OutData = [][]string{}
+	for _, Journal := range Journals {
+		if JournalSuspended(Journal.JournalStatus) {
+			Warning.Println("Journal: " + strconv.Itoa(Journal.JournalID) + " is suspended (status: " + Journal.JournalStatus + ").")
+			Findings = Findings + 1
+		}
+		if Journal.UsageRate > float64(p.JournalThreshold) {
+			Warning.Println("Journal: " + strconv.Itoa(Journal.JournalID) + " is used " + strconv.FormatFloat(Journal.UsageRate, 'f', 0, 64) + "% (threshold: " + strconv.Itoa(p.JournalThreshold) + "%).")
+			Findings = Findings + 1
+		}
+
+		var MuNumbers []string
+		for _, MuNumber := range Journal.MuNumbers {
+			MuNumbers = append(MuNumbers, strconv.Itoa(MuNumber))
+		}
+
+		OutData = append(OutData, []string{p.ElementStringStart})
+		OutData = append(OutData, []string{HeaderFormat("Journal ID", "string", p), strconv.Itoa(Journal.JournalID)})
+		OutData = append(OutData, []string{HeaderFormat("Status", "string", p), Journal.JournalStatus})
+		OutData = append(OutData, []string{HeaderFormat("MU", "string", p), strings.Join(MuNumbers, " ")})
+		OutData = append(OutData, []string{HeaderFormat("LDEVs", "float64", p), strconv.Itoa(Journal.NumOfLdevs)})
+		OutData = append(OutData, []string{HeaderFormat("Usage rate [%]", "float64", p), strconv.FormatFloat(Journal.UsageRate, 'f', 0, 64)})
+		OutData = append(OutData, []string{HeaderFormat("Q count", "float64", p), strconv.FormatFloat(Journal.QCount, 'f', 0, 64)})
+		OutData = append(OutData, []string{p.ElementStringEnd})
+	}
+
+	if len(Journals) > 0 {
+		p.ReportName = "journals"
+		if OutputListFormat(OutData, p) {
+			Warning.Println("The function 'OutputListFormat' returned an Error.")
+		}
+	}
+
+	//quorum disks
+	var QuorumDisks []QuorumDiskInfo
+	QuorumDisks, State = QuorumDisksListGet(p)
+
+	OutData = [][]string{}
+	for _, QuorumDisk := range QuorumDisks {
+		if QuorumDisk.Status != "NORMAL" {
+			Warning.Println("Quorum disk: " + strconv.Itoa(QuorumDisk.QuorumDiskID) + " is not normal (status: " + QuorumDisk.Status + ").")
+			Findings = Findings + 1
+		}
+
+		var LdevID string
+		LdevID = "-"
+		if QuorumDisk.LdevID >= 0 {
+			LdevID = LdevIDFormat(QuorumDisk.LdevID)
+		}
+
+		OutData = append(OutData, []string{p.ElementStringStart})
+		OutData = append(OutData, []string{HeaderFormat("Quorum disk ID", "string", p), strconv.Itoa(QuorumDisk.QuorumDiskID)})
+		OutData = append(OutData, []string{HeaderFormat("Remote serial", "string", p), QuorumDisk.RemoteSerialNumber})
+		OutData = append(OutData, []string{HeaderFormat("LDEV ID", "string", p), LdevID})
+		OutData = append(OutData, []string{HeaderFormat("Status", "string", p), QuorumDisk.Status})
+		OutData = append(OutData, []string{p.ElementStringEnd})
+	}
+
+	if len(QuorumDisks) > 0 {
+		p.ReportName = "quorumDisks"
+		if OutputListFormat(OutData, p) {
+			Warning.Println("The function 'OutputListFormat' returned an Error.")
+		}
+	}
+	OutputReportsEnd(p)
+
+	Info.Println("Remote replication findings: " + strconv.Itoa(Findings))
+	Info.Println("Get remote replication information end")
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'RemoteReplicationGet' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'RemoteReplicationGet' return values Findings:", Findings)
+	Debug.Println("Function 'RemoteReplicationGet' end")
+
+	//state to OK
+	State = false
+	return Findings, State
+}
+
+//JournalSuspended checks if a journal status is a suspended state
+//"PJSN"/"SJSN" -> suspended, "PJSF"/"SJSF" -> suspended as the journal is full, "PJSE"/"SJSE" -> suspended by error
+func JournalSuspended(Status string) bool {
+	switch Status {
+	case "PJSN", "SJSN", "PJSF", "SJSF", "PJSE", "SJSE":
+		return true
+	}
+	return false
+}
+
+//RemoteIDFormat returns "-" for ids that are not set (-1)
+func RemoteIDFormat(ID int) string {
+	if ID < 0 {
+		return "-"
+	}
+	return strconv.Itoa(ID)
+}
+
+//RemoteNumberFormat returns "-" for numbers that are not available (-1)
+func RemoteNumberFormat(Number float64) string {
+	if Number < 0 {
+		return "-"
+	}
+	return strconv.FormatFloat(Number, 'f', 0, 64)
+}
+
+//RemoteValueFormat returns "-" for values that are not set
+func RemoteValueFormat(Value string) string {
+	if Value == "" {
+		return "-"
+	}
+	return Value
+}
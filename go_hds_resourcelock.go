@@ -0,0 +1,68 @@
+package main
+
+import (
+	"time"
+)
+
+//ResourceUnlockFailed is true if the resources could not be unlocked. SessionClose deletes the session then (also with -tokencache) to release the lock.
+var ResourceUnlockFailed bool
+
+//ResourceLock locks the resource groups of the storage for the session. Other sessions cannot change them until ResourceUnlock.
+//The lock waits up to 30 seconds for locks of other sessions.
+//if the resources cannot be locked the state is true. Otherwise false. The caller stops without changes and the session is closed.
+//example: ResourceLock(p)
+func ResourceLock(p Params) (string, bool) {
+	Debug.Println("Function 'ResourceLock' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//initial state is true that means NOK
+	State := true
+
+	Verbose.Println("Lock the resources")
+
+	p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + "/ConfigurationManager/v1/objects/storages/" + p.StorageDeviceID + "/services/resource-group-service/actions/lock/invoke"
+	p.RequestType = "POST"
+	p.RequestBody = `{"parameters": {"waitTime": 30}}`
+
+	if _, State = JobRequest(p); State {
+		Error.Println("The resources cannot be locked. No changes will take place.")
+	}
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'ResourceLock' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'ResourceLock' ended.")
+
+	return "", State
+}
+
+//ResourceUnlock unlocks the resource groups locked by ResourceLock
+//if an error happened the state is true. Otherwise false.
+//example: ResourceUnlock(p)
+func ResourceUnlock(p Params) (string, bool) {
+	Debug.Println("Function 'ResourceUnlock' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//initial state is true that means NOK
+	State := true
+
+	Verbose.Println("Unlock the resources")
+
+	p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + "/ConfigurationManager/v1/objects/storages/" + p.StorageDeviceID + "/services/resource-group-service/actions/unlock/invoke"
+	p.RequestType = "POST"
+	p.RequestBody = ""
+
+	if _, State = JobRequest(p); State {
+		Warning.Println("The resources cannot be unlocked. They are unlocked when the session is deleted at the end of this run. The session is not kept in the token cache.")
+		ResourceUnlockFailed = true
+	}
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'ResourceUnlock' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'ResourceUnlock' ended.")
+
+	return "", State
+}
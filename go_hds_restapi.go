@@ -74,6 +74,34 @@
 #								 Change: Ordering of the output changed to show the 'Compression ratio FMC' closer to the physical values.
 #								         And the 'Compression ratio total' closer to the Effective total GB free. (roman siegenthaler)
 #								 Change: Name changed from 'Effective GB free [GB]' to 'Effective total GB free [GB]' (roman siegenthaler)
+#   2026-10-18 - v01.0.17      - drive report added (-type drive) with spare coverage and drive status audit
+#   2026-10-18 - v01.0.18      - ldev report added (-type ldev) with -poolid, -label and -attribute filters. LDEVs are read page by page (headLdevId)
+#								 BUG: MaxElementCount was 16348 instead of 16384
+#   2026-10-18 - v01.0.19      - orphan report added (-type orphan). DP volumes without LUN path sorted by reclaimable capacity
+#								 Change: the HostGroup/LUN walk of the reserve report moved to LunsListGet
+#   2026-10-18 - v01.0.20      - chargeback report added (-type chargeback) per host group, host group name pattern (-groupby) or tag (-tagfile)
+#								 Change: the pool calculation moved to PoolsListGet. PoolsGet only creates the output
+#   2026-10-18 - v01.0.21      - pool consumer report added (-type pool-consumers -poolid <id>). Growth since the last snapshot stored in -snapshotdir
+#   2026-10-18 - v01.0.22      - local replication report added (-type local-replication). ShadowImage and Thin Image pairs and the snapshot capacity per pool
+#   2026-10-18 - v01.0.23      - remote replication report added (-type remote-replication). TC/UR/GAD pairs, journals and quorum disks. json output added (-output json)
+#   2026-10-18 - v01.0.24      - thin image snapshot prune added (-type snapshot-prune -retentionfile <file> [-execute]). dry-run by default
+#   2026-10-18 - v01.0.25      - asynchronous jobs are waited for with timeout (-jobtimeout) and error details. PATCH/PUT requests added. only top level error messages stop a request
+#   2026-10-18 - v01.0.26      - non interactive hcs storage registration added (-type hcs-register/hcs-unregister/hcs-list -svpip -serial -model or -storagefile)
+#   2026-10-18 - v01.0.27      - session inventory added (-type sessions). orphaned sessions of this tool are deleted with -execute [-force]
+#   2026-10-18 - v01.0.28      - encrypted token cache added (-tokencache <file>). valid sessions are reused across runs
+#   2026-10-18 - v01.0.29      - session alive time and authentication timeout added (-alivetime, -authtimeout). background keep-alive and a new session if it expired during the run
+#   2026-10-18 - v01.0.30      - LDEV provisioning added (-type provision). plan, dry-run and max pool subscription check
+#   2026-10-18 - v01.0.31      - bulk LUN mapping from a map file added (-type lun-map -mapfile <file>). validation, diff and resume (-progressfile)
+#   2026-10-18 - v01.0.32      - host groups and WWNs from a host file added (-type host-create -hostfile <yaml>). missing host groups and WWNs are created
+#   2026-10-18 - v01.0.33      - LDEV expansion and deletion added (-type ldev-expand / ldev-delete -ldevid). pool capacity and in-use checks, confirmation (-yes)
+#   2026-10-18 - v01.0.34      - pool expansion planner added (-type pool-expand-plan -poolid -targetfree). unused parity groups of the pool drive type
+#   2026-10-18 - v01.0.35      - pool rebalancing recommendations added (-type pool-rebalance). DP volumes to migrate between pools of the same tier
+#   2026-10-18 - v01.0.36      - snapshot of pools, host groups and LUN paths (-type snapshot) and diff of two snapshots (-type snapshot-diff -snapshotfrom -snapshotto)
+#   2026-10-18 - v01.0.37      - drift detection of host groups, WWNs and LUN paths against a YAML state file (-type drift -statefile). exits with 89 on drift
+#   2026-10-18 - v01.0.38      - xlsx workbook output added (-output xlsx -outputfile). one sheet per report, pool summary and tiers, utilization highlighted by threshold
+#   2026-10-18 - v01.0.39      - static html report added (-output html -outputfile). utilization bars, compression summary, HDT tiers and svg trends of the snapshots (-snapshotdir)
+#   2026-10-18 - v01.0.40      - markdown output added (-output markdown). GitHub flavoured tables per report with a header block (storage, REST API version, collection time)
+#   2026-10-18 - v01.0.41      - InfluxDB line protocol of the pool metrics added (-output influx). written to InfluxDB v2 with retry if -influxurl is set
 #
 */
 
@@ -91,6 +119,7 @@ import (
 	"math"
 	"net/http"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -143,6 +172,8 @@ type Params struct {
 
 	OutputStyle        string
 	OutputType         string
+	OutputFile         string
+	ReportName         string
 	ElementStringStart string
 	ElementStringEnd   string
 	RoundPrecision     int
@@ -151,6 +182,84 @@ type Params struct {
 	APIVersionElement  string
 	DataElement        string
 	CSVString          string
+
+	//filters
+	PoolID          int
+	LabelFilter     *regexp.Regexp
+	AttributeFilter string
+
+	//chargeback
+	GroupBy string
+	TagFile string
+
+	//directory of the stored snapshots
+	SnapshotDir string
+
+	//journal usage rate [%] above which the remote replication check fails
+	JournalThreshold int
+
+	//snapshot prune
+	RetentionFile string
+
+	//changes are only executed if set. otherwise dry-run
+	Execute bool
+
+	//seconds to wait for an asynchronous job
+	JobTimeout int
+
+	//hcs storage registration
+	SvpIP       string
+	Serial      int
+	Model       string
+	StorageFile string
+
+	//orphaned sessions
+	SessionIdle int
+	Force       bool
+
+	//encrypted file to keep the session between runs
+	TokenCache string
+
+	//session timeouts [s]. 0 is the default of the storage
+	AliveTime   int
+	AuthTimeout int
+
+	//ldev expansion and deletion. the change is not confirmed interactively if Yes is set
+	LdevID string
+	Yes    bool
+
+	//free capacity [%] a pool should reach with the expansion plan
+	TargetFree int
+
+	//snapshot files to compare
+	SnapshotFrom string
+	SnapshotTo   string
+
+	//YAML file with the desired host groups, WWNs and LUN paths
+	StateFile string
+
+	//InfluxDB v2 the line protocol is written to. the line protocol is output to the command line if InfluxURL is empty
+	InfluxURL    string
+	InfluxOrg    string
+	InfluxBucket string
+	InfluxToken  string
+	//the certificate of the InfluxDB is not verified (-influxinsecure)
+	InfluxInsecure bool
+
+	//ldev provisioning. Label is the label of the new LDEV (-label)
+	Label           string
+	Capacity        string
+	DataReduction   string
+	HostGroups      string
+	Lun             int
+	MaxSubscription int
+
+	//bulk lun mapping
+	MapFile      string
+	ProgressFile string
+
+	//host definition (yaml) to create host groups and wwns
+	HostFile string
 }
 
 //PoolInfo type is used for all Pool Element actions
@@ -170,6 +279,29 @@ type PoolInfo struct {
 	PhysFMCPoolVolCapUsed           string
 	EffectiveGBFree                 string
 	CompressionRatioTotal           string
+
+	//values used for calculations [MB] [%]. CompressionRatio is -1 if not available
+	PoolStatus            string
+	FMC                   bool
+	TotalPoolCapacity     float64
+	PhysicalCapacityTotal float64
+	PhysicalCapacityFree  float64
+	TotalLocatedCapacity  float64
+	UsedCapacityRate      float64
+	WarningThreshold      float64
+	DepletionThreshold    float64
+	CompressionRatio      float64
+	SnapshotUsedCapacity  float64
+
+	//tiers of HDT pools
+	Tiers []PoolTierInfo
+}
+
+//PoolTierInfo type is one tier of a HDT pool [MB]
+type PoolTierInfo struct {
+	TierNumber    int
+	TotalCapacity float64
+	UsedCapacity  float64
 }
 
 //Init is used to initialize the logging
@@ -205,11 +337,16 @@ func main() {
 
 	//defaults
 	//Version of the script
-	const Version string = "01.00.16"
+	const Version string = "01.00.41"
 
 	//output styles
 	const OutputTypeStdout string = "stdout"
 	const OutputTypeCsv string = "csv"
+	const OutputTypeJSON string = "json"
+	const OutputTypeXLSX string = "xlsx"
+	const OutputTypeHTML string = "html"
+	const OutputTypeMarkdown string = "markdown"
+	const OutputTypeInflux string = "influx"
 	// Minimum Version to be able to run the script
 	const VersionMinimum string = "1.5.0"
 
@@ -228,8 +365,47 @@ func main() {
 	PortPtr := flag.String("port", "443", "Port to be used to contact the host. The storage RestAPI uses 443 (https). The HCS Rest API uses 23451. (Optional)")
 	UserPtr := flag.String("user", "", "User you want to use to contact. (Required)")
 	PasswordPtr := flag.String("password", "", "Password you want to use to contact. (Required)")
-	OutputPtr := flag.String("output", "stdout", "Specify the way you want to send the output to. Options are 'stdout' or 'csv'. 'stdout' sends the output to the command line. 'csv' create a file containing comma separated data. (Optional)")
-	TypePtr := flag.String("type", "pool", "Sets the type of output you want. 'pool' get all pool data. 'reserve' gets you all LUNs/LDEVs that have a reserve. (Optional)")
+	OutputPtr := flag.String("output", "stdout", "Specify the way you want to send the output to. Options are 'stdout', 'csv', 'json', 'xlsx', 'html', 'markdown' or 'influx'. 'stdout' sends the output to the command line. 'csv' create a file containing comma separated data. 'json' outputs every table as JSON array. Types with several reports (ex: 'remote-replication' with pairs, journals and quorumDisks) output one JSON object with the report as key and start every csv block with the line '# <report>'. 'xlsx' writes a workbook (-outputfile). 'html' writes a static html report (-outputfile). 'markdown' outputs every report as markdown table. 'influx' outputs the pool metrics as InfluxDB line protocol. (Optional)")
+	OutputFilePtr := flag.String("outputfile", "", "File the workbook of the output 'xlsx' or the report of the output 'html' is written to. (Optional)")
+	TypePtr := flag.String("type", "pool", "Sets the type of output you want. 'pool' get all pool data. 'reserve' gets you all LUNs/LDEVs that have a reserve. 'drive' gets you all physical drives and audits the spare coverage. 'ldev' gets you all LDEVs. 'orphan' gets you all DP volumes without LUN path. 'chargeback' gets you the capacity per host group. 'pool-consumers' gets you all DP volumes of a pool with their LUN paths. 'local-replication' gets you all ShadowImage and Thin Image pairs. 'remote-replication' gets you all TrueCopy, Universal Replicator and GAD pairs, journals and quorum disks. 'snapshot-prune' deletes the Thin Image snapshots older than their retention. 'hcs-register', 'hcs-unregister' and 'hcs-list' manage the storage systems of a HCS Configuration Manager. 'sessions' gets you all sessions and deletes the orphaned ones of this tool. 'provision' creates and maps a new LDEV. 'lun-map' maps the LUN paths of a map file. 'host-create' creates the host groups and WWNs of a host file. 'ldev-expand' and 'ldev-delete' expand or delete an LDEV. 'pool-expand-plan' plans the expansion of a pool. 'pool-rebalance' recommends DP volumes to migrate between pools. 'snapshot' saves the pools, host groups and LUN paths. 'snapshot-diff' compares two snapshots. 'drift' compares the host groups, WWNs and LUN paths with a state file. (Optional)")
+	PoolIDPtr := flag.Int("poolid", -1, "Pool ID. Shows only the LDEVs of this pool with the types 'ldev' and 'orphan'. The pool of the types 'pool-consumers', 'pool-expand-plan' and 'provision'. (Optional)")
+	LabelPtr := flag.String("label", "", "Shows only the LDEVs with a label matching this regular expression (types 'ldev' and 'orphan'). With the type 'provision' it is the label of the new LDEV and not a regular expression. (Optional)")
+	AttributePtr := flag.String("attribute", "", "Shows only the LDEVs with this attribute (ex: HDP, HDT, CVS, CMD). (Optional)")
+	GroupByPtr := flag.String("groupby", "hostgroup", "Groups the chargeback by 'hostgroup' or by a regular expression on the host group name. The first capture group is the group. (Optional)")
+	TagFilePtr := flag.String("tagfile", "", "File with lines '<host group name regular expression>,<tag>' to group the chargeback by tag. (Optional)")
+	JournalThresholdPtr := flag.Int("journalthreshold", 80, "Journal usage rate [%] above which the remote replication check fails. (Optional)")
+	RetentionFilePtr := flag.String("retentionfile", "", "File with lines '<snapshot group name regular expression>,<days>' to keep the Thin Image snapshots. (Optional)")
+	ExecutePtr := flag.Bool("execute", false, "Executes the changes. Without it only the changes that would be done are shown (dry-run). (Optional)")
+	SvpIPPtr := flag.String("svpip", "", "SVP IP of the storage system to register in the HCS Configuration Manager. (Optional)")
+	SerialPtr := flag.Int("serial", 0, "Serial number of the storage system to register or unregister in the HCS Configuration Manager. (Optional)")
+	ModelPtr := flag.String("model", "", "Model of the storage system to register in the HCS Configuration Manager (ex: 'VSP G600'). (Optional)")
+	StorageFilePtr := flag.String("storagefile", "", "File with lines '<svpIp>,<serial>,<model>' to register or unregister storage systems in the HCS Configuration Manager. (Optional)")
+	SessionIdlePtr := flag.Int("sessionidle", 10, "Minutes without access after which a session of this tool is orphaned. Sessions of the same user and local IP are treated as sessions of this tool. (Optional)")
+	ForcePtr := flag.Bool("force", false, "Deletes the sessions with the force option of the API. (Optional)")
+	TokenCachePtr := flag.String("tokencache", "", "Encrypted file to keep the session between runs. A valid session is reused. (Optional)")
+	AliveTimePtr := flag.Int("alivetime", 0, "Seconds (1-300) without a request after which the session expires. (Optional)")
+	AuthTimeoutPtr := flag.Int("authtimeout", 0, "Seconds (1-900) to wait for the authentication of the session. (Optional)")
+	CapacityPtr := flag.String("capacity", "", "Capacity of the new LDEV with the unit M, G or T (ex: 100G). (Optional)")
+	DataReductionPtr := flag.String("datareduction", "disabled", "Data reduction mode of the new LDEV: 'disabled', 'compression' or 'compression_deduplication'. (Optional)")
+	HostGroupsPtr := flag.String("hostgroups", "", "Host groups '<port>:<host group number or name>' separated by ',' to map the new LDEV (ex: CL1-A:1,CL2-A:esx01). (Optional)")
+	LunPtr := flag.Int("lun", -1, "LUN number of the new LDEV in all host groups. Default is the lowest free LUN of all host groups. (Optional)")
+	MaxSubscriptionPtr := flag.Int("maxsubscription", 150, "Subscription [%] of the pool that a new LDEV must not exceed. (Optional)")
+	MapFilePtr := flag.String("mapfile", "", "File with lines '<LDEV ID>,<port>,<host group number or name>,<LUN>' to map the LDEVs. (Optional)")
+	ProgressFilePtr := flag.String("progressfile", "", "File with the last applied row of the map file. Default is '<mapfile>.progress'. (Optional)")
+	HostFilePtr := flag.String("hostfile", "", "YAML file with the name, OS, WWNs and ports per fabric of a host to create its host groups. (Optional)")
+	LdevIDPtr := flag.String("ldevid", "", "LDEV ID (decimal or 00:04:00) to expand or delete. (Optional)")
+	YesPtr := flag.Bool("yes", false, "Confirms the expansion or deletion of the LDEV without asking. (Optional)")
+	TargetFreePtr := flag.Int("targetfree", 30, "Free capacity [%] the pool should reach with the added parity groups. (Optional)")
+	StateFilePtr := flag.String("statefile", "", "YAML file with the desired host groups, WWNs and LUN paths to compare with the storage. (Optional)")
+	InfluxURLPtr := flag.String("influxurl", "", "URL of the InfluxDB v2 (ex: http://influxdb:8086) the line protocol of the output 'influx' is written to. (Optional)")
+	InfluxOrgPtr := flag.String("influxorg", "", "Organization of the InfluxDB. Required with -influxurl.")
+	InfluxBucketPtr := flag.String("influxbucket", "", "Bucket of the InfluxDB. Required with -influxurl.")
+	InfluxTokenPtr := flag.String("influxtoken", "", "API token of the InfluxDB. (Optional)")
+	InfluxInsecurePtr := flag.Bool("influxinsecure", false, "Skips the verification of the certificate of the InfluxDB (https). (Optional)")
+	JobTimeoutPtr := flag.Int("jobtimeout", 300, "Seconds to wait for an asynchronous job of the storage to complete. (Optional)")
+	SnapshotFromPtr := flag.String("snapshotfrom", "", "Older snapshot file to compare. (Optional)")
+	SnapshotToPtr := flag.String("snapshotto", "", "Newer snapshot file to compare. (Optional)")
+	SnapshotDirPtr := flag.String("snapshotdir", "", "Directory to store the snapshots of every run. The last snapshot is used to show the growth. (Optional)")
 	VerbosePtr := flag.Bool("verbose", false, "Sets the output mode to verbose. (Optional)")
 	HelpPtr := flag.Bool("h", false, "Shows the help. (Optional)")
 	TracePtr := flag.Bool("trace", false, "Shows all output for tracing.")
@@ -243,8 +419,8 @@ func main() {
 		if *VerbosePtr { //show trace logging in standard out
 			Init(ioutil.Discard, os.Stdout, os.Stdout, os.Stdout, os.Stdout)
 		} else {
-			//discard all standard out logging if csv is set. show only data
-			if *OutputPtr == "csv" {
+			//discard all standard out logging if csv, json, markdown or influx without InfluxDB is set. show only data
+			if *OutputPtr == OutputTypeCsv || *OutputPtr == OutputTypeJSON || *OutputPtr == OutputTypeMarkdown || (*OutputPtr == OutputTypeInflux && *InfluxURLPtr == "") {
 				Init(ioutil.Discard, ioutil.Discard, ioutil.Discard, os.Stderr, os.Stderr)
 			} else {
 				if *TracePtr {
@@ -281,7 +457,8 @@ func main() {
 		os.Exit(0)
 	}
 
-	if *UserPtr == "" {
+	//the snapshot diff only reads files. no credentials are needed
+	if *UserPtr == "" && *TypePtr != "snapshot-diff" {
 		//Message what to do
 		fmt.Println()
 		fmt.Println("You must specify a user for your request")
@@ -292,7 +469,7 @@ func main() {
 		os.Exit(1)
 	}
 
-	if *PasswordPtr == "" {
+	if *PasswordPtr == "" && *TypePtr != "snapshot-diff" {
 		//Message what to do
 		fmt.Println()
 		fmt.Println("You must specify a password for your request")
@@ -304,16 +481,191 @@ func main() {
 	}
 
 	//check the type values if they are correct
-	if (*OutputPtr != "stdout") && (*OutputPtr != "csv") {
+	if (*OutputPtr != OutputTypeStdout) && (*OutputPtr != OutputTypeCsv) && (*OutputPtr != OutputTypeJSON) && (*OutputPtr != OutputTypeXLSX) && (*OutputPtr != OutputTypeHTML) && (*OutputPtr != OutputTypeMarkdown) && (*OutputPtr != OutputTypeInflux) {
 		//throw an error an strop the program
-		Warning.Println("The output type you specified is not valid. Please specify 'stdout' or 'csv'. No action will take place.")
+		Warning.Println("The output type you specified is not valid. Please specify 'stdout', 'csv', 'json', 'xlsx', 'html', 'markdown' or 'influx'. No action will take place.")
+		os.Exit(1)
+	}
+
+	if *OutputPtr == OutputTypeInflux && *TypePtr != "pool" {
+		//throw an error an strop the program
+		Warning.Println("The output 'influx' is only available with the type 'pool'. No action will take place.")
+		os.Exit(1)
+	}
+
+	if *InfluxURLPtr != "" && (*OutputPtr != OutputTypeInflux || *InfluxOrgPtr == "" || *InfluxBucketPtr == "") {
+		//throw an error an strop the program
+		Warning.Println("The InfluxDB URL (-influxurl) needs the output 'influx', the organization (-influxorg) and the bucket (-influxbucket). No action will take place.")
+		os.Exit(1)
+	}
+
+	if (*OutputPtr == OutputTypeXLSX || *OutputPtr == OutputTypeHTML) && *OutputFilePtr == "" {
+		//throw an error an strop the program
+		Warning.Println("The output '" + *OutputPtr + "' needs an output file (-outputfile). No action will take place.")
 		os.Exit(1)
 	}
 
 	//check the type values if they are correct
-	if (*TypePtr != "reserve") && (*TypePtr != "pool") {
+	switch *TypePtr {
+	case "pool", "reserve", "drive", "ldev", "orphan", "chargeback", "pool-consumers", "local-replication", "remote-replication", "snapshot-prune", "hcs-register", "hcs-unregister", "hcs-list", "sessions", "provision", "lun-map", "host-create", "ldev-expand", "ldev-delete", "pool-expand-plan", "pool-rebalance", "snapshot", "snapshot-diff", "drift":
+	default:
+		//throw an error an strop the program
+		Warning.Println("The type you specified is not valid. Please specify 'pool', 'reserve', 'drive', 'ldev', 'orphan', 'chargeback', 'pool-consumers', 'local-replication', 'remote-replication', 'snapshot-prune', 'hcs-register', 'hcs-unregister', 'hcs-list', 'sessions', 'provision', 'lun-map', 'host-create', 'ldev-expand', 'ldev-delete', 'pool-expand-plan', 'pool-rebalance', 'snapshot', 'snapshot-diff' or 'drift'. No action will take place.")
+		os.Exit(1)
+	}
+
+	//the pool consumers are always shown for one pool
+	if *TypePtr == "pool-consumers" && *PoolIDPtr < 0 {
+		//throw an error an strop the program
+		Warning.Println("The type 'pool-consumers' needs a pool id (-poolid). No action will take place.")
+		os.Exit(1)
+	}
+
+	if *TypePtr == "snapshot-prune" && *RetentionFilePtr == "" {
+		//throw an error an strop the program
+		Warning.Println("The type 'snapshot-prune' needs a retention file (-retentionfile). No action will take place.")
+		os.Exit(1)
+	}
+
+	if *TypePtr == "hcs-register" && *StorageFilePtr == "" && (*SvpIPPtr == "" || *SerialPtr <= 0 || *ModelPtr == "") {
+		//throw an error an strop the program
+		Warning.Println("The type 'hcs-register' needs the storage systems (-svpip, -serial and -model or -storagefile). No action will take place.")
+		os.Exit(1)
+	}
+
+	if *TypePtr == "hcs-unregister" && *StorageFilePtr == "" && *SerialPtr <= 0 {
+		//throw an error an strop the program
+		Warning.Println("The type 'hcs-unregister' needs the storage systems (-serial or -storagefile). No action will take place.")
+		os.Exit(1)
+	}
+
+	if *TypePtr == "provision" && (*PoolIDPtr < 0 || *CapacityPtr == "" || *HostGroupsPtr == "") {
+		//throw an error an strop the program
+		Warning.Println("The type 'provision' needs a pool id (-poolid), a capacity (-capacity) and the host groups (-hostgroups). No action will take place.")
+		os.Exit(1)
+	}
+
+	if *TypePtr == "provision" {
+		if _, CapacityState := CapacityParse(*CapacityPtr); CapacityState {
+			//throw an error an strop the program
+			Warning.Println("The capacity you specified is not valid. Please specify a number with the unit M, G or T (ex: 100G). No action will take place.")
+			os.Exit(1)
+		}
+		if _, HostGroupsState := ProvisionTargetsParse(*HostGroupsPtr); HostGroupsState {
+			//throw an error an strop the program
+			Warning.Println("The host groups you specified are not valid. Please specify '<port>:<host group number or name>' separated by ',' (ex: CL1-A:1,CL2-A:esx01). No action will take place.")
+			os.Exit(1)
+		}
+	}
+
+	if *TypePtr == "lun-map" && *MapFilePtr == "" {
+		//throw an error an strop the program
+		Warning.Println("The type 'lun-map' needs a map file (-mapfile). No action will take place.")
+		os.Exit(1)
+	}
+
+	if *TypePtr == "host-create" && *HostFilePtr == "" {
+		//throw an error an strop the program
+		Warning.Println("The type 'host-create' needs a host file (-hostfile). No action will take place.")
+		os.Exit(1)
+	}
+
+	if *TypePtr == "ldev-expand" && (*LdevIDPtr == "" || *CapacityPtr == "") {
+		//throw an error an strop the program
+		Warning.Println("The type 'ldev-expand' needs an LDEV ID (-ldevid) and the additional capacity (-capacity). No action will take place.")
+		os.Exit(1)
+	}
+
+	if *TypePtr == "ldev-expand" {
+		if _, CapacityState := CapacityParse(*CapacityPtr); CapacityState {
+			//throw an error an strop the program
+			Warning.Println("The capacity you specified is not valid. Please specify a number with the unit M, G or T (ex: 100G). No action will take place.")
+			os.Exit(1)
+		}
+	}
+
+	if *TypePtr == "ldev-delete" && *LdevIDPtr == "" {
+		//throw an error an strop the program
+		Warning.Println("The type 'ldev-delete' needs an LDEV ID (-ldevid). No action will take place.")
+		os.Exit(1)
+	}
+
+	if _, LdevIDState := LdevIDParse(*LdevIDPtr); LdevIDState && *LdevIDPtr != "" {
+		//throw an error an strop the program
+		Warning.Println("The LDEV ID you specified is not valid. Please specify a decimal (ex: 1024) or hex LDEV ID (ex: 00:04:00) from 0 to 65279 (00:FE:FF). No action will take place.")
+		os.Exit(1)
+	}
+
+	if *TypePtr == "pool-expand-plan" && *PoolIDPtr < 0 {
+		//throw an error an strop the program
+		Warning.Println("The type 'pool-expand-plan' needs a pool id (-poolid). No action will take place.")
+		os.Exit(1)
+	}
+
+	if *TargetFreePtr < 1 || *TargetFreePtr > 99 {
 		//throw an error an strop the program
-		Warning.Println("The type you specified is not valid. Please specify 'pool' or 'reserve'. No action will take place.")
+		Warning.Println("The target free capacity you specified is not valid. Please specify 1 to 99 percent. No action will take place.")
+		os.Exit(1)
+	}
+
+	if *TypePtr == "snapshot" && *SnapshotDirPtr == "" {
+		//throw an error an strop the program
+		Warning.Println("The type 'snapshot' needs a snapshot directory (-snapshotdir). No action will take place.")
+		os.Exit(1)
+	}
+
+	if *TypePtr == "snapshot-diff" && (*SnapshotFromPtr == "" || *SnapshotToPtr == "") {
+		//throw an error an strop the program
+		Warning.Println("The type 'snapshot-diff' needs two snapshot files (-snapshotfrom and -snapshotto). No action will take place.")
+		os.Exit(1)
+	}
+
+	if *TypePtr == "drift" && *StateFilePtr == "" {
+		//throw an error an strop the program
+		Warning.Println("The type 'drift' needs a state file (-statefile). No action will take place.")
+		os.Exit(1)
+	}
+
+	if *DataReductionPtr != "disabled" && *DataReductionPtr != "compression" && *DataReductionPtr != "compression_deduplication" {
+		//throw an error an strop the program
+		Warning.Println("The data reduction mode you specified is not valid. Please specify 'disabled', 'compression' or 'compression_deduplication'. No action will take place.")
+		os.Exit(1)
+	}
+
+	if *LunPtr > ProvisionMaxLun || *MaxSubscriptionPtr <= 0 {
+		//throw an error an strop the program
+		Warning.Println("The LUN (0-" + strconv.Itoa(ProvisionMaxLun) + ") or the max subscription (>0) you specified is not valid. No action will take place.")
+		os.Exit(1)
+	}
+
+	//check the session timeouts if they are in the range of the storage
+	if *AliveTimePtr < 0 || *AliveTimePtr > 300 {
+		//throw an error an strop the program
+		Warning.Println("The alive time you specified is not valid. Please specify 1 to 300 seconds. No action will take place.")
+		os.Exit(1)
+	}
+
+	if *AuthTimeoutPtr < 0 || *AuthTimeoutPtr > 900 {
+		//throw an error an strop the program
+		Warning.Println("The authentication timeout you specified is not valid. Please specify 1 to 900 seconds. No action will take place.")
+		os.Exit(1)
+	}
+
+	//compile the label filter once. with the type 'provision' it is the label of the new LDEV and not a regular expression
+	var LabelFilter *regexp.Regexp
+	if *LabelPtr != "" && *TypePtr != "provision" {
+		var err error
+		if LabelFilter, err = regexp.Compile(*LabelPtr); err != nil {
+			//throw an error an strop the program
+			Warning.Println("The label filter you specified is not a valid regular expression (" + err.Error() + "). No action will take place.")
+			os.Exit(1)
+		}
+	}
+
+	//check the groupby value if it is a valid regular expression
+	if _, err := regexp.Compile(*GroupByPtr); err != nil {
+		//throw an error an strop the program
+		Warning.Println("The groupby value you specified is not 'hostgroup' nor a valid regular expression (" + err.Error() + "). No action will take place.")
 		os.Exit(1)
 	}
 
@@ -325,7 +677,7 @@ func main() {
 	//All values are output with 2 decimal digits after the dot
 	Parameters.RoundPrecision = 2
 	//This is the maximum number of elements that the REST API can return
-	Parameters.MaxElementCount = 16348
+	Parameters.MaxElementCount = 16384
 
 	//These are constants to specify the start and end of a row and the table to easy th output creation as table and csv
 	Parameters.ElementStringStart = "Lacsap-Hitachi-Start"
@@ -348,11 +700,57 @@ func main() {
 	Parameters.RequestType = ""
 	Parameters.RequestBody = ""
 	Parameters.OutputStyle = *OutputPtr
+	Parameters.OutputFile = *OutputFilePtr
 	Parameters.OutputType = *TypePtr
 	Parameters.Token = ""
 	Parameters.StorageDeviceID = ""
 	Parameters.SessionID = 0.0
 
+	//filters
+	Parameters.PoolID = *PoolIDPtr
+	Parameters.LabelFilter = LabelFilter
+	Parameters.AttributeFilter = *AttributePtr
+
+	//chargeback
+	Parameters.GroupBy = *GroupByPtr
+	Parameters.TagFile = *TagFilePtr
+
+	//snapshots
+	Parameters.SnapshotDir = *SnapshotDirPtr
+	Parameters.JournalThreshold = *JournalThresholdPtr
+	Parameters.RetentionFile = *RetentionFilePtr
+	Parameters.Execute = *ExecutePtr
+	Parameters.JobTimeout = *JobTimeoutPtr
+	Parameters.SvpIP = *SvpIPPtr
+	Parameters.Serial = *SerialPtr
+	Parameters.Model = *ModelPtr
+	Parameters.StorageFile = *StorageFilePtr
+	Parameters.SessionIdle = *SessionIdlePtr
+	Parameters.Force = *ForcePtr
+	Parameters.TokenCache = *TokenCachePtr
+	Parameters.Label = *LabelPtr
+	Parameters.Capacity = *CapacityPtr
+	Parameters.DataReduction = *DataReductionPtr
+	Parameters.HostGroups = *HostGroupsPtr
+	Parameters.Lun = *LunPtr
+	Parameters.MaxSubscription = *MaxSubscriptionPtr
+	Parameters.MapFile = *MapFilePtr
+	Parameters.ProgressFile = *ProgressFilePtr
+	Parameters.HostFile = *HostFilePtr
+	Parameters.LdevID = *LdevIDPtr
+	Parameters.Yes = *YesPtr
+	Parameters.TargetFree = *TargetFreePtr
+	Parameters.SnapshotFrom = *SnapshotFromPtr
+	Parameters.SnapshotTo = *SnapshotToPtr
+	Parameters.StateFile = *StateFilePtr
+	Parameters.InfluxURL = *InfluxURLPtr
+	Parameters.InfluxOrg = *InfluxOrgPtr
+	Parameters.InfluxBucket = *InfluxBucketPtr
+	Parameters.InfluxToken = *InfluxTokenPtr
+	Parameters.InfluxInsecure = *InfluxInsecurePtr
+	Parameters.AliveTime = *AliveTimePtr
+	Parameters.AuthTimeout = *AuthTimeoutPtr
+
 	/*
 		//hcs rest api
 		Protocol = "http"
@@ -397,40 +795,397 @@ func main() {
 		//check if version is ok
 		State = CheckVersion(Parameters.RestVersion, VersionMinimum)
 
-		//Get the StorageDeviceID
-		//Verbose.Println("Get the StorageDeviceID")
-		Parameters.StorageDeviceID, State = StorageDeviceIDGet(Parameters)
-
-		//Create a sesseion
-		//Verbose.Println("Create a Session")
-		Parameters.Token, Parameters.SessionID, State = TokenGet(Parameters)
+		//Get the StorageDeviceID and create a session. with -tokencache the cached session is reused
+		Parameters.StorageDeviceID, Parameters.Token, Parameters.SessionID, State = SessionOpen(Parameters)
 
 		//Verbose.Println("Get Pool information")
-		output, State = PoolsGet(Parameters)
+		var PoolsState bool
+		output, PoolsState = PoolsGet(Parameters)
 
-		//Delete the session
-		//Verbose.Println("Delete the Session")
-		output, State = TokenDelete(Parameters)
+		//Delete the session. with -tokencache the session is kept for the next run
+		output, State = SessionClose(Parameters)
+
+		if PoolsState {
+			os.Exit(105)
+		}
 	}
 
 	//reserve type
 	if *TypePtr == "reserve" {
 		//Get LUN reservation information
 
-		//Get the StorageDeviceID
-		//Verbose.Println("Get the StorageDeviceID")
-		Parameters.StorageDeviceID, State = StorageDeviceIDGet(Parameters)
-
-		//Create a sesseion
-		//Verbose.Println("Create a Session")
-		Parameters.Token, Parameters.SessionID, State = TokenGet(Parameters)
+		//Get the StorageDeviceID and create a session. with -tokencache the cached session is reused
+		Parameters.StorageDeviceID, Parameters.Token, Parameters.SessionID, State = SessionOpen(Parameters)
 
 		output, State = LunsGetReserve(Parameters)
 
-		//Delete the session
-		//Verbose.Println("Delete the Session")
-		output, State = TokenDelete(Parameters)
+		//Delete the session. with -tokencache the session is kept for the next run
+		output, State = SessionClose(Parameters)
+
+	}
+
+	//drive type
+	if *TypePtr == "drive" {
+		//Get the physical drive information
+
+		//Get the StorageDeviceID and create a session. with -tokencache the cached session is reused
+		Parameters.StorageDeviceID, Parameters.Token, Parameters.SessionID, State = SessionOpen(Parameters)
+
+		output, State = DrivesGet(Parameters)
+
+		//Delete the session. with -tokencache the session is kept for the next run
+		output, State = SessionClose(Parameters)
+	}
+
+	//ldev type
+	if *TypePtr == "ldev" {
+		//Get the LDEV information
+
+		//Get the StorageDeviceID and create a session. with -tokencache the cached session is reused
+		Parameters.StorageDeviceID, Parameters.Token, Parameters.SessionID, State = SessionOpen(Parameters)
+
+		output, State = LdevsGet(Parameters)
+
+		//Delete the session. with -tokencache the session is kept for the next run
+		output, State = SessionClose(Parameters)
+	}
+
+	//orphan type
+	if *TypePtr == "orphan" {
+		//Get the DP volumes without LUN path
+
+		//Get the StorageDeviceID and create a session. with -tokencache the cached session is reused
+		Parameters.StorageDeviceID, Parameters.Token, Parameters.SessionID, State = SessionOpen(Parameters)
+
+		output, State = LdevsOrphanGet(Parameters)
+
+		//Delete the session. with -tokencache the session is kept for the next run
+		output, State = SessionClose(Parameters)
+	}
+
+	//chargeback type
+	if *TypePtr == "chargeback" {
+		//Get the capacity per host group
+
+		//the tag file is read before the session is opened. it stops with exit status 70 or 71
+		var Tags []ChargebackTag
+		if Parameters.TagFile != "" {
+			Tags, State = ChargebackTagsRead(Parameters.TagFile)
+		}
+
+		//Get the StorageDeviceID and create a session. with -tokencache the cached session is reused
+		Parameters.StorageDeviceID, Parameters.Token, Parameters.SessionID, State = SessionOpen(Parameters)
+
+		output, State = ChargebackGet(Parameters, Tags)
+
+		//Delete the session. with -tokencache the session is kept for the next run
+		output, State = SessionClose(Parameters)
+	}
+
+	//pool-consumers type
+	if *TypePtr == "pool-consumers" {
+		//Get the DP volumes of a pool
+
+		//Get the StorageDeviceID and create a session. with -tokencache the cached session is reused
+		Parameters.StorageDeviceID, Parameters.Token, Parameters.SessionID, State = SessionOpen(Parameters)
+
+		output, State = PoolConsumersGet(Parameters)
+
+		//Delete the session. with -tokencache the session is kept for the next run
+		output, State = SessionClose(Parameters)
+	}
+
+	//local-replication type
+	if *TypePtr == "local-replication" {
+		//Get the ShadowImage and Thin Image pairs
+
+		//Get the StorageDeviceID and create a session. with -tokencache the cached session is reused
+		Parameters.StorageDeviceID, Parameters.Token, Parameters.SessionID, State = SessionOpen(Parameters)
+
+		output, State = LocalReplicationGet(Parameters)
+
+		//Delete the session. with -tokencache the session is kept for the next run
+		output, State = SessionClose(Parameters)
+	}
+
+	//remote-replication type
+	if *TypePtr == "remote-replication" {
+		//Get the TrueCopy, Universal Replicator and GAD pairs, the journals and the quorum disks
+
+		//Get the StorageDeviceID and create a session. with -tokencache the cached session is reused
+		Parameters.StorageDeviceID, Parameters.Token, Parameters.SessionID, State = SessionOpen(Parameters)
+
+		var Findings int
+		Findings, State = RemoteReplicationGet(Parameters)
+
+		//Delete the session. with -tokencache the session is kept for the next run
+		output, State = SessionClose(Parameters)
+
+		//the report is used as DR readiness check
+		if Findings > 0 {
+			Warning.Println("The remote replication check failed with " + strconv.Itoa(Findings) + " finding(s).")
+			os.Exit(90)
+		}
+	}
+
+	//snapshot-prune type
+	if *TypePtr == "snapshot-prune" {
+		//Delete the Thin Image snapshots older than their retention
+
+		//the retention file is read before the session is opened. it stops with exit status 72 or 73
+		var Retentions []SnapshotRetention
+		Retentions, State = SnapshotRetentionRead(Parameters.RetentionFile)
+
+		//Get the StorageDeviceID and create a session. with -tokencache the cached session is reused
+		Parameters.StorageDeviceID, Parameters.Token, Parameters.SessionID, State = SessionOpen(Parameters)
+
+		var PruneState bool
+		output, PruneState = SnapshotPrune(Parameters, Retentions)
+
+		//Delete the session. with -tokencache the session is kept for the next run
+		output, State = SessionClose(Parameters)
+
+		if PruneState {
+			Warning.Println("Not all snapshots could be deleted.")
+			os.Exit(91)
+		}
+	}
+
+	//hcs-register type
+	if *TypePtr == "hcs-register" {
+		//Register the storage systems in the HCS Configuration Manager. No session is needed.
+		var Failed int
+		Failed, State = HCSRegister(Parameters)
+		if Failed > 0 {
+			Warning.Println(strconv.Itoa(Failed) + " storage system(s) could not be registered.")
+			os.Exit(92)
+		}
+	}
+
+	//hcs-unregister type
+	if *TypePtr == "hcs-unregister" {
+		//Unregister the storage systems from the HCS Configuration Manager. No session is needed.
+		var Failed int
+		Failed, State = HCSUnregister(Parameters)
+		if Failed > 0 {
+			Warning.Println(strconv.Itoa(Failed) + " storage system(s) could not be unregistered.")
+			os.Exit(92)
+		}
+	}
+
+	//hcs-list type
+	if *TypePtr == "hcs-list" {
+		//List the storage systems of the HCS Configuration Manager. No session is needed.
+		output, State = HCSList(Parameters)
+	}
+
+	//snapshot-diff type
+	if *TypePtr == "snapshot-diff" {
+		//Compare two snapshot files. No session is needed.
+		_, State = SnapshotDiff(Parameters)
+	}
+
+	//provision type
+	if *TypePtr == "provision" {
+		//Create an LDEV and map it to the host groups
+
+		//Get the StorageDeviceID and create a session. with -tokencache the cached session is reused
+		Parameters.StorageDeviceID, Parameters.Token, Parameters.SessionID, State = SessionOpen(Parameters)
+
+		var Refused bool
+		var ProvisionState bool
+		Refused, ProvisionState = Provision(Parameters)
+
+		//Delete the session. with -tokencache the session is kept for the next run
+		output, State = SessionClose(Parameters)
+
+		if Refused {
+			Warning.Println("The provisioning is refused. No action took place.")
+			os.Exit(94)
+		}
+		if ProvisionState {
+			Warning.Println("The LDEV could not be provisioned completely.")
+			os.Exit(93)
+		}
+	}
+
+	//lun-map type
+	if *TypePtr == "lun-map" {
+		//Map the LDEVs of the map file to the host groups
+
+		//the map file is read before the session is opened. it stops with exit status 76 or 77
+		var Rows []LunMapRow
+		Rows, State = LunMapRead(Parameters.MapFile)
+
+		//Get the StorageDeviceID and create a session. with -tokencache the cached session is reused
+		Parameters.StorageDeviceID, Parameters.Token, Parameters.SessionID, State = SessionOpen(Parameters)
+
+		var Invalid int
+		var MapState bool
+		Invalid, MapState = LunMap(Parameters, Rows)
+
+		//Delete the session. with -tokencache the session is kept for the next run
+		output, State = SessionClose(Parameters)
+
+		if Invalid > 0 {
+			os.Exit(95)
+		}
+		if MapState {
+			Warning.Println("Not all LUN paths could be mapped.")
+			os.Exit(96)
+		}
+	}
+
+	//host-create type
+	if *TypePtr == "host-create" {
+		//Create the missing host groups and WWNs of the host file
+
+		//the host file is read before the session is opened. it stops with exit status 78 or 79
+		var Host HostDefinition
+		Host, State = HostDefinitionRead(Parameters.HostFile)
+
+		//Get the StorageDeviceID and create a session. with -tokencache the cached session is reused
+		Parameters.StorageDeviceID, Parameters.Token, Parameters.SessionID, State = SessionOpen(Parameters)
+
+		var Failed int
+		Failed, State = HostCreate(Parameters, Host)
+
+		//Delete the session. with -tokencache the session is kept for the next run
+		output, State = SessionClose(Parameters)
+
+		if Failed > 0 {
+			Warning.Println(strconv.Itoa(Failed) + " host group(s) or WWN(s) could not be created.")
+			os.Exit(97)
+		}
+	}
+
+	//ldev-expand type
+	if *TypePtr == "ldev-expand" {
+		//Expand a DP volume if its pool has enough free capacity
+
+		//Get the StorageDeviceID and create a session. with -tokencache the cached session is reused
+		Parameters.StorageDeviceID, Parameters.Token, Parameters.SessionID, State = SessionOpen(Parameters)
+
+		var Refused bool
+		var ExpandState bool
+		Refused, ExpandState = LdevExpand(Parameters)
+
+		//Delete the session. with -tokencache the session is kept for the next run
+		output, State = SessionClose(Parameters)
+
+		if Refused {
+			os.Exit(98)
+		}
+		if ExpandState {
+			Warning.Println("The LDEV could not be expanded.")
+			os.Exit(99)
+		}
+	}
+
+	//ldev-delete type
+	if *TypePtr == "ldev-delete" {
+		//Delete an LDEV that is not used anymore
+
+		//Get the StorageDeviceID and create a session. with -tokencache the cached session is reused
+		Parameters.StorageDeviceID, Parameters.Token, Parameters.SessionID, State = SessionOpen(Parameters)
+
+		var Refused bool
+		var DeleteState bool
+		Refused, DeleteState = LdevDelete(Parameters)
 
+		//Delete the session. with -tokencache the session is kept for the next run
+		output, State = SessionClose(Parameters)
+
+		if Refused {
+			os.Exit(98)
+		}
+		if DeleteState {
+			Warning.Println("The LDEV could not be deleted.")
+			os.Exit(86)
+		}
+	}
+
+	//pool-expand-plan type
+	if *TypePtr == "pool-expand-plan" {
+		//Propose the parity groups to add to a pool
+
+		//Get the StorageDeviceID and create a session. with -tokencache the cached session is reused
+		Parameters.StorageDeviceID, Parameters.Token, Parameters.SessionID, State = SessionOpen(Parameters)
+
+		var NotFound bool
+		var PlanState bool
+		NotFound, PlanState = PoolExpandPlan(Parameters)
+
+		//Delete the session. with -tokencache the session is kept for the next run
+		output, State = SessionClose(Parameters)
+
+		if NotFound {
+			os.Exit(87)
+		}
+		if PlanState {
+			os.Exit(88)
+		}
+	}
+
+	//pool-rebalance type
+	if *TypePtr == "pool-rebalance" {
+		//Recommend the DP volumes to migrate between the pools of a tier
+
+		//Get the StorageDeviceID and create a session. with -tokencache the cached session is reused
+		Parameters.StorageDeviceID, Parameters.Token, Parameters.SessionID, State = SessionOpen(Parameters)
+
+		output, State = PoolRebalance(Parameters)
+
+		//Delete the session. with -tokencache the session is kept for the next run
+		output, State = SessionClose(Parameters)
+	}
+
+	//snapshot type
+	if *TypePtr == "snapshot" {
+		//Store the pools, host groups and LUN paths in the snapshot directory
+
+		//Get the StorageDeviceID and create a session. with -tokencache the cached session is reused
+		Parameters.StorageDeviceID, Parameters.Token, Parameters.SessionID, State = SessionOpen(Parameters)
+
+		output, State = SnapshotCollect(Parameters)
+
+		//Delete the session. with -tokencache the session is kept for the next run
+		output, State = SessionClose(Parameters)
+	}
+
+	//drift type
+	if *TypePtr == "drift" {
+		//Compare the host groups, WWNs and LUN paths with the state file
+
+		//the state file is read before the session is opened. it stops with exit status 82 or 85
+		var Desired DesiredState
+		Desired, State = DesiredStateRead(Parameters.StateFile)
+
+		//Get the StorageDeviceID and create a session. with -tokencache the cached session is reused
+		Parameters.StorageDeviceID, Parameters.Token, Parameters.SessionID, State = SessionOpen(Parameters)
+
+		var Differences int
+		Differences, State = Drift(Parameters, Desired)
+
+		//Delete the session. with -tokencache the session is kept for the next run
+		output, State = SessionClose(Parameters)
+
+		if Differences > 0 {
+			os.Exit(89)
+		}
+	}
+
+	//sessions type
+	if *TypePtr == "sessions" {
+		//Get all sessions and delete the orphaned ones of this tool
+
+		//Get the StorageDeviceID and create a session. with -tokencache the cached session is reused
+		Parameters.StorageDeviceID, Parameters.Token, Parameters.SessionID, State = SessionOpen(Parameters)
+
+		output, State = SessionsGet(Parameters)
+
+		//Delete the session. with -tokencache the session is kept for the next run
+		output, State = SessionClose(Parameters)
 	}
 
 	//Stop execute commands
@@ -448,177 +1203,176 @@ func LunsGetReserve(p Params) (string, bool) {
 	//initial state is true that means NOK
 	State := true
 
-	var Out string
+	//all LUNs of all HostGroups
+	var Luns []LunInfo
+	Luns, State = LunsListGet(p)
 
-	//GET base-URL/v1/objects/storages/storage-device-ID/luns
-	var ConfigurationManagerString string
-	ConfigurationManagerString = "/ConfigurationManager/v1/objects/storages/"
-	var PostString string
-	PostString = "/host-groups?count=" + strconv.FormatInt(p.MaxElementCount, 10)
+	//documents get the LUNs as table
+	OutData := [][]string{}
 
-	Verbose.Println("Get general information of all HostGroups")
+	var HostGroupID string
+	HostGroupID = ""
+	for _, Lun := range Luns {
+		//HostGroup Element
+		if Lun.PortID+","+strconv.Itoa(Lun.HostGroupNumber) != HostGroupID {
+			HostGroupID = Lun.PortID + "," + strconv.Itoa(Lun.HostGroupNumber)
+			Info.Println("Get the HostGroup Information: " + Lun.PortID + " " + Lun.HostGroupName + "(" + strconv.Itoa(Lun.HostGroupNumber) + ")")
+		}
 
-	/*
-	   http://10.70.4.145/ConfigurationManager/v1/objects/storages/800000058068/host-groups?count=16384
-	   {
-	       "data": [{
-	           "hostGroupId": "CL1-A,0",
-	           "portId": "CL1-A",
-	           "hostGroupNumber": 0,
-	           "hostGroupName": "1A-G00",
-	           "hostMode": "LINUX/IRIX"
-	       }, {
+		// the "luHostReserve" elements that are set.
+		ReserveString := LunReservesFormat(Lun)
 
-	*/
+		var LdevString string
+		//format the ldev id to xx:xx
+		LdevString = LdevIDFormat(Lun.LdevID)
 
-	p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + ConfigurationManagerString + p.StorageDeviceID + PostString
-	Debug.Println(p.URL)
-	p.RequestType = "GET"
-	Out = HTTPRequest(p)
+		if len(Lun.Reserves) > 0 {
+			//reservations set
+			Info.Printf("LUN: %04d LDEV: %s reservations: %s", Lun.Lun, LdevString, ReserveString)
+		} else {
+			//No reservations set
+			Info.Printf("LUN: %04d LDEV: %s reservations: none", Lun.Lun, LdevString)
+		}
 
-	//is the string "data" in the output
-	if CheckIsInString(Out, p.DataElement) {
-		Error.Println(Out)
+		OutData = append(OutData, []string{p.ElementStringStart})
+		OutData = append(OutData, []string{HeaderFormat("Port", "string", p), Lun.PortID})
+		OutData = append(OutData, []string{HeaderFormat("Host group", "string", p), Lun.HostGroupName})
+		OutData = append(OutData, []string{HeaderFormat("LUN", "float64", p), strconv.Itoa(Lun.Lun)})
+		OutData = append(OutData, []string{HeaderFormat("LDEV ID", "string", p), LdevString})
+		OutData = append(OutData, []string{HeaderFormat("Reservations", "string", p), ReserveString})
+		OutData = append(OutData, []string{p.ElementStringEnd})
 	}
 
-	var JSONUnmarshalOut map[string]interface{}
-	JSONUnmarshalOut, State = JSONUnmarshal(Out)
-	Debug.Println("JSON Unmarshal:", JSONUnmarshalOut)
-
-	// all responses from hitachi rest api calls answer with only one element called data "{ "data": [{"
-	if len(JSONUnmarshalOut) != 1 {
-		//this should never happens
-		//at hitachi the response always starts with "{ "data": [{"
-		Error.Println("JSON parsing error (Return Format is not correct).")
-		os.Exit(41)
+	if OutputDocument(p) && len(OutData) > 0 {
+		p.ReportName = "reserves"
+		if OutputListFormat(OutData, p) {
+			Warning.Println("The function 'OutputListFormat' returned an Error.")
+		}
 	}
 
-	Debug.Println("Number of HostGroups", len(JSONUnmarshalOut["data"].([]interface{})))
-	for key1, hostgroup := range JSONUnmarshalOut["data"].([]interface{}) {
-		//Verbose.Println("Key: "+strconv.Itoa(key1), "Value: ", hostgroup)
-		ParsedHostGroupMap := hostgroup.(map[string]interface{})
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'LunsGetReserve' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'LunsGetReserve' return values State:", State)
+	Debug.Println("Function 'LunsGetReserve' end")
 
-		// HostGroup Element
-		Debug.Println("HostGroup Element: ", key1)
-		Info.Println("Get the HostGroup Information: " + ParsedHostGroupMap["portId"].(string) + " " + ParsedHostGroupMap["hostGroupName"].(string) + "(" + strconv.FormatFloat(ParsedHostGroupMap["hostGroupNumber"].(float64), 'f', 0, 64) + ")")
+	Verbose.Println("Get the LUNs Information end")
 
-		//------------------------------------
-		//get lun information
-		p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + ConfigurationManagerString + p.StorageDeviceID + "/luns?portId=" + ParsedHostGroupMap["portId"].(string) + "&hostGroupNumber=" + strconv.FormatFloat(ParsedHostGroupMap["hostGroupNumber"].(float64), 'f', 0, 64)
-		Debug.Println(p.URL)
-		p.RequestType = "GET"
-		Out = HTTPRequest(p)
+	//state to OK
+	State = false
+	//return the choosen "storageDeviceId" and its state
+	return "", State
+}
 
-		//is the string "data" in the output
-		if CheckIsInString(Out, p.DataElement) {
-			Error.Println(Out)
-		}
+//PoolsGet is used to output all pool information
+//return value (string) is empty and the status of the request. if the line protocol cannot be written to InfluxDB (-influxurl) the state is true (exit status 105). Otherwise false.
+//example: PoolsGet(p)
+func PoolsGet(p Params) (string, bool) {
+	Debug.Println("Function 'PoolsGet' start.")
+	//start timer
+	TimeStart := time.Now()
+
+	//initial state is true that means NOK
+	State := true
 
-		var JSONUnmarshalOut1 map[string]interface{}
-		JSONUnmarshalOut1, State = JSONUnmarshal(Out)
-		Debug.Println("JSON Unmarshal:", JSONUnmarshalOut1)
+	Info.Println("Get Pool information start")
+
+	//true if the line protocol cannot be written to InfluxDB
+	var InfluxState bool
+	InfluxState = false
+
+	//all pools with the calculated values
+	var Pools []PoolInfo
+	Pools, State = PoolsListGet(p)
+
+	//add empty string of strings to collect all pool data to output
+	OutData := [][]string{}
 
-		// all responses from hitachi rest api calls answer with only one element called data "{ "data": [{"
-		if len(JSONUnmarshalOut1) != 1 {
-			//this should never happens
-			//at hitachi the response always starts with "{ "data": [{"
-			Error.Println("JSON parsing error (Return Format is not correct).")
-			os.Exit(41)
+	//documents start with the totals of the storage
+	if OutputDocument(p) {
+		var Storage StorageInfo
+		Storage, State = StorageInfoGet(p)
+		p.ReportName = "summary"
+		if OutputListFormat(PoolsSummaryFormat(Storage, Pools, p), p) {
+			Warning.Println("The function 'OutputListFormat' returned an Error.")
 		}
+	}
 
-		Debug.Println("Number of LUNs", len(JSONUnmarshalOut1["data"].([]interface{})))
-		for _, luns := range JSONUnmarshalOut1["data"].([]interface{}) {
-			//Verbose.Println("Key: "+strconv.Itoa(key1), "Value: ", value1)
-			ParsedLunsMap := luns.(map[string]interface{})
-
-			/*
-			   http://10.70.4.145/ConfigurationManager/v1/objects/storages/800000058068/luns?portId=CL1-B&hostGroupNumber=1
-
-			   				"data": [{
-			   			"lunId": "CL1-B,1,1",
-			   			"portId": "CL1-B",
-			   			"hostGroupNumber": 1,
-			   			"hostMode": "WIN_EX",
-			   			"lun": 1,
-			   			"ldevId": 13312,
-			   			"isCommandDevice": false,
-			   			"luHostReserve": {
-			   				"openSystem": false,
-			   				"persistent": false,
-			   				"pgrKey": false,
-			   				"mainframe": false,
-			   				"acaReserve": false
-			   			},
-			   			"hostModeOptions": [40, 73]
-			*/
-
-			// LUN Element
-			//Verbose.Println("LUN Element: ", key2)
-
-			// Loop over the "luHostReserve" map.
-			ReserveString := ""
-			ReserveSet := false
-
-			//map[persistent:false pgrKey:false mainframe:false acaReserve:false openSystem:false]
-			for key3, reserve := range ParsedLunsMap["luHostReserve"].(map[string]interface{}) {
-				Debug.Println("luHostReserve Element: ", key3)
-				if reserve.(bool) {
-					ReserveSet = true
-					if ReserveString == "" {
-						ReserveString = "(" + key3 + "=" + strconv.FormatBool(reserve.(bool))
-					} else {
-						ReserveString = ReserveString + "; " + key3 + "=" + strconv.FormatBool(reserve.(bool))
-					}
-				}
-			}
-			ReserveString = ReserveString + ")"
-
-			var LdevString string
-			//format float64 to string in hex
-			LdevString = strconv.FormatInt(int64(ParsedLunsMap["ldevId"].(float64)), 16)
-			//add leading zeros until it is 4 digits long
-			if len(LdevString) < 4 {
-				Len := 4 - len(LdevString)
-				for i := 0; i < Len; i++ {
-					LdevString = "0" + LdevString
-				}
+	for _, PoolElement := range Pools {
+		//select the output type
+		// at the beginning it is checked that only these two values pass the script
+		switch p.OutputStyle {
+		case "stdout":
+			OutData, State = PoolInfoFormatTable(PoolElement, p)
+			if OutputStandardFormat(OutData, p) {
+				Warning.Println("The function 'OutputStandardFormat' returned an Error.")
 			}
+		case "csv", "json", "xlsx", "html", "markdown":
+			OutData, State = PoolInfoFormatCSV(OutData, PoolElement, p)
+			//As all Pools have to be listed in one Table the output function is called at the end of the function
+		}
+	}
 
-			// format the hex string to xx:xx
-			LdevString = LdevString[:len(LdevString)-2] + ":" + LdevString[len(LdevString)-2:]
+	// CSV, JSON, XLSX, HTML and markdown output OutData
+	if p.OutputStyle == "csv" || p.OutputStyle == "json" || p.OutputStyle == "xlsx" || p.OutputStyle == "html" || p.OutputStyle == "markdown" {
+		p.ReportName = "pools"
+		if OutputStandardFormat(OutData, p) {
+			Warning.Println("The function 'OutputStandardFormat' returned an Error.")
+		}
+	}
 
-			if ReserveSet {
-				//reservations set
-				Info.Printf("LUN: %04.f LDEV: %s reservations: %s", ParsedLunsMap["lun"].(float64), LdevString, ReserveString)
-			} else {
-				//No reservations set
-				Info.Printf("LUN: %04.f LDEV: %s reservations: none", ParsedLunsMap["lun"].(float64), LdevString)
-			}
+	//documents get the compression and the tiers of the HDT pools as well
+	if OutputDocument(p) {
+		OutData = PoolsCompressionFormat(Pools, p)
+		p.ReportName = "compression"
+		if len(OutData) > 0 && OutputListFormat(OutData, p) {
+			Warning.Println("The function 'OutputListFormat' returned an Error.")
 		}
 
+		OutData = PoolTiersFormat(Pools, p)
+		p.ReportName = "tiers"
+		if len(OutData) > 0 && OutputListFormat(OutData, p) {
+			Warning.Println("The function 'OutputListFormat' returned an Error.")
+		}
 	}
 
+	//influx gets the line protocol of the pool metrics
+	if p.OutputStyle == "influx" {
+		var Storage StorageInfo
+		Storage, State = StorageInfoGet(p)
+		if OutputInflux(PoolsInfluxFormat(Storage, Pools, TimeStart), p) {
+			Warning.Println("The function 'OutputInflux' returned an Error.")
+			InfluxState = true
+		}
+	}
+
+	//the html report shows the trends of the snapshots
+	if p.OutputStyle == "html" && p.SnapshotDir != "" {
+		if HTMLPoolTrends(Pools, p) {
+			Warning.Println("The function 'HTMLPoolTrends' returned an Error.")
+		}
+	}
+
+	Info.Println("Get Pool information end")
+
 	TimeEnd := time.Now()
 	TimeDiff := TimeEnd.Sub(TimeStart)
-	Debug.Println("Function 'LunsGetReserve' - Elapsed time ", TimeDiff)
-	Debug.Println("Function 'LunsGetReserve' return values State:", State)
-	Debug.Println("Function 'LunsGetReserve' end")
-
-	Verbose.Println("Get the LUNs Information end")
+	Debug.Println("Function 'PoolsGet' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'PoolsGet' return values State:", State)
+	Debug.Println("Function 'PoolsGet' end")
 
-	//state to OK
-	State = false
-	//return the choosen "storageDeviceId" and its state
+	//state to OK unless the line protocol cannot be written to InfluxDB
+	State = InfluxState
 	return "", State
 }
 
-//PoolsGet is used to get all pool information
-//return value (string) is the version used and the status of the request. if an error happened the state is true. Otherwise false.
+//PoolsListGet is used to get all pool information and to calculate the pool values
+//return value ([]PoolInfo) are all pools and the status of the request. if an error happened the state is true. Otherwise false.
 //The function stops with exit status 40 ("JSON parsing error (Unmarshal function threw an error).")
 //The function stops with exit status 41 ("JSON parsing error (Return Format is not correct).")
-//example: StorageRestAPIVersionGet("https", "443", "10.0.0.1", "c0492b4c-165d-4052-87e2-27053023e29f", "834000470018")
-func PoolsGet(p Params) (string, bool) {
-	Debug.Println("Function 'PoolsGet' start.")
+//example: PoolsListGet(p)
+func PoolsListGet(p Params) ([]PoolInfo, bool) {
+	Debug.Println("Function 'PoolsListGet' start.")
 	//start timer
 	TimeStart := time.Now()
 
@@ -642,7 +1396,6 @@ func PoolsGet(p Params) (string, bool) {
 	var PoolsString string
 	PoolsString = "/pools?detailInfoType=FMC"
 
-	Info.Println("Get Pool information start")
 	Verbose.Println("Get general information of all Pools start")
 
 	p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + ConfigurationManagerString + p.StorageDeviceID + PoolsString
@@ -674,8 +1427,8 @@ func PoolsGet(p Params) (string, bool) {
 	Debug.Println("Number of Pools", len(JSONUnmarshalOut["data"].([]interface{})))
 	Verbose.Println("Get general information of all Pools end")
 
-	//add empty string of strings to collect all pool data to output
-	OutData := [][]string{}
+	//all pools with the calculated values
+	var Pools []PoolInfo
 
 	for Key1, Value1 := range JSONUnmarshalOut["data"].([]interface{}) {
 		//Verbose.Println("Key: "+strconv.Itoa(Key1), "Value: ", Value1)
@@ -706,6 +1459,37 @@ func PoolsGet(p Params) (string, bool) {
 			Debug.Println("Element: 'availablePhysicalVolumeCapacity' does not exist. Took 'availableVolumeCapacity' (" + strconv.FormatFloat(availablePhysicalVolumeCapacity/Mb2Gb, 'f', p.RoundPrecision, 64) + "MiB) instead.")
 		}
 
+		//values used for calculations
+		PoolElement.PoolType = ElementString(ParsedMap, "poolType")
+		PoolElement.PoolStatus = ElementString(ParsedMap, "poolStatus")
+		PoolElement.FMC = ParsedMap["usedFMCPoolVolumesCapacity"] != nil
+		PoolElement.TotalPoolCapacity = ElementFloat64(ParsedMap, "totalPoolCapacity")
+		PoolElement.PhysicalCapacityTotal = ElementFloat64(ParsedMap, "totalPoolCapacity")
+		if PoolElement.FMC {
+			PoolElement.PhysicalCapacityTotal = ElementFloat64(ParsedMap, "totalPhysicalCapacity")
+		}
+		PoolElement.PhysicalCapacityFree = availablePhysicalVolumeCapacity
+		PoolElement.TotalLocatedCapacity = ElementFloat64(ParsedMap, "totalLocatedCapacity")
+		PoolElement.UsedCapacityRate = ElementFloat64(ParsedMap, "usedCapacityRate")
+		PoolElement.WarningThreshold = ElementFloat64(ParsedMap, "warningThreshold")
+		PoolElement.DepletionThreshold = ElementFloat64(ParsedMap, "depletionThreshold")
+		//capacity used by thin image snapshots. older versions only know HTI pools where all used capacity is snapshot data
+		PoolElement.SnapshotUsedCapacity = ElementFloat64(ParsedMap, "snapshotUsedCapacity")
+		if ParsedMap["snapshotUsedCapacity"] == nil && PoolElement.PoolType == "HTI" {
+			PoolElement.SnapshotUsedCapacity = PoolElement.TotalPoolCapacity - availablePhysicalVolumeCapacity
+		}
+		//tiers of HDT pools
+		PoolElement.Tiers = nil
+		if Tiers, ok := ParsedMap["tiers"].([]interface{}); ok {
+			for _, Tier := range Tiers {
+				TierMap, ok := Tier.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				PoolElement.Tiers = append(PoolElement.Tiers, PoolTierInfo{TierNumber: int(ElementFloat64(TierMap, "tierNumber")), TotalCapacity: ElementFloat64(TierMap, "tierTotalCapacity"), UsedCapacity: ElementFloat64(TierMap, "tierUsedCapacity")})
+			}
+		}
+
 		//is it a pool containing FMC?
 		if ParsedMap["usedFMCPoolVolumesCapacity"] != nil {
 
@@ -759,18 +1543,7 @@ func PoolsGet(p Params) (string, bool) {
 				CompressionRatioTotal = RoundFloat64(CompressionRatioTotal, p.RoundPrecision)
 				PoolElement.EffectiveGBFree = strconv.FormatFloat(((availablePhysicalVolumeCapacity / Mb2Gb) * CompressionRatioTotal), 'f', p.RoundPrecision, 64)
 
-				//select the output type
-				// at the beginning it is checked that only these two values pass the script
-				switch p.OutputStyle {
-				case "stdout":
-					OutData, State = PoolInfoFormatTable(PoolElement, p)
-					if OutputStandardFormat(OutData, p) {
-						Warning.Println("The function 'OutputStandardFormat' returned an Error.")
-					}
-				case "csv":
-					OutData, State = PoolInfoFormatCSV(OutData, PoolElement, p)
-					//As all Pools have to be listed in one Table the output function is called at the end of the function
-				}
+				Pools = append(Pools, PoolElement)
 
 			} else {
 				//-------------------------------------
@@ -815,18 +1588,7 @@ func PoolsGet(p Params) (string, bool) {
 				CompressionRatioTotal = RoundFloat64(CompressionRatioTotal, p.RoundPrecision)
 				PoolElement.EffectiveGBFree = strconv.FormatFloat(((availablePhysicalVolumeCapacity / Mb2Gb) * CompressionRatioTotal), 'f', p.RoundPrecision, 64)
 
-				//select the output type
-				// at the beginning it is checked that only these two values pass the script
-				switch p.OutputStyle {
-				case "stdout":
-					OutData, State = PoolInfoFormatTable(PoolElement, p)
-					if OutputStandardFormat(OutData, p) {
-						Warning.Println("The function 'OutputStandardFormat' returned an Error.")
-					}
-				case "csv":
-					OutData, State = PoolInfoFormatCSV(OutData, PoolElement, p)
-					//As all Pools have to be listed in one Table the output function is called at the end of the function
-				}
+				Pools = append(Pools, PoolElement)
 			}
 
 		} else {
@@ -884,18 +1646,7 @@ func PoolsGet(p Params) (string, bool) {
 				//VirtualMappedGBFree := strconv.FormatFloat(RoundFloat64(ParsedMap["totalPoolCapacity"].(float64)-availablePhysicalVolumeCapacity, p.RoundPrecision)*
 				//(LdevMappedUsedArray[0]/(ParsedMap["totalPoolCapacity"].(float64)-RoundFloat64(availablePhysicalVolumeCapacity, p.RoundPrecision)))/1024, 'f', p.RoundPrecision, 64)
 
-				//select the output type
-				// at the beginning it is checked that only these two values pass the script
-				switch p.OutputStyle {
-				case "stdout":
-					OutData, State = PoolInfoFormatTable(PoolElement, p)
-					if OutputStandardFormat(OutData, p) {
-						Warning.Println("The function 'OutputStandardFormat' returned an Error.")
-					}
-				case "csv":
-					OutData, State = PoolInfoFormatCSV(OutData, PoolElement, p)
-					//As all Pools have to be listed in one Table the output function is called at the end of the function
-				}
+				Pools = append(Pools, PoolElement)
 
 			}
 
@@ -946,18 +1697,7 @@ func PoolsGet(p Params) (string, bool) {
 				//PoolElement.EffectiveGBFree = strconv.FormatFloat(((ParsedMap["availablePhysicalVolumeCapacity"].(float64)/1024)*CompressionRatioTotal), 'f', p.RoundPrecision, 64)
 				PoolElement.EffectiveGBFree = PoolElement.availablePhysicalVolumeCapacity
 
-				//select the output type
-				// at the beginning it is checked that only these two values pass the script
-				switch p.OutputStyle {
-				case "stdout":
-					OutData, State = PoolInfoFormatTable(PoolElement, p)
-					if OutputStandardFormat(OutData, p) {
-						Warning.Println("The function 'OutputStandardFormat' returned an Error.")
-					}
-				case "csv":
-					OutData, State = PoolInfoFormatCSV(OutData, PoolElement, p)
-					//As all Pools have to be listed in one Table the output function is called at the end of the function
-				}
+				Pools = append(Pools, PoolElement)
 
 			}
 
@@ -1008,18 +1748,7 @@ func PoolsGet(p Params) (string, bool) {
 				//PoolElement.EffectiveGBFree = strconv.FormatFloat(((ParsedMap["availablePhysicalVolumeCapacity"].(float64)/1024)*CompressionRatioTotal), 'f', p.RoundPrecision, 64)
 				PoolElement.EffectiveGBFree = PoolElement.availablePhysicalVolumeCapacity
 
-				//select the output type
-				// at the beginning it is checked that only these two values pass the script
-				switch p.OutputStyle {
-				case "stdout":
-					OutData, State = PoolInfoFormatTable(PoolElement, p)
-					if OutputStandardFormat(OutData, p) {
-						Warning.Println("The function 'OutputStandardFormat' returned an Error.")
-					}
-				case "csv":
-					OutData, State = PoolInfoFormatCSV(OutData, PoolElement, p)
-					//As all Pools have to be listed in one Table the output function is called at the end of the function
-				}
+				Pools = append(Pools, PoolElement)
 
 			}
 
@@ -1033,108 +1762,55 @@ func PoolsGet(p Params) (string, bool) {
 
 	}
 
-	// CSV output OutData
-	if p.OutputStyle == "csv" {
-		if OutputStandardFormat(OutData, p) {
-			Warning.Println("The function 'OutputStandardFormat' returned an Error.")
-		}
+	//compression ratio total as number for calculations
+	for i := range Pools {
+		Pools[i].CompressionRatio, _ = strconv.ParseFloat(Pools[i].CompressionRatioTotal, 64)
 	}
 
-	Info.Println("Get Pool information end")
+	Verbose.Println("Get Pool information completed")
 
 	TimeEnd := time.Now()
 	TimeDiff := TimeEnd.Sub(TimeStart)
-	Debug.Println("Function 'PoolsGet' - Elapsed time ", TimeDiff)
-	Debug.Println("Function 'PoolsGet' return values State:", State)
-	Debug.Println("Function 'PoolsGet' end")
+	Debug.Println("Function 'PoolsListGet' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'PoolsListGet' return values State:", State)
+	Debug.Println("Function 'PoolsListGet' end")
 
 	//state to OK
 	State = false
-	return "", State
-
+	return Pools, State
 }
 
 //LdevCapSumGet is used to get the sum of all mapped LDEV Capacity [MB] and the sum of all used capacity of all mapped LDEVs [MB]
 //return value (slice of two values ("sum of mapped capacity" and "sum of used capacity") (float64)) and the status of the request. if an error happened the state is true. Otherwise false.
-//The function stops with exit status 50 ("JSON parsing error ("Unmarshal function threw an error).")
-//The function stops with exit status 51 ("JSON parsing error (Return Format is not correct).")
-//example: StorageRestAPIVersionGet("https", "443", "10.0.0.1", "c0492b4c-165d-4052-87e2-27053023e29f", "834000470018")
+//The LDEVs are read page by page with LdevsListGet. A single request with count=MaxElementCount would truncate large arrays.
+//example: LdevCapSumGet(p, 20)
 func LdevCapSumGet(p Params, PoolID float64) ([2]float64, bool) {
 	//initial state is true that means NOK
 	State := true
 
 	Info.Println("Get all LDEVs to calculate the mapped and used capacity")
 
-	var Out string
-	Out = ""
-
 	var MappedCapacity float64
 	var UsedCapacity float64
 	var SliceReturn [2]float64
 	SliceReturn[0] = 0
 	SliceReturn[1] = 0
-	//initialize an array of two values with initial value 0
-	//SliceReturn = make{[]float64, 0, 0}
-
-	var ConfigurationManagerString string
-	ConfigurationManagerString = "/ConfigurationManager/v1/objects/storages/"
-	var LdevsString string
-	LdevsString = "/ldevs?ldevOption=dpVolume&poolId="
-	var ElementCountString string
-	ElementCountString = "&count="
-
-	//http://10.70.5.104/ConfigurationManager/v1/objects/storages/834000470018/ldevs?ldevOption=dpVolume&poolId=20&count=16384
-	p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + ConfigurationManagerString + p.StorageDeviceID + LdevsString + strconv.FormatFloat(PoolID, 'f', 0, 64) + ElementCountString + strconv.FormatInt(p.MaxElementCount, 10)
-	Verbose.Println(p.URL)
-	p.RequestType = "GET"
-	Out = HTTPRequest(p)
-
-	//Testdata
-	//Pool 20
-	//output = `{    "data": [{        "ldevId": 2816,        "clprId": 0,        "emulationType": "OPEN-V-CVS",        "byteFormatCapacity": "1.00 T",        "blockCapacity": 2147483648,        "numOfPorts": 2,        "ports": [{            "portId": "CL1-E",            "hostGroupNumber": 1,            "hostGroupName": "CB500_blade3_lpa",            "lun": 1        }, {            "portId": "CL2-E",            "hostGroupNumber": 1,            "hostGroupName": "CB500_blade3_lpa",            "lun": 1        }],        "attributes": ["CVS", "HDP"],        "label": "FMC_HDP_TEST",        "status": "NML",        "mpBladeId": 2,        "ssid": "000F",        "poolId": 20,        "numOfUsedBlock": 533729280,        "isFullAllocationEnabled": false,        "resourceGroupId": 0,        "dataReductionStatus": "DISABLED",        "dataReductionMode": "disabled"    }, {        "ldevId": 2817,        "clprId": 0,        "emulationType": "OPEN-V-CVS",        "byteFormatCapacity": "1.00 T",        "blockCapacity": 2147483648,        "numOfPorts": 2,        "ports": [{            "portId": "CL1-E",            "hostGroupNumber": 1,            "hostGroupName": "CB500_blade3_lpa",            "lun": 2        }, {            "portId": "CL2-E",            "hostGroupNumber": 1,            "hostGroupName": "CB500_blade3_lpa",            "lun": 2        }],        "attributes": ["CVS", "HDP"],        "label": "FMC_HDP_TEST",        "status": "NML",        "mpBladeId": 0,        "ssid": "000F",        "poolId": 20,        "numOfUsedBlock": 800378880,        "isFullAllocationEnabled": false,        "resourceGroupId": 0,        "dataReductionStatus": "DISABLED",        "dataReductionMode": "disabled"    }, {        "ldevId": 2818,        "clprId": 0,        "emulationType": "OPEN-V-CVS",        "byteFormatCapacity": "1.00 T",        "blockCapacity": 2147483648,        "numOfPorts": 2,        "ports": [{            "portId": "CL1-E",            "hostGroupNumber": 1,            "hostGroupName": "CB500_blade3_lpa",            "lun": 4        }, {            "portId": "CL2-E",            "hostGroupNumber": 1,            "hostGroupName": "CB500_blade3_lpa",            "lun": 4        }],        "attributes": ["CVS", "HDP"],        "label": "FMC_HDP_TEST",        "status": "NML",        "mpBladeId": 2,        "ssid": "000F",        "poolId": 20,        "numOfUsedBlock": 106831872,        "isFullAllocationEnabled": false,        "resourceGroupId": 0,        "dataReductionStatus": "DISABLED",        "dataReductionMode": "disabled"    }, {        "ldevId": 2819,        "clprId": 0,        "emulationType": "OPEN-V-CVS",        "byteFormatCapacity": "1.00 T",        "blockCapacity": 2147483648,        "numOfPorts": 2,        "ports": [{            "portId": "CL1-E",            "hostGroupNumber": 1,            "hostGroupName": "CB500_blade3_lpa",            "lun": 5        }, {            "portId": "CL2-E",            "hostGroupNumber": 1,            "hostGroupName": "CB500_blade3_lpa",            "lun": 5        }],        "attributes": ["CVS", "HDP"],        "label": "FMC_HDP_TEST",        "status": "NML",        "mpBladeId": 0,        "ssid": "000F",        "poolId": 20,        "numOfUsedBlock": 1376256,        "isFullAllocationEnabled": false,        "resourceGroupId": 0,        "dataReductionStatus": "DISABLED",        "dataReductionMode": "disabled"    }, {        "ldevId": 2820,        "clprId": 0,        "emulationType": "OPEN-V-CVS",        "byteFormatCapacity": "1.00 T",        "blockCapacity": 2147483648,        "numOfPorts": 2,        "ports": [{            "portId": "CL1-E",            "hostGroupNumber": 1,            "hostGroupName": "CB500_blade3_lpa",            "lun": 6        }, {            "portId": "CL2-E",            "hostGroupNumber": 1,            "hostGroupName": "CB500_blade3_lpa",            "lun": 6        }],        "attributes": ["CVS", "HDP"],        "label": "FMC_HDP_TEST",        "status": "NML",        "mpBladeId": 2,        "ssid": "000F",        "poolId": 20,        "numOfUsedBlock": 518246400,        "isFullAllocationEnabled": false,        "resourceGroupId": 0,        "dataReductionStatus": "DISABLED",        "dataReductionMode": "disabled"    }, {        "ldevId": 2821,        "clprId": 0,        "emulationType": "OPEN-V-CVS",        "byteFormatCapacity": "1.00 T",        "blockCapacity": 2147483648,        "numOfPorts": 2,        "ports": [{            "portId": "CL1-E",            "hostGroupNumber": 1,            "hostGroupName": "CB500_blade3_lpa",            "lun": 7        }, {            "portId": "CL2-E",            "hostGroupNumber": 1,            "hostGroupName": "CB500_blade3_lpa",            "lun": 7        }],        "attributes": ["CVS", "HDP"],        "label": "FMC_HDP_TEST",        "status": "NML",        "mpBladeId": 0,        "ssid": "000F",        "poolId": 20,        "numOfUsedBlock": 544825344,        "isFullAllocationEnabled": false,        "resourceGroupId": 0,        "dataReductionStatus": "DISABLED",        "dataReductionMode": "disabled"    }]}`
-	//Pool
-	//Verbose.Println("RAW data: " + output)
-
-	//is the string "data" in the output
-	if CheckIsInString(Out, p.DataElement) {
-		Verbose.Println(Out)
-	}
-
-	var JSONUnmarshalOut map[string]interface{}
-	JSONUnmarshalOut, State = JSONUnmarshal(Out)
-	Verbose.Println("JSON Unmarshal:", JSONUnmarshalOut)
-
-	// all responses from hitachi rest api calls answer with only one element called data "{ "data": [{"
-	if len(JSONUnmarshalOut) != 1 {
-		//this should never happens
-		//at hitachi the response always starts with "{ "data": [{"
-		Error.Println("JSON parsing error (Return Format is not correct)")
-		os.Exit(51)
-	}
 
-	Verbose.Println("Number of LDEVs:", len(JSONUnmarshalOut["data"].([]interface{})))
-	for Key1, Value1 := range JSONUnmarshalOut["data"].([]interface{}) {
-		//Verbose.Println("Key: "+strconv.Itoa(Key1), "Value: ", Value1)
-		ParsedMap := Value1.(map[string]interface{})
+	//only the dp volumes of the pool
+	p.PoolID = int(PoolID)
 
-		//Verbose.Println("Element:", key1)
+	var Ldevs []LdevInfo
+	Ldevs, State = LdevsListGet(p)
 
+	Verbose.Println("Number of LDEVs:", len(Ldevs))
+	for Key1, Ldev := range Ldevs {
 		//mapped capacity
-		if ParsedMap["blockCapacity"] != nil {
-			if w, ok := ParsedMap["blockCapacity"].(float64); ok {
-				Verbose.Println("Element: ", Key1, "Mapped Capacity [MB]: ", w*512/1024/1024, " in Blocks -> ", w)
-				MappedCapacity = MappedCapacity + w*512/1024/1024
-			}
-		}
+		Verbose.Println("Element: ", Key1, "Mapped Capacity [MB]: ", Ldev.Capacity)
+		MappedCapacity = MappedCapacity + Ldev.Capacity
 
 		//used capacity
-		if ParsedMap["numOfUsedBlock"] != nil {
-			if w, ok := ParsedMap["numOfUsedBlock"].(float64); ok {
-				Verbose.Println("Element: ", Key1, "Used Capacity [MB]: ", w*512/1024/1024, " in Blocks -> ", w)
-				UsedCapacity = UsedCapacity + w*512/1024/1024
-			}
-		}
-
+		Verbose.Println("Element: ", Key1, "Used Capacity [MB]: ", Ldev.UsedCapacity)
+		UsedCapacity = UsedCapacity + Ldev.UsedCapacity
 	}
 	// first value in array is the mapped capacity in [MB]
 	SliceReturn[0] = MappedCapacity
@@ -1254,6 +1930,19 @@ func PoolInfoFormatCSV(OutData [][]string, PoolDataSet PoolInfo, p Params) ([][]
 	// ("availablePhysicalFMCPoolVolumesCapacity" - "usedPhysicalFMCPoolVolumesCapacity") *  ("*mapped capacity*" / "usedPhysicalFMCPoolVolumesCapacity") / 1024
 	//TempData = append(TempData, []string{"Virtual/Mapped GB FREE [GB]", VirtualMappedGBFree})
 
+	//documents get the utilization and the thresholds to highlight the pools
+	if OutputDocument(p) {
+		var Utilization float64
+		Utilization = 0
+		if PoolDataSet.PhysicalCapacityTotal > 0 {
+			Utilization = (PoolDataSet.PhysicalCapacityTotal - PoolDataSet.PhysicalCapacityFree) / PoolDataSet.PhysicalCapacityTotal * 100
+		}
+		TempData = append(TempData, []string{"Pool type(string)", PoolDataSet.PoolType})
+		TempData = append(TempData, []string{"Utilization [%](float64)", strconv.FormatFloat(Utilization, 'f', p.RoundPrecision, 64)})
+		TempData = append(TempData, []string{"Warning threshold [%](float64)", strconv.FormatFloat(PoolDataSet.WarningThreshold, 'f', 0, 64)})
+		TempData = append(TempData, []string{"Depletion threshold [%](float64)", strconv.FormatFloat(PoolDataSet.DepletionThreshold, 'f', 0, 64)})
+	}
+
 	//table end line
 	TempData = append(TempData, []string{p.ElementStringEnd})
 
@@ -1287,7 +1976,23 @@ func OutputStandardFormat(Data [][]string, p Params) bool {
 	case p.OutputStyle == "csv":
 		Debug.Print("OutputStype: " + p.OutputStyle)
 		Debug.Print("Data: ", Data)
-		State = OutputCSV(Data, p.ElementStringStart, p.ElementStringEnd, p.CSVString)
+		State = OutputCSV(Data, p.ElementStringStart, p.ElementStringEnd, p.CSVString, p.ReportName)
+	case p.OutputStyle == "json":
+		Debug.Print("OutputStype: " + p.OutputStyle)
+		Debug.Print("Data: ", Data)
+		State = OutputJSON(Data, p.ElementStringStart, p.ElementStringEnd, p.ReportName)
+	case p.OutputStyle == "xlsx":
+		Debug.Print("OutputStype: " + p.OutputStyle)
+		Debug.Print("Data: ", Data)
+		State = OutputXLSX(Data, p)
+	case p.OutputStyle == "html":
+		Debug.Print("OutputStype: " + p.OutputStyle)
+		Debug.Print("Data: ", Data)
+		State = OutputHTML(Data, p)
+	case p.OutputStyle == "markdown":
+		Debug.Print("OutputStype: " + p.OutputStyle)
+		Debug.Print("Data: ", Data)
+		State = OutputMarkdown(Data, p)
 	default:
 		Warning.Print("Output Format (" + p.OutputStyle + ") invalid. stdout taken instead.")
 		Debug.Print("OutputStype: " + p.OutputStyle)
@@ -1362,8 +2067,98 @@ func OutputTable(Data [][]string, ElementStringStart string, ElementStringEnd st
 	return State
 }
 
+//OutputListFormat modyfies the output values of list reports (one row per element) to Standard Format
+//stdout shows all elements in one table with the descriptors as header. csv is the same as in OutputStandardFormat.
+func OutputListFormat(Data [][]string, p Params) bool {
+	Debug.Println("Function 'OutputListFormat' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//false -> OK
+	State := false
+
+	//select output type
+	switch {
+	case p.OutputStyle == "csv":
+		Debug.Print("OutputStype: " + p.OutputStyle)
+		State = OutputCSV(Data, p.ElementStringStart, p.ElementStringEnd, p.CSVString, p.ReportName)
+	case p.OutputStyle == "json":
+		Debug.Print("OutputStype: " + p.OutputStyle)
+		State = OutputJSON(Data, p.ElementStringStart, p.ElementStringEnd, p.ReportName)
+	case p.OutputStyle == "xlsx":
+		Debug.Print("OutputStype: " + p.OutputStyle)
+		State = OutputXLSX(Data, p)
+	case p.OutputStyle == "html":
+		Debug.Print("OutputStype: " + p.OutputStyle)
+		State = OutputHTML(Data, p)
+	case p.OutputStyle == "markdown":
+		Debug.Print("OutputStype: " + p.OutputStyle)
+		State = OutputMarkdown(Data, p)
+	default:
+		Debug.Print("OutputStype: " + p.OutputStyle)
+		State = OutputTableList(Data, p.ElementStringStart, p.ElementStringEnd)
+	}
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'OutputListFormat' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'OutputListFormat' ended.")
+	return State
+}
+
+//OutputTableList outputs the data to the command line as one table. Every element is one row.
+//The descriptors of the first element are used as header.
+func OutputTableList(Data [][]string, ElementStringStart string, ElementStringEnd string) bool {
+	Debug.Println("Function 'OutputTableList' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//true -> NOK
+	//false -> OK
+	State := false
+
+	var Header []string
+	var Row []string
+	var HeaderDone bool
+	HeaderDone = false
+
+	// if no data is available skip output
+	if len(Data) == 0 {
+		Error.Println("No Data to output.")
+	} else {
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetAutoWrapText(false)
+		for i := 0; i < len(Data); i++ {
+			switch Data[i][0] {
+			case ElementStringStart:
+				Row = []string{}
+			case ElementStringEnd:
+				if !HeaderDone {
+					table.SetHeader(Header)
+					HeaderDone = true
+				}
+				table.Append(Row)
+			default:
+				if !HeaderDone {
+					Header = append(Header, Data[i][0])
+				}
+				Row = append(Row, Data[i][1])
+			}
+		}
+		table.Render() // Send output
+	}
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'OutputTableList' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'OutputTableList' return values State:", State)
+	Debug.Println("Function 'OutputTableList' ended.")
+	return State
+}
+
 //OutputCSV outputs the data to a comma separated file in the same directory.
-func OutputCSV(Data [][]string, ElementStringStart string, ElementStringEnd string, SeparatorString string) bool {
+//Between OutputReportsStart and OutputReportsEnd the block starts with the line "# <report>" (ReportName).
+func OutputCSV(Data [][]string, ElementStringStart string, ElementStringEnd string, SeparatorString string, ReportName string) bool {
 	Debug.Println("Function 'OutputCSV' started.")
 	//start timer
 	TimeStart := time.Now()
@@ -1403,6 +2198,10 @@ func OutputCSV(Data [][]string, ElementStringStart string, ElementStringEnd stri
 				break
 			}
 		}
+		//report line of a type with several reports
+		if OutputReports != nil && ReportName != "" {
+			fmt.Println("# " + ReportName)
+		}
 		//descriptor line
 		fmt.Println(Descriptor)
 
@@ -1413,9 +2212,9 @@ func OutputCSV(Data [][]string, ElementStringStart string, ElementStringEnd stri
 			if Data[i][0] != ElementStringEnd && Data[i][0] != ElementStringStart {
 				//Debug.Println("Descriptor - Data["+strconv.Itoa(i)+"]:", Data[i][1])
 				if Values == "" {
-					Values = Data[i][1]
+					Values = CSVField(Data[i][1], SeparatorString)
 				} else {
-					Values = Values + SeparatorString + Data[i][1]
+					Values = Values + SeparatorString + CSVField(Data[i][1], SeparatorString)
 				}
 			} else {
 				if Data[i][0] == ElementStringEnd {
@@ -1435,6 +2234,86 @@ func OutputCSV(Data [][]string, ElementStringStart string, ElementStringEnd stri
 	return State
 }
 
+//CSVField quotes a value that contains the separator, a quote or a line break (RFC 4180). quotes in the value are doubled.
+func CSVField(Value string, SeparatorString string) string {
+	if !strings.Contains(Value, SeparatorString) && !strings.ContainsAny(Value, "\"\r\n") {
+		return Value
+	}
+	return "\"" + strings.Replace(Value, "\"", "\"\"", -1) + "\""
+}
+
+//OutputJSON outputs the data as JSON array. Every element is one object.
+//The type in the descriptor (ex: "Capacity [GB](float64)") is removed from the key and float64 values are output as numbers.
+//float64 values that are not a number (ex: "-", "n/a") are output as null.
+//Between OutputReportsStart and OutputReportsEnd the array is collected with the report (ReportName) as key and output by OutputReportsEnd.
+func OutputJSON(Data [][]string, ElementStringStart string, ElementStringEnd string, ReportName string) bool {
+	Debug.Println("Function 'OutputJSON' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//true -> NOK
+	//false -> OK
+	State := false
+
+	//RFC3339 time format used as in the csv output
+	const TimeformatString = "Time(RFC3339)"
+	var TimeFormatValue string
+	TimeFormatValue = TimeStart.Format(time.RFC3339)
+
+	//the keys are kept in the order of the descriptors
+	var Elements []string
+	var Element []string
+
+	// if no data is available skip output. a collected report is an empty array
+	if len(Data) == 0 && OutputReports != nil && ReportName != "" {
+		KeyJSON, _ := json.Marshal(ReportName)
+		OutputReports = append(OutputReports, string(KeyJSON)+":[]")
+	} else if len(Data) == 0 {
+		Error.Println("No Data to output.")
+	} else {
+		for i := 0; i < len(Data); i++ {
+			switch Data[i][0] {
+			case ElementStringStart:
+				TimeJSON, _ := json.Marshal(TimeFormatValue)
+				Element = []string{"\"" + TimeformatString + "\":" + string(TimeJSON)}
+			case ElementStringEnd:
+				Elements = append(Elements, "{"+strings.Join(Element, ",")+"}")
+			default:
+				Key, Type := HeaderSplit(Data[i][0])
+				if Type == "" {
+					Type = "string"
+				}
+				KeyJSON, _ := json.Marshal(Key)
+				var Value interface{}
+				Value = Data[i][1]
+				if Type == "float64" {
+					Number, err := strconv.ParseFloat(Data[i][1], 64)
+					if err != nil {
+						Value = nil
+					} else {
+						Value = Number
+					}
+				}
+				ValueJSON, _ := json.Marshal(Value)
+				Element = append(Element, string(KeyJSON)+":"+string(ValueJSON))
+			}
+		}
+		if OutputReports != nil && ReportName != "" {
+			KeyJSON, _ := json.Marshal(ReportName)
+			OutputReports = append(OutputReports, string(KeyJSON)+":["+strings.Join(Elements, ",\n")+"]")
+		} else {
+			fmt.Println("[" + strings.Join(Elements, ",\n") + "]")
+		}
+	}
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'OutputJSON' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'OutputJSON' return values State:", State)
+	Debug.Println("Function 'OutputJSON' ended.")
+	return State
+}
+
 //StorageRestAPIVersionGet is used to get the RestAPI version
 //return value (string) is the version used and the status of the request. if an error happened the state is true. Otherwise false.
 //The function stops with exit status 30 ("JSON parsing error."")
@@ -1681,7 +2560,6 @@ func HCSRegisterStorage(p Params, StorageSerialSlice []float64, StorageDeviceIDS
 	const SVPIPElement string = "svpIp"
 	const SerialNumberElement string = "serialNumber"
 	const ModelElement string = "model"
-	const StorageDeviceIDElement string = "storageDeviceId"
 
 	//JSON input
 	var SvpIP string
@@ -1690,8 +2568,6 @@ func HCSRegisterStorage(p Params, StorageSerialSlice []float64, StorageDeviceIDS
 	SerialNumber = ""
 	var Model string
 	Model = ""
-	var JSONInputString string
-	JSONInputString = ""
 	var StorageDeviceID string
 	StorageDeviceID = ""
 
@@ -1795,29 +2671,12 @@ func HCSRegisterStorage(p Params, StorageSerialSlice []float64, StorageDeviceIDS
 	} else {
 		//not existent
 		// register storage
-		//create put string
-		//doubleqouote in a string must look like \"
-		JSONInputString = "{\"" + SVPIPElement + "\":\"" + SvpIP + "\",\"" + SerialNumberElement + "\":" + SerialNumber + ",\"" + ModelElement + "\":\"" + Model + "\"}"
-		Debug.Println("JSON Request body:" + JSONInputString)
-		//curl -kv -H "Accept:application/json" -H "Content-Type:application/json" -u raidcom:raidcom -X POST --data-binary "@./g600.txt" https://10.70.4.84:23451/ConfigurationManager/v1/objects/storages
-
-		p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + "/ConfigurationManager/v1/objects/storages"
-		Debug.Println("URL: " + p.URL)
-		p.RequestType = "POST"
-		p.RequestBody = JSONInputString
-		Out = HTTPRequest(p)
-
-		//get data
-		//Verbose.Println("RAW data: " + output)
-		//is the storageDeviceId variable specified in the output
-		if CheckIsInString(Out, StorageDeviceIDElement) {
-			Debug.Println(Out)
+		Serial, _ := strconv.Atoi(SerialNumber)
+		Out, State = HCSStorageRegister(p, HCSStorageInfo{SvpIP: SvpIP, SerialNumber: Serial, Model: Model})
+		if State {
+			return Out, State
 		}
-
-		JSONUnmarshalOut, State = JSONUnmarshal(Out)
-		Debug.Println("JSON Unmarshal:", JSONUnmarshalOut)
-
-		StorageDeviceID = JSONUnmarshalOut[StorageDeviceIDElement].(string)
+		StorageDeviceID = Out
 	}
 
 	TimeEnd := time.Now()
@@ -1873,6 +2732,8 @@ func TokenGet(p Params) (string, float64, bool) {
 	p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + "/ConfigurationManager/v1/objects/storages/" + p.StorageDeviceID + "/sessions/"
 	Debug.Println("URL: " + p.URL)
 	p.RequestType = "POST"
+	//without -alivetime and -authtimeout the session gets the default timeouts of the storage
+	p.RequestBody = SessionRequestBody(p)
 	Out = HTTPRequest(p)
 
 	//is the Token variable specified in the output
@@ -1943,6 +2804,7 @@ func TokenDelete(p Params) (string, bool) {
 	Debug.Println("URL: " + p.URL)
 	//Verbose.Println("Token: " + Token)
 	p.RequestType = "DELETE"
+	p.RequestBody = `{"force": false}`
 	Out = HTTPRequest(p)
 
 	TimeEnd := time.Now()
@@ -1960,6 +2822,51 @@ func TokenDelete(p Params) (string, bool) {
 	return Out, State
 }
 
+//RestDataGet sends the request specified in the parameters and returns the elements of the "data" element
+//all responses from hitachi rest api list calls answer with only one element called data "{ "data": [{"
+//return value ([]interface{}) are the data elements. if an error happened the state is true. Otherwise false.
+//The function stops with the exit status passed ("JSON parsing error (Return Format is not correct).")
+//example: RestDataGet(p, 61)
+func RestDataGet(p Params, ExitStatus int) ([]interface{}, bool) {
+	Debug.Println("Function 'RestDataGet' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//initial state is true that means NOK
+	State := true
+
+	var Out string
+	Debug.Println(p.URL)
+	Out = HTTPRequest(p)
+
+	//is the string "data" in the output
+	if CheckIsInString(Out, p.DataElement) {
+		Error.Println(Out)
+	}
+
+	var JSONUnmarshalOut map[string]interface{}
+	JSONUnmarshalOut, State = JSONUnmarshal(Out)
+	Debug.Println("JSON Unmarshal:", JSONUnmarshalOut)
+
+	Data, ok := JSONUnmarshalOut[p.DataElement].([]interface{})
+	if len(JSONUnmarshalOut) != 1 || !ok {
+		//this should never happens
+		//at hitachi the response always starts with "{ "data": [{"
+		Error.Println("JSON parsing error (Return Format is not correct).")
+		os.Exit(ExitStatus)
+	}
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'RestDataGet' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'RestDataGet' return values number of elements:", len(Data))
+	Debug.Println("Function 'RestDataGet' ended.")
+
+	//state to OK
+	State = false
+	return Data, State
+}
+
 //JSONUnmarshal returns the Unmashalled JSON response
 func JSONUnmarshal(JSON string) (map[string]interface{}, bool) {
 	Debug.Println("Function 'JSONUnmarshal' started.")
@@ -2070,13 +2977,72 @@ func IsJSONUnmarshal(s string) bool {
 }
 
 //HTTPRequest is used to send a http(s) request with user and password or with a security token
-//return value (string) is the output body.
-//The function stops exit code 100 (The requesttype was set other than "GET, "POST", "DELETE".)
-//The function stops exit code 101 (A webrequest cannot be executed as no user nor a token was specified.)
-//The function stops exit code 102 ("A webrequest cannot be executed with an empty password.)
+//return value (string) is the output body. it is used by the reading requests. changing requests use HTTPRequestResult or JobRequest.
+//The function stops exit code 104 (The response has a http error status or contains an error message.)
+//The function stops with the exit codes of HTTPRequestStatus
 //example: HttpRequest("GET", protocol, url, username, passwd, token)
 func HTTPRequest(p Params) string {
 	Debug.Println("Function 'HTTPRequest' started.")
+
+	Body, Status, State := HTTPRequestResult(p)
+	if State {
+		//throw an error with the message
+		Error.Println("The request with the URL:\"" + p.URL + "\" with requesttype:\"" + p.RequestType + "\" ended with an error (" + Status + ").")
+		var parsed map[string]interface{}
+		if json.Unmarshal([]byte(Body), &parsed) != nil {
+			Error.Println("Error message: " + Body)
+			os.Exit(104)
+		}
+		for _, Element := range []string{"messageId", "message", "cause", "solution", "errorSource", "errorResource"} {
+			if Value := ElementString(parsed, Element); Value != "" {
+				Error.Println("Error " + Element + ": " + Value)
+			}
+		}
+		os.Exit(104)
+	}
+
+	Debug.Println("Function 'HTTPRequest' ended.")
+	return Body
+}
+
+//HTTPRequestResult sends a request like HTTPRequest but does not stop on an error. it is used by the changing requests so that the caller can record the failure, unlock the resources and close the session.
+//return values are the output body (string), the http status (string) and the state. an error is a http error status or a top level "message" element. if an error happened the state is true. Otherwise false.
+//example: HTTPRequestResult(p)
+func HTTPRequestResult(p Params) (string, string, bool) {
+	Debug.Println("Function 'HTTPRequestResult' started.")
+
+	//requests with a session always use the current session. it is replaced if it expired during the run
+	if p.Token != "" {
+		p.Token = SessionTokenCurrent(p.Token)
+	}
+
+	StatusCode, Status, Body := HTTPRequestStatus(p)
+
+	//the session expired (ex: a long scan without a request). a new session is created and the request is sent again
+	if StatusCode == 401 && p.Token != "" {
+		Warning.Println("The session expired during the request with the URL:\"" + p.URL + "\". A new session is created.")
+		p.Token = SessionRenew(p)
+		StatusCode, Status, Body = HTTPRequestStatus(p)
+	}
+
+	//jobs contain the "message" in the "error" element. they are checked in JobWait
+	var parsed map[string]interface{}
+	ParseErr := json.Unmarshal([]byte(Body), &parsed)
+	State := StatusCode >= 400 || (ParseErr == nil && parsed["message"] != nil)
+
+	Debug.Println("Function 'HTTPRequestResult' return values State:", State)
+	Debug.Println("Function 'HTTPRequestResult' ended.")
+	return Body, Status, State
+}
+
+//HTTPRequestStatus is used to send a http(s) request with user and password or with a security token
+//return values are the http status code (int), the http status (string) and the output body (string). error responses are returned too.
+//The function stops exit code 100 (The requesttype was set other than "GET", "POST", "PATCH", "PUT", "DELETE".)
+//The function stops exit code 101 (A webrequest cannot be executed as no user nor a token was specified.)
+//The function stops exit code 102 ("A webrequest cannot be executed with an empty password.)
+//example: HTTPRequestStatus(p)
+func HTTPRequestStatus(p Params) (int, string, string) {
+	Debug.Println("Function 'HTTPRequestStatus' started.")
 	//start timer
 	TimeStart := time.Now()
 
@@ -2099,9 +3065,11 @@ func HTTPRequest(p Params) string {
 		Error.Println(Out)
 	}
 
-	var RequesttypGet, RequesttypPost, RequesttypDelete string
+	var RequesttypGet, RequesttypPost, RequesttypPatch, RequesttypPut, RequesttypDelete string
 	RequesttypGet = "GET"
 	RequesttypPost = "POST"
+	RequesttypPatch = "PATCH"
+	RequesttypPut = "PUT"
 	RequesttypDelete = "DELETE"
 
 	//skip unsecure certificate
@@ -2130,10 +3098,10 @@ func HTTPRequest(p Params) string {
 				os.Exit(101)
 			}
 		}
-	case RequesttypPost: //POST
+	case RequesttypPost, RequesttypPatch, RequesttypPut: //POST, PATCH, PUT
 		Debug.Println("Webrequest type: " + p.RequestType)
-		Debug.Println("Requestbody:" + p.RequestType)
-		//POST
+		Debug.Println("Requestbody:" + p.RequestBody)
+		//POST, PATCH, PUT
 		if p.RequestBody == "" {
 			req, err = http.NewRequest(p.RequestType, p.URL, nil)
 		} else {
@@ -2155,18 +3123,34 @@ func HTTPRequest(p Params) string {
 		}
 	case RequesttypDelete: //DELETE
 		Debug.Println("Webrequest type: " + p.RequestType)
+		Debug.Println("Requestbody:" + p.RequestBody)
 		//DELETE
-		jsonStr := []byte(`{"force": false}`)
-		req, err = http.NewRequest(p.RequestType, p.URL, bytes.NewBuffer(jsonStr))
-		if p.Token == "" {
-			Error.Println("A webrequest cannot be executed as no token was specified.")
-			//stops with exit code 102
-			os.Exit(102)
+		//the body is optional. ex: sessions are deleted with {"force": false}
+		if p.RequestBody == "" {
+			req, err = http.NewRequest(p.RequestType, p.URL, nil)
+		} else {
+			req, err = http.NewRequest(p.RequestType, p.URL, bytes.NewBufferString(p.RequestBody))
+		}
+		// prefer token if exists. the HCS Configuration Manager accepts user/password (ex: unregister a storage)
+		if p.Token != "" {
+			req.Header.Set("Authorization", "Session "+p.Token)
+			Debug.Println("Authorization: Session " + p.Token)
+		} else {
+			if p.Username != "" && p.Password != "" {
+				Debug.Println("Set basic authorization with user: " + p.Username)
+				req.SetBasicAuth(p.Username, p.Password)
+			} else {
+				Error.Println("A webrequest cannot be executed as no token or username/password was specified.")
+				//stops with exit code 102
+				os.Exit(102)
+			}
 		}
-		req.Header.Set("Authorization", "Session "+p.Token)
-		Debug.Println("Authorization: Session " + p.Token)
 	default: //OTHER
-		Error.Println("The requesttype must be 'GET', 'POST' or 'DELETE'. The specified requesttype is wrong(" + p.RequestType + ").")
+		Error.Println("The requesttype must be 'GET', 'POST', 'PATCH', 'PUT' or 'DELETE'. The specified requesttype is wrong(" + p.RequestType + ").")
+		os.Exit(100)
+	}
+	if err != nil {
+		Error.Println("The webrequest cannot be created ('" + p.URL + "'): " + err.Error())
 		os.Exit(100)
 	}
 
@@ -2191,28 +3175,13 @@ func HTTPRequest(p Params) string {
 	body, _ := ioutil.ReadAll(resp.Body)
 	Debug.Println("Response Body:", string(body))
 
-	if strings.Index(string(body), "message") != -1 {
-		//load the output in an unspecified interface as map of string
-		//already declared
-		var parsed map[string]interface{}
-		err := json.Unmarshal(body, &parsed)
-		if err != nil {
-			Error.Println("JSON parsing error (Unmarshal function threw an error).")
-			os.Exit(103)
-		}
-		//throw an error with the message
-		Error.Println("The request with the URL:\"" + p.URL + "\" with requesttype:\"" + p.RequestType + "\" ended with an error.")
-		Error.Println("Error message: " + parsed["message"].(string))
-		os.Exit(104)
-	}
-
 	TimeEnd := time.Now()
 	TimeDiff := TimeEnd.Sub(TimeStart)
-	Debug.Println("Function 'HTTPRequest' - Elapsed time ", TimeDiff)
-	Debug.Println("Function 'HTTPRequest' return values State:", State)
-	Debug.Println("Function 'HTTPRequest' ended.")
+	Debug.Println("Function 'HTTPRequestStatus' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'HTTPRequestStatus' return values State:", State)
+	Debug.Println("Function 'HTTPRequestStatus' ended.")
 
-	return string(body)
+	return resp.StatusCode, resp.Status, string(body)
 }
 
 //CheckProtocol is used to check if the protocol is http or https
@@ -2387,8 +3356,8 @@ func HelpOutput(Version string) {
 	fmt.Fprintf(os.Stderr, LineIn+"%s\n", os.Args[0])
 	fmt.Println()
 	fmt.Println("SYNOPSIS:")
-	fmt.Fprintf(os.Stderr, LineIn+"%s -user <username> -password <password> [-host <hostname/IP>] [-port <HttpRequestPortnumber>] [-type pool/reserve] [-verbose]\n", os.Args[0])
-	fmt.Fprintf(os.Stderr, LineIn+"%s --user <username> --password <password> [--host <hostname/IP>] [--port <HttpRequestPortnumber>] [--type pool/reserve] [--verbose]\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, LineIn+"%s -user <username> -password <password> [-host <hostname/IP>] [-port <HttpRequestPortnumber>] [-type pool/reserve/drive/ldev/orphan/chargeback/pool-consumers/local-replication/remote-replication/snapshot-prune/hcs-register/hcs-unregister/hcs-list/sessions/provision/lun-map/host-create/ldev-expand/ldev-delete/pool-expand-plan/pool-rebalance/snapshot/snapshot-diff/drift] [-poolid <poolID>] [-label <regex>] [-attribute <attribute>] [-groupby hostgroup/<regex>] [-tagfile <file>] [-snapshotdir <directory>] [-snapshotfrom <file> -snapshotto <file>] [-journalthreshold <percent>] [-retentionfile <file>] [-execute] [-svpip <IP> -serial <serial> -model <model>] [-storagefile <file>] [-sessionidle <minutes>] [-force] [-tokencache <file>] [-alivetime <seconds>] [-authtimeout <seconds>] [-capacity <size> -hostgroups <port:hostgroup,...>] [-datareduction <mode>] [-lun <number>] [-maxsubscription <percent>] [-mapfile <file>] [-progressfile <file>] [-hostfile <file>] [-ldevid <LDEV ID>] [-yes] [-targetfree <percent>] [-statefile <file>] [-jobtimeout <seconds>] [-output stdout/csv/json/xlsx/html/markdown/influx] [-outputfile <file>] [-influxurl <URL> -influxorg <organization> -influxbucket <bucket> -influxtoken <token>] [-influxinsecure] [-verbose]\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, LineIn+"%s --user <username> --password <password> [--host <hostname/IP>] [--port <HttpRequestPortnumber>] [--type pool/reserve/drive/ldev/orphan/chargeback/pool-consumers/local-replication/remote-replication/snapshot-prune/hcs-register/hcs-unregister/hcs-list/sessions/provision/lun-map/host-create/ldev-expand/ldev-delete/pool-expand-plan/pool-rebalance/snapshot/snapshot-diff/drift] [--poolid <poolID>] [--label <regex>] [--attribute <attribute>] [--groupby hostgroup/<regex>] [--tagfile <file>] [--snapshotdir <directory>] [--snapshotfrom <file> --snapshotto <file>] [--journalthreshold <percent>] [--retentionfile <file>] [--execute] [--svpip <IP> --serial <serial> --model <model>] [--storagefile <file>] [--sessionidle <minutes>] [--force] [--tokencache <file>] [--alivetime <seconds>] [--authtimeout <seconds>] [--capacity <size> --hostgroups <port:hostgroup,...>] [--datareduction <mode>] [--lun <number>] [--maxsubscription <percent>] [--mapfile <file>] [--progressfile <file>] [--hostfile <file>] [--ldevid <LDEV ID>] [--yes] [--targetfree <percent>] [--statefile <file>] [--jobtimeout <seconds>] [--output stdout/csv/json/xlsx/html/markdown/influx] [--outputfile <file>] [--influxurl <URL> --influxorg <organization> --influxbucket <bucket> --influxtoken <token>] [--influxinsecure] [--verbose]\n", os.Args[0])
 	fmt.Fprintf(os.Stderr, LineIn+"%s -h/-help\n", os.Args[0])
 	fmt.Fprintf(os.Stderr, LineIn+"%s --h/--help\n", os.Args[0])
 	fmt.Println()
@@ -2414,10 +3383,115 @@ func HelpOutput(Version string) {
 	fmt.Println(LineIn + SecondLineIn + "Port to be used to contact the host. The storage RestAPI uses 443 (https). The HCS Rest API uses 23451. (Optional) (default '443')")
 	//output option
 	fmt.Println(LineIn + "-output string")
-	fmt.Println(LineIn + SecondLineIn + "Specify the way you want to send the output to. Options are 'stdout' or 'csv'. 'stdout' sends the output to the command line. 'csv' create a file containing comma separated data. (Optional) (default 'stdout')")
+	fmt.Println(LineIn + SecondLineIn + "Specify the way you want to send the output to. Options are 'stdout', 'csv', 'json', 'xlsx', 'html', 'markdown' or 'influx'. 'stdout' sends the output to the command line. 'csv' create a file containing comma separated data. 'json' outputs every table as JSON array. Types with several reports (ex: 'remote-replication' with pairs, journals and quorumDisks) output one JSON object with the report as key and start every csv block with the line '# <report>'. 'xlsx' writes a workbook (-outputfile) with one sheet per report and numeric cells. The type 'pool' adds a summary sheet with the totals of the storage and a sheet with the tiers of the HDT pools and highlights the utilization at the warning and depletion threshold. The type 'reserve' adds a sheet with all LUNs. 'html' writes a static report (-outputfile) without external assets with one table per report. The type 'pool' adds the summary, the compression and the tiers of the HDT pools, shows the utilization as bars coloured by the thresholds and adds trend charts if the snapshot directory (-snapshotdir) contains pools (type 'snapshot'). 'markdown' outputs every report as GitHub flavoured markdown table after a header with the storage, the REST API version and the collection time. It gets the same reports as 'html'. 'influx' outputs one line per pool (measurement 'hitachi_pool') in InfluxDB line protocol with the capacities [GB], the utilization, the thresholds and the compression ratios tagged with the serial number, the model, the pool id, name and type and fmc. Only with the type 'pool'. (Optional) (default 'stdout')")
+	//outputfile option
+	fmt.Println(LineIn + "-outputfile string")
+	fmt.Println(LineIn + SecondLineIn + "File the workbook or the html report is written to. Required with the outputs 'xlsx' and 'html'.")
 	//type option
 	fmt.Println(LineIn + "-type string")
-	fmt.Println(LineIn + SecondLineIn + "Sets the type of output you want. 'pool' get all pool data. 'reserve' gets you all LUNs/LDEVs that have a reserve. (Optional) (default 'pool')")
+	fmt.Println(LineIn + SecondLineIn + "Sets the type of output you want. 'pool' get all pool data. 'reserve' gets you all LUNs/LDEVs that have a reserve. 'drive' gets you all physical drives and audits the spare coverage. 'ldev' gets you all LDEVs. 'orphan' gets you all DP volumes without LUN path sorted by reclaimable capacity. 'chargeback' gets you the provisioned, used and estimated physical capacity per host group and pool. 'pool-consumers' gets you all DP volumes of a pool (-poolid) with their LUN paths ranked by the used capacity. 'local-replication' gets you all ShadowImage and Thin Image pairs and the snapshot capacity per pool. 'remote-replication' gets you all TrueCopy, Universal Replicator and GAD pairs, the journals and the quorum disks and exits with 90 if a pair is suspended or a journal is above the threshold. 'snapshot-prune' deletes the Thin Image snapshots older than the retention of their snapshot group (-retentionfile). 'hcs-register' and 'hcs-unregister' add or remove storage systems (-svpip, -serial, -model or -storagefile) of a HCS Configuration Manager. 'hcs-list' shows them. 'sessions' gets you all sessions of the storage and deletes the orphaned sessions of this tool (same user and local IP) with -execute. 'provision' creates a new LDEV in a pool (-poolid, -capacity) and maps it to the host groups (-hostgroups) with -execute. 'lun-map' maps the LUN paths of a map file (-mapfile) with -execute. 'host-create' creates the missing host groups and WWNs of a host file (-hostfile) with -execute. 'ldev-expand' and 'ldev-delete' expand or delete an LDEV (-ldevid) with -execute. 'pool-expand-plan' plans the parity groups to add to a pool (-poolid) to reach the free capacity (-targetfree). 'pool-rebalance' recommends DP volumes to migrate between pools of the same tier. 'snapshot' saves the pools, host groups, LUN paths and reservations to -snapshotdir. 'snapshot-diff' compares two snapshots (-snapshotfrom, -snapshotto). 'drift' compares the host groups, WWNs and LUN paths with a state file (-statefile) and exits with 89 on drift. (Optional) (default 'pool')")
+	//poolid option
+	fmt.Println(LineIn + "-poolid int")
+	fmt.Println(LineIn + SecondLineIn + "Pool ID. With the types 'ldev' and 'orphan' only the LDEVs of this pool are shown. With the types 'pool-consumers' and 'pool-expand-plan' it is the pool of the report and with the type 'provision' the pool of the new LDEV. Required with the types 'pool-consumers', 'pool-expand-plan' and 'provision'. (Optional)")
+	//label option
+	fmt.Println(LineIn + "-label string")
+	fmt.Println(LineIn + SecondLineIn + "Shows only the LDEVs with a label matching this regular expression. Used with the types 'ldev' and 'orphan'. With the type 'provision' it is the label of the new LDEV (not a regular expression). (Optional)")
+	//attribute option
+	fmt.Println(LineIn + "-attribute string")
+	fmt.Println(LineIn + SecondLineIn + "Shows only the LDEVs with this attribute (ex: HDP, HDT, CVS, CMD). Used with the type 'ldev'. (Optional)")
+	//groupby option
+	fmt.Println(LineIn + "-groupby string")
+	fmt.Println(LineIn + SecondLineIn + "Groups the chargeback by 'hostgroup' or by a regular expression on the host group name. The first capture group is the group. Used with the type 'chargeback'. (Optional) (default 'hostgroup')")
+	//tagfile option
+	fmt.Println(LineIn + "-tagfile string")
+	fmt.Println(LineIn + SecondLineIn + "File with lines '<host group name regular expression>,<tag>'. The chargeback is grouped by the first matching tag. Used with the type 'chargeback'. (Optional)")
+	//snapshotdir option
+	fmt.Println(LineIn + "-snapshotdir string")
+	fmt.Println(LineIn + SecondLineIn + "Directory to store a snapshot of every run (<storageDeviceId>_<time>.json). The last snapshot is used to show the growth. Used with the type 'pool-consumers'. Required with the type 'snapshot'.")
+	//snapshotfrom and snapshotto option
+	fmt.Println(LineIn + "-snapshotfrom string -snapshotto string")
+	fmt.Println(LineIn + SecondLineIn + "Older and newer snapshot file of the type 'snapshot'. The added and removed pools, host groups, LUN paths and reservations, the capacity changes of the pools and the changed host modes are shown. No user and password are needed. Required with the type 'snapshot-diff'.")
+	//journalthreshold option
+	fmt.Println(LineIn + "-journalthreshold int")
+	fmt.Println(LineIn + SecondLineIn + "Journal usage rate [%] above which the remote replication check fails. Used with the type 'remote-replication'. (Optional) (default 80)")
+	//retentionfile option
+	fmt.Println(LineIn + "-retentionfile string")
+	fmt.Println(LineIn + SecondLineIn + "File with lines '<snapshot group name regular expression>,<days>'. The first matching line is the retention of a snapshot group. Snapshot groups without matching line are kept. Required with the type 'snapshot-prune'.")
+	//execute option
+	fmt.Println(LineIn + "-execute")
+	fmt.Println(LineIn + SecondLineIn + "Executes the changes. Without it the changes are only shown (dry-run). Used with the types 'snapshot-prune', 'sessions', 'provision', 'lun-map', 'host-create', 'ldev-expand' and 'ldev-delete'. (Optional)")
+	//svpip, serial and model option
+	fmt.Println(LineIn + "-svpip string -serial int -model string")
+	fmt.Println(LineIn + SecondLineIn + "SVP IP, serial number and model (ex: 'VSP G600') of the storage system. All are needed with the type 'hcs-register'. Only the serial number is needed with the type 'hcs-unregister'. (Optional)")
+	//storagefile option
+	fmt.Println(LineIn + "-storagefile string")
+	fmt.Println(LineIn + SecondLineIn + "File with lines '<svpIp>,<serial>,<model>' to register or unregister many storage systems. Used with the types 'hcs-register' and 'hcs-unregister'. (Optional)")
+	//sessionidle option
+	fmt.Println(LineIn + "-sessionidle int")
+	fmt.Println(LineIn + SecondLineIn + "Minutes without access after which a session of this tool is orphaned. Used with the type 'sessions'. (Optional) (default 10)")
+	fmt.Println(LineIn + SecondLineIn + "The API does not show the client of a session: all sessions of the same user from the same local IP are treated as sessions of this tool, also those of other clients of that user on this host (or behind the same NAT). Use a dedicated user for this tool. The current session and the sessions in the token cache (-tokencache) are never deleted.")
+	//force option
+	fmt.Println(LineIn + "-force")
+	fmt.Println(LineIn + SecondLineIn + "Deletes the sessions with the force option of the API. Used with the type 'sessions'. (Optional)")
+	//tokencache option
+	fmt.Println(LineIn + "-tokencache string")
+	fmt.Println(LineIn + SecondLineIn + "Encrypted file (AES-GCM, the key is derived with scrypt from the user, the password and a random salt) to keep the session between runs. A valid session of the host and storage is reused and an expired one is replaced. The session is not deleted at the end. A file that cannot be decrypted with the credentials (ex: of another user) is not overwritten and the session is deleted at the end. Use one file per user. (Optional)")
+	//alivetime option
+	fmt.Println(LineIn + "-alivetime int")
+	fmt.Println(LineIn + SecondLineIn + "Seconds (1-300) without a request after which the session expires. The session is kept alive in the background every half of this time. If it expires anyway a new session is created. (Optional) (default of the storage: 300)")
+	//authtimeout option
+	fmt.Println(LineIn + "-authtimeout int")
+	fmt.Println(LineIn + SecondLineIn + "Seconds (1-900) to wait for the authentication of the session (ex: an external authentication server). (Optional) (default of the storage)")
+	//capacity option
+	fmt.Println(LineIn + "-capacity string")
+	fmt.Println(LineIn + SecondLineIn + "Capacity of the new LDEV with the unit M, G or T (ex: 100G). Required with the type 'provision'. With the type 'ldev-expand' it is the capacity to add.")
+	//hostgroups option
+	fmt.Println(LineIn + "-hostgroups string")
+	fmt.Println(LineIn + SecondLineIn + "Host groups '<port>:<host group number or name>' separated by ',' (ex: CL1-A:1,CL2-A:esx01). The new LDEV is mapped to all of them with the same LUN. Required with the type 'provision'.")
+	//datareduction option
+	fmt.Println(LineIn + "-datareduction string")
+	fmt.Println(LineIn + SecondLineIn + "Data reduction mode of the new LDEV: 'disabled', 'compression' or 'compression_deduplication'. Used with the type 'provision'. (Optional) (default disabled)")
+	//lun option
+	fmt.Println(LineIn + "-lun int")
+	fmt.Println(LineIn + SecondLineIn + "LUN number of the new LDEV in all host groups. Without it the lowest LUN that is free in all host groups is used. Used with the type 'provision'. (Optional)")
+	//maxsubscription option
+	fmt.Println(LineIn + "-maxsubscription int")
+	fmt.Println(LineIn + SecondLineIn + "Subscription [%] (capacity of all DP volumes / pool capacity) the pool must not exceed with the new LDEV. Otherwise the plan is refused. Used with the type 'provision'. (Optional) (default 150)")
+	//mapfile option
+	fmt.Println(LineIn + "-mapfile string")
+	fmt.Println(LineIn + SecondLineIn + "File with lines '<LDEV ID>,<port>,<host group number or name>,<LUN>' (LDEV ID decimal or 00:04:00, 0 to 65279). The rows are validated, shown as diff and mapped in order. Another LUN of the LDEV on an existing path or in another row is a warning. Required with the type 'lun-map'.")
+	//progressfile option
+	fmt.Println(LineIn + "-progressfile string")
+	fmt.Println(LineIn + SecondLineIn + "File with the last applied row of the map file. After a failure the next run resumes after this row. It is deleted when all rows are applied. Used with the type 'lun-map'. (Optional) (default <mapfile>.progress)")
+	//hostfile option
+	fmt.Println(LineIn + "-hostfile string")
+	fmt.Println(LineIn + SecondLineIn + "YAML file of a host: name, os (linux, vmware, windows, aix, solaris, hp-ux) and per fabric the wwns and the ports. Optional are hostMode, hostModeOptions and hostGroupName ({name}, {port}, {portshort}). Without hostGroupName the naming of the existing host groups is used. Required with the type 'host-create'.")
+	//ldevid option
+	fmt.Println(LineIn + "-ldevid string")
+	fmt.Println(LineIn + SecondLineIn + "LDEV ID (decimal or 00:04:00, 0 to 65279). 'ldev-expand' adds -capacity if the pool has enough free capacity (physical free * compression ratio) and stays below the depletion threshold. 'ldev-delete' refuses LDEVs with LUN paths, copy pairs or reservations. Required with the types 'ldev-expand' and 'ldev-delete'.")
+	//yes option
+	fmt.Println(LineIn + "-yes")
+	fmt.Println(LineIn + SecondLineIn + "Confirms the expansion or deletion of the LDEV with -execute without asking for the LDEV ID. Used with the types 'ldev-expand' and 'ldev-delete'. (Optional)")
+	//targetfree option
+	fmt.Println(LineIn + "-targetfree int")
+	fmt.Println(LineIn + SecondLineIn + "Free capacity [%] the pool should reach. The fewest unused parity groups (without LDEVs) with the drive type and RAID level of the pool are proposed. Exits with 88 if all of them are not enough. Used with the type 'pool-expand-plan'. (Optional) (default 30)")
+	//statefile option
+	fmt.Println(LineIn + "-statefile string")
+	fmt.Println(LineIn + SecondLineIn + "YAML file with the desired host groups (port, name, hostMode, hostModeOptions, wwns and luns with LUN: LDEV ID). Missing, extra and changed host groups, WWNs and LUN paths of its ports are shown. Exits with 89 on drift. Required with the type 'drift'.")
+	//influx options
+	fmt.Println(LineIn + "-influxurl string")
+	fmt.Println(LineIn + SecondLineIn + "URL of the InfluxDB v2 (ex: http://influxdb:8086). The line protocol of the output 'influx' is posted in batches to its write endpoint instead of the command line. A batch is retried 3 times if InfluxDB is not reachable or answers with 429 or 5xx. Exits with 105 if it cannot be written. (Optional)")
+	fmt.Println(LineIn + "-influxorg string")
+	fmt.Println(LineIn + SecondLineIn + "Organization of the InfluxDB. Required with -influxurl.")
+	fmt.Println(LineIn + "-influxbucket string")
+	fmt.Println(LineIn + SecondLineIn + "Bucket of the InfluxDB. Required with -influxurl.")
+	fmt.Println(LineIn + "-influxtoken string")
+	fmt.Println(LineIn + SecondLineIn + "API token of the InfluxDB with write permission to the bucket. (Optional)")
+	fmt.Println(LineIn + "-influxinsecure")
+	fmt.Println(LineIn + SecondLineIn + "Skips the verification of the certificate of the InfluxDB (https). The token is sent to a server that is not verified. Only for test systems. (Optional) (default false)")
+	//jobtimeout option
+	fmt.Println(LineIn + "-jobtimeout int")
+	fmt.Println(LineIn + SecondLineIn + "Seconds to wait for an asynchronous job (ex: a deletion) of the storage to complete. The job keeps running on the storage after the timeout. (Optional) (default 300)")
 	//verbose option
 	fmt.Println(LineIn + "-verbose")
 	fmt.Println(LineIn + SecondLineIn + "Sets the output mode to verbose. (Optional)")
@@ -2425,6 +3499,66 @@ func HelpOutput(Version string) {
 	fmt.Println(LineIn + "-trace")
 	fmt.Println(LineIn + SecondLineIn + "Sets the output mode to trace. Only needed for troubleshooting. (Optional)")
 	fmt.Println()
+	fmt.Println("EXIT STATUS:")
+	fmt.Println(LineIn + "0")
+	fmt.Println(LineIn + SecondLineIn + "OK (or help shown)")
+	fmt.Println(LineIn + "1")
+	fmt.Println(LineIn + SecondLineIn + "Invalid or missing options")
+	fmt.Println(LineIn + "11, 20, 21, 41, 52, 61, 62, 81, 83")
+	fmt.Println(LineIn + SecondLineIn + "The response of the REST API cannot be parsed (JSON)")
+	fmt.Println(LineIn + "70, 71")
+	fmt.Println(LineIn + SecondLineIn + "The tag file (-tagfile) cannot be read or is not valid")
+	fmt.Println(LineIn + "72, 73")
+	fmt.Println(LineIn + SecondLineIn + "The retention file (-retentionfile) cannot be read or is not valid")
+	fmt.Println(LineIn + "74, 75")
+	fmt.Println(LineIn + SecondLineIn + "The storage file (-storagefile) cannot be read or is not valid")
+	fmt.Println(LineIn + "76, 77")
+	fmt.Println(LineIn + SecondLineIn + "The map file (-mapfile) cannot be read or is not valid")
+	fmt.Println(LineIn + "78, 79")
+	fmt.Println(LineIn + SecondLineIn + "The host file (-hostfile) cannot be read or is not valid")
+	fmt.Println(LineIn + "80")
+	fmt.Println(LineIn + SecondLineIn + "The snapshots (-snapshotfrom, -snapshotto) cannot be compared")
+	fmt.Println(LineIn + "82, 85")
+	fmt.Println(LineIn + SecondLineIn + "The state file (-statefile) cannot be read or is not valid")
+	fmt.Println(LineIn + "86")
+	fmt.Println(LineIn + SecondLineIn + "Type 'ldev-delete': the LDEV could not be deleted")
+	fmt.Println(LineIn + "87")
+	fmt.Println(LineIn + SecondLineIn + "Type 'pool-expand-plan': the pool or the drive type of the pool cannot be found")
+	fmt.Println(LineIn + "88")
+	fmt.Println(LineIn + SecondLineIn + "Type 'pool-expand-plan': the unused parity groups are not enough for the target free capacity")
+	fmt.Println(LineIn + "89")
+	fmt.Println(LineIn + SecondLineIn + "Type 'drift': the storage differs from the state file")
+	fmt.Println(LineIn + "90")
+	fmt.Println(LineIn + SecondLineIn + "Type 'remote-replication': the check has findings")
+	fmt.Println(LineIn + "91")
+	fmt.Println(LineIn + SecondLineIn + "Type 'snapshot-prune': not all snapshots could be deleted")
+	fmt.Println(LineIn + "92")
+	fmt.Println(LineIn + SecondLineIn + "Type 'hcs-register', 'hcs-unregister': not all storage systems could be registered or unregistered")
+	fmt.Println(LineIn + "93")
+	fmt.Println(LineIn + SecondLineIn + "Type 'provision': the LDEV could not be provisioned completely")
+	fmt.Println(LineIn + "94")
+	fmt.Println(LineIn + SecondLineIn + "Type 'provision': the provisioning is refused (pool, subscription, host groups or LUN)")
+	fmt.Println(LineIn + "95")
+	fmt.Println(LineIn + SecondLineIn + "Type 'lun-map': the map file contains invalid rows")
+	fmt.Println(LineIn + "96")
+	fmt.Println(LineIn + SecondLineIn + "Type 'lun-map': not all LUN paths could be mapped")
+	fmt.Println(LineIn + "97")
+	fmt.Println(LineIn + SecondLineIn + "Type 'host-create': not all host groups or WWNs could be created")
+	fmt.Println(LineIn + "98")
+	fmt.Println(LineIn + SecondLineIn + "Type 'ldev-expand', 'ldev-delete': the change is refused, not confirmed or the LDEV does not exist")
+	fmt.Println(LineIn + "99")
+	fmt.Println(LineIn + SecondLineIn + "Type 'ldev-expand': the LDEV could not be expanded")
+	fmt.Println(LineIn + "100, 101, 102")
+	fmt.Println(LineIn + SecondLineIn + "The webrequest cannot be created or executed (host, port, credentials)")
+	fmt.Println(LineIn + "104")
+	fmt.Println(LineIn + SecondLineIn + "A reading request is rejected by the REST API")
+	fmt.Println(LineIn + "105")
+	fmt.Println(LineIn + SecondLineIn + "The line protocol cannot be written to InfluxDB")
+	fmt.Println(LineIn + "200, 201, 202")
+	fmt.Println(LineIn + SecondLineIn + "The protocol is wrong or the host/IP does not answer as REST API")
+	fmt.Println(LineIn + "203, 204")
+	fmt.Println(LineIn + SecondLineIn + "The REST API version cannot be converted or is not supported")
+	fmt.Println()
 	fmt.Println("FILES:")
 	fmt.Fprintf(os.Stderr, LineIn+"%s\n", os.Args[0])
 	fmt.Println()
@@ -2440,6 +3574,59 @@ func HelpOutput(Version string) {
 	fmt.Fprintf(os.Stderr, LineIn+"%s -user restuser -password restpass -host 10.0.1.1 -port 23451 -verbose\n", os.Args[0])
 	fmt.Println(LineIn + "Shows all the reserves on LUNs on a Storage System. It connects to the restserver on host 10.0.1.1 on port 23451 (HCS) with the user credentials in table format")
 	fmt.Fprintf(os.Stderr, LineIn+"%s -user restuser -password restpass -host 10.0.1.1 -port 23451 -type reserve\n", os.Args[0])
+	fmt.Println(LineIn + "Shows all physical drives of a Storage System and warns about drive types without a matching spare and drives that are blocked or copying")
+	fmt.Fprintf(os.Stderr, LineIn+"%s -user restuser -password restpass -host 10.0.1.1 -type drive\n", os.Args[0])
+	fmt.Println(LineIn + "Shows all LDEVs of pool 20 with a label starting with 'ORA' in csv format")
+	fmt.Fprintf(os.Stderr, LineIn+"%s -user restuser -password restpass -host 10.0.1.1 -type ldev -poolid 20 -label '^ORA' -output csv\n", os.Args[0])
+	fmt.Println(LineIn + "Shows all DP volumes without LUN path. The volumes with the most reclaimable capacity are shown first")
+	fmt.Fprintf(os.Stderr, LineIn+"%s -user restuser -password restpass -host 10.0.1.1 -type orphan\n", os.Args[0])
+	fmt.Println(LineIn + "Creates the monthly chargeback in csv format. The application is the part of the host group name before the first '_'")
+	fmt.Fprintf(os.Stderr, LineIn+"%s -user restuser -password restpass -host 10.0.1.1 -type chargeback -groupby '^([^_]+)_' -output csv\n", os.Args[0])
+	fmt.Println(LineIn + "Shows the hosts using pool 20 and the growth of their volumes since the last run")
+	fmt.Fprintf(os.Stderr, LineIn+"%s -user restuser -password restpass -host 10.0.1.1 -type pool-consumers -poolid 20 -snapshotdir /var/lib/hichpoolinfo\n", os.Args[0])
+	fmt.Println(LineIn + "Shows all ShadowImage and Thin Image pairs and warns about pairs suspended by error")
+	fmt.Fprintf(os.Stderr, LineIn+"%s -user restuser -password restpass -host 10.0.1.1 -type local-replication\n", os.Args[0])
+	fmt.Println(LineIn + "DR readiness check in json format. Exits with 90 if a pair is suspended or a journal is used more than 60%")
+	fmt.Fprintf(os.Stderr, LineIn+"%s -user restuser -password restpass -host 10.0.1.1 -type remote-replication -journalthreshold 60 -output json\n", os.Args[0])
+	fmt.Println(LineIn + "Shows the Thin Image snapshots older than the retention in /etc/hichpoolinfo/retention.csv (ex: line '^TI_DAILY,7') and deletes them with -execute")
+	fmt.Fprintf(os.Stderr, LineIn+"%s -user restuser -password restpass -host 10.0.1.1 -type snapshot-prune -retentionfile /etc/hichpoolinfo/retention.csv\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, LineIn+"%s -user restuser -password restpass -host 10.0.1.1 -type snapshot-prune -retentionfile /etc/hichpoolinfo/retention.csv -execute\n", os.Args[0])
+	fmt.Println(LineIn + "Registers a storage system in the HCS Configuration Manager on host 10.0.1.1. Storage systems already registered are skipped")
+	fmt.Fprintf(os.Stderr, LineIn+"%s -user raidcom -password raidcom -host 10.0.1.1 -port 23451 -type hcs-register -svpip 10.70.5.104 -serial 470018 -model 'VSP G600'\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, LineIn+"%s -user raidcom -password raidcom -host 10.0.1.1 -port 23451 -type hcs-list\n", os.Args[0])
+	fmt.Println(LineIn + "Deletes the sessions this tool left open for more than 30 minutes")
+	fmt.Fprintf(os.Stderr, LineIn+"%s -user restuser -password restpass -host 10.0.1.1 -type sessions -sessionidle 30 -execute\n", os.Args[0])
+	fmt.Println(LineIn + "Shows the pool output every five minutes (cron) and reuses the session of the last run")
+	fmt.Fprintf(os.Stderr, LineIn+"%s -user restuser -password restpass -host 10.0.1.1 -output csv -tokencache ~/.hichpoolinfo.cache\n", os.Args[0])
+	fmt.Println(LineIn + "Shows all LDEVs of a large storage with sessions expiring after 60 seconds without a request")
+	fmt.Fprintf(os.Stderr, LineIn+"%s -user restuser -password restpass -host 10.0.1.1 -type ldev -alivetime 60 -authtimeout 120\n", os.Args[0])
+	fmt.Println(LineIn + "Shows the plan to create a 100 GB compressed LDEV in pool 1 mapped to two host groups. -execute provisions it")
+	fmt.Fprintf(os.Stderr, LineIn+"%s -user restuser -password restpass -host 10.0.1.1 -type provision -poolid 1 -capacity 100G -label esx01_data -datareduction compression -hostgroups CL1-A:esx01,CL2-A:esx01\n", os.Args[0])
+	fmt.Println(LineIn + "Validates the LUN mappings of a host migration and maps them. A failed run is resumed by running it again")
+	fmt.Fprintf(os.Stderr, LineIn+"%s -user restuser -password restpass -host 10.0.1.1 -type lun-map -mapfile migration.csv -execute\n", os.Args[0])
+	fmt.Println(LineIn + "Creates the host groups and WWNs of a new server that are missing")
+	fmt.Fprintf(os.Stderr, LineIn+"%s -user restuser -password restpass -host 10.0.1.1 -type host-create -hostfile esx01.yaml -execute\n", os.Args[0])
+	fmt.Println(LineIn + "Checks the pool and expands the LDEV 00:04:00 by 50 GB. The LDEV ID has to be typed to confirm")
+	fmt.Fprintf(os.Stderr, LineIn+"%s -user restuser -password restpass -host 10.0.1.1 -type ldev-expand -ldevid 00:04:00 -capacity 50G -execute\n", os.Args[0])
+	fmt.Println(LineIn + "Shows why the LDEV 1024 cannot be deleted or deletes it without asking (ex: from a script)")
+	fmt.Fprintf(os.Stderr, LineIn+"%s -user restuser -password restpass -host 10.0.1.1 -type ldev-delete -ldevid 1024 -execute -yes\n", os.Args[0])
+	fmt.Println(LineIn + "Shows the parity groups to add to pool 20 to get 40% free capacity and the effective capacity they add")
+	fmt.Fprintf(os.Stderr, LineIn+"%s -user restuser -password restpass -host 10.0.1.1 -type pool-expand-plan -poolid 20 -targetfree 40\n", os.Args[0])
+	fmt.Println(LineIn + "Recommends the DP volumes to migrate between pools of the same type, drive type and RAID level and shows the utilization of every pool after the moves")
+	fmt.Fprintf(os.Stderr, LineIn+"%s -user restuser -password restpass -host 10.0.1.1 -type pool-rebalance\n", os.Args[0])
+	fmt.Println(LineIn + "Stores a snapshot of the pools, host groups and LUN paths every week and shows the changes of the last week in csv format")
+	fmt.Fprintf(os.Stderr, LineIn+"%s -user restuser -password restpass -host 10.0.1.1 -type snapshot -snapshotdir /var/lib/hichpoolinfo\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, LineIn+"%s -type snapshot-diff -snapshotfrom /var/lib/hichpoolinfo/834000470018_20261011T060000.json -snapshotto /var/lib/hichpoolinfo/834000470018_20261018T060000.json -output csv\n", os.Args[0])
+	fmt.Println(LineIn + "Compares the host groups, WWNs and LUN paths every night with the state file in git and shows the drift in json format")
+	fmt.Fprintf(os.Stderr, LineIn+"%s -user restuser -password restpass -host 10.0.1.1 -type drift -statefile /srv/git/san/vsp01.yaml -output json\n", os.Args[0])
+	fmt.Println(LineIn + "Writes the pool report as formatted workbook for the management")
+	fmt.Fprintf(os.Stderr, LineIn+"%s -user restuser -password restpass -host 10.0.1.1 -type pool -output xlsx -outputfile pools.xlsx\n", os.Args[0])
+	fmt.Println(LineIn + "Writes the pool report with the trends of the weekly snapshots as html file to mail it")
+	fmt.Fprintf(os.Stderr, LineIn+"%s -user restuser -password restpass -host 10.0.1.1 -type pool -output html -outputfile pools.html -snapshotdir /var/lib/hichpoolinfo\n", os.Args[0])
+	fmt.Println(LineIn + "Writes the pool report as markdown to paste it into the wiki or a change ticket")
+	fmt.Fprintf(os.Stderr, LineIn+"%s -user restuser -password restpass -host 10.0.1.1 -type pool -output markdown > pools.md\n", os.Args[0])
+	fmt.Println(LineIn + "Writes the pool metrics to the bucket 'storage' of the InfluxDB")
+	fmt.Fprintf(os.Stderr, LineIn+"%s -user restuser -password restpass -host 10.0.1.1 -type pool -output influx -influxurl http://influxdb:8086 -influxorg it -influxbucket storage -influxtoken <token>\n", os.Args[0])
 	fmt.Println()
 
 	TimeEnd := time.Now()
@@ -2483,3 +3670,88 @@ func RoundFloat64(val float64, places int) (newVal float64) {
 
 	return newVal
 }
+
+//HeaderFormat returns the descriptor of a value. csv, json, xlsx, html and markdown descriptors get the type of the value added ex: "Capacity [GB](float64)"
+func HeaderFormat(Descriptor string, Type string, p Params) string {
+	if p.OutputStyle == "csv" || p.OutputStyle == "json" || p.OutputStyle == "xlsx" || p.OutputStyle == "html" || p.OutputStyle == "markdown" {
+		return Descriptor + "(" + Type + ")"
+	}
+	return Descriptor
+}
+
+//HeaderSplit returns the descriptor without the type and the type of a descriptor of HeaderFormat. the type is empty if the descriptor has none.
+//example: HeaderSplit("Capacity [GB](float64)") returns "Capacity [GB]", "float64"
+func HeaderSplit(Descriptor string) (string, string) {
+	if Start := strings.LastIndex(Descriptor, "("); Start > 0 && strings.HasSuffix(Descriptor, ")") {
+		return Descriptor[:Start], Descriptor[Start+1 : len(Descriptor)-1]
+	}
+	return Descriptor, ""
+}
+
+//OutputDocument returns true if the output is a document for people (xlsx, html, markdown). documents get additional reports (ex: the tiers and the totals of the pools).
+func OutputDocument(p Params) bool {
+	return p.OutputStyle == "xlsx" || p.OutputStyle == "html" || p.OutputStyle == "markdown"
+}
+
+//OutputReports are the collected reports of the JSON output of a type with several reports (ex: pairs, journals and quorum disks). it is nil outside of OutputReportsStart and OutputReportsEnd.
+var OutputReports []string
+
+//OutputReportsStart starts the output of a type with several reports. every report needs a name (p.ReportName).
+//json: the reports are collected and output as one object keyed by the report (ex: {"pairs":[...],"journals":[...]}). csv: every block starts with the line "# <report>".
+func OutputReportsStart() {
+	OutputReports = []string{}
+}
+
+//OutputReportsEnd outputs the collected reports of the JSON output as one object. It has to be called before the function returns or stops after OutputReportsStart.
+func OutputReportsEnd(p Params) {
+	if OutputReports != nil && p.OutputStyle == "json" {
+		fmt.Println("{" + strings.Join(OutputReports, ",\n") + "}")
+	}
+	OutputReports = nil
+}
+
+//ReportTitle returns the title of a report in a document. the report (p.ReportName) or the type (-type) starting with an upper case letter (ex: "Pools").
+func ReportTitle(p Params) string {
+	var Name string
+	Name = p.ReportName
+	if Name == "" {
+		Name = p.OutputType
+	}
+	if Name == "" {
+		return Name
+	}
+	return strings.ToUpper(Name[:1]) + Name[1:]
+}
+
+//ElementString returns the string value of a key of a parsed JSON element. if the key does not exist an empty string is returned
+func ElementString(ParsedMap map[string]interface{}, Key string) string {
+	if Value, ok := ParsedMap[Key].(string); ok {
+		return Value
+	}
+	return ""
+}
+
+//ElementFloat64 returns the number value of a key of a parsed JSON element. if the key does not exist 0 is returned
+func ElementFloat64(ParsedMap map[string]interface{}, Key string) float64 {
+	if Value, ok := ParsedMap[Key].(float64); ok {
+		return Value
+	}
+	return 0
+}
+
+//LdevIDFormat formats a LDEV ID to the hex format xx:xx (ex: 13312 -> 34:00)
+func LdevIDFormat(LdevID int) string {
+	var LdevString string
+	//format int to string in hex
+	LdevString = strconv.FormatInt(int64(LdevID), 16)
+	//add leading zeros until it is 4 digits long
+	if len(LdevString) < 4 {
+		Len := 4 - len(LdevString)
+		for i := 0; i < Len; i++ {
+			LdevString = "0" + LdevString
+		}
+	}
+
+	// format the hex string to xx:xx
+	return LdevString[:len(LdevString)-2] + ":" + LdevString[len(LdevString)-2:]
+}
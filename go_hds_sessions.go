@@ -0,0 +1,215 @@
+package main
+
+import (
+	"net"
+	"strconv"
+	"time"
+)
+
+//SessionInfo type is a session of the storage
+type SessionInfo struct {
+	SessionID      int
+	UserID         string
+	IPAddress      string
+	CreatedTime    string
+	LastAccessTime string
+}
+
+//SessionsListGet gets all sessions of the storage
+//return value ([]SessionInfo) are all sessions. if an error happened the state is true. Otherwise false.
+//The function stops with exit status 21 ("JSON parsing error (Return Format is not correct).")
+//example: SessionsListGet(p)
+func SessionsListGet(p Params) ([]SessionInfo, bool) {
+	Debug.Println("Function 'SessionsListGet' started.")
+
+	//initial state is true that means NOK
+	State := true
+
+	var Sessions []SessionInfo
+
+	/*
+	   https://10.70.5.104/ConfigurationManager/v1/objects/storages/834000470018/sessions
+	   {
+	       "data": [{
+	           "sessionId": 7,
+	           "userId": "restuser",
+	           "ipAddress": "10.70.5.20",
+	           "createdTime": "2018-05-20T10:00:01Z",
+	           "lastAccessTime": "2018-05-20T10:00:03Z"
+	       }, {
+	*/
+
+	p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + "/ConfigurationManager/v1/objects/storages/" + p.StorageDeviceID + "/sessions"
+	p.RequestType = "GET"
+
+	var Data []interface{}
+	Data, State = RestDataGet(p, 21)
+	for _, Value1 := range Data {
+		ParsedMap := Value1.(map[string]interface{})
+
+		Sessions = append(Sessions, SessionInfo{
+			SessionID:      int(ElementFloat64(ParsedMap, "sessionId")),
+			UserID:         ElementString(ParsedMap, "userId"),
+			IPAddress:      ElementString(ParsedMap, "ipAddress"),
+			CreatedTime:    ElementString(ParsedMap, "createdTime"),
+			LastAccessTime: ElementString(ParsedMap, "lastAccessTime"),
+		})
+	}
+
+	Debug.Println("Function 'SessionsListGet' return values number of sessions:", len(Sessions))
+	Debug.Println("Function 'SessionsListGet' ended.")
+
+	//state to OK
+	State = false
+	return Sessions, State
+}
+
+//SessionsGet shows all sessions of the storage. Sessions of the same user and local IP as this run are created by this tool.
+//this is a heuristic: the API does not show the client of a session. other clients of the same user on the same host (or behind the same NAT) are counted as this tool.
+//sessions created by this tool that were not accessed for -sessionidle minutes are orphaned (ex: a run stopped before TokenDelete).
+//with -execute the orphaned sessions are deleted. -force deletes them with the force option of the API. The own session and the sessions in the token cache (-tokencache) are never deleted.
+//return value (string) is empty. if an error happened the state is true. Otherwise false.
+//example: SessionsGet(p)
+func SessionsGet(p Params) (string, bool) {
+	Debug.Println("Function 'SessionsGet' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//initial state is true that means NOK
+	State := true
+
+	Info.Println("Get sessions start")
+
+	var Sessions []SessionInfo
+	Sessions, State = SessionsListGet(p)
+
+	var LocalIP string
+	LocalIP = LocalIPGet(p)
+	Verbose.Println("Local IP: " + LocalIP)
+
+	//the sessions in the token cache (-tokencache) are reused by later runs and are never deleted
+	Cached := map[int]bool{}
+	if p.TokenCache != "" {
+		Entries, _ := TokenCacheRead(p)
+		for _, Entry := range Entries {
+			if Entry.Host == p.Host && Entry.Port == p.Port {
+				Cached[int(Entry.SessionID)] = true
+			}
+		}
+	}
+
+	//add empty string of strings to collect all session data to output
+	OutData := [][]string{}
+	var Orphaned int
+	Orphaned = 0
+	var Deleted int
+	Deleted = 0
+	for _, Session := range Sessions {
+		var Tool bool
+		Tool = Session.UserID == p.Username && Session.IPAddress == LocalIP
+
+		var Action string
+		Action = "keep"
+		switch {
+		case Session.SessionID == int(p.SessionID):
+			Action = "current"
+		case Cached[Session.SessionID]:
+			Action = "keep (token cache)"
+		case Tool && SessionIdle(Session, p.SessionIdle):
+			Orphaned = Orphaned + 1
+			Action = "dry-run"
+			if p.Execute {
+				if _, DeleteState := SessionDelete(p, Session.SessionID); DeleteState {
+					Action = "failed"
+				} else {
+					Action = "deleted"
+					Deleted = Deleted + 1
+				}
+			}
+		}
+
+		var Created string
+		Created = "no"
+		if Tool {
+			Created = "yes"
+		}
+
+		OutData = append(OutData, []string{p.ElementStringStart})
+		OutData = append(OutData, []string{HeaderFormat("Session ID", "string", p), strconv.Itoa(Session.SessionID)})
+		OutData = append(OutData, []string{HeaderFormat("User", "string", p), Session.UserID})
+		OutData = append(OutData, []string{HeaderFormat("IP address", "string", p), Session.IPAddress})
+		OutData = append(OutData, []string{HeaderFormat("Created", "string", p), Session.CreatedTime})
+		OutData = append(OutData, []string{HeaderFormat("Last access", "string", p), Session.LastAccessTime})
+		OutData = append(OutData, []string{HeaderFormat("Created by this tool", "string", p), Created})
+		OutData = append(OutData, []string{HeaderFormat("Action", "string", p), Action})
+		OutData = append(OutData, []string{p.ElementStringEnd})
+	}
+
+	if OutputListFormat(OutData, p) {
+		Warning.Println("The function 'OutputListFormat' returned an Error.")
+	}
+
+	if p.Execute {
+		Info.Println("Orphaned sessions deleted: " + strconv.Itoa(Deleted) + " of " + strconv.Itoa(Orphaned))
+	} else {
+		Info.Println("Orphaned sessions: " + strconv.Itoa(Orphaned) + " (dry-run. use -execute to delete them)")
+	}
+	Info.Println("Get sessions end")
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'SessionsGet' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'SessionsGet' ended.")
+
+	//state to OK
+	State = false
+	return "", State
+}
+
+//SessionDelete deletes a session of the storage. -force deletes it with the force option of the API.
+//return value (string) is the response. if the session cannot be deleted the state is true. Otherwise false.
+//example: SessionDelete(p, 7)
+func SessionDelete(p Params, SessionID int) (string, bool) {
+	Debug.Println("Function 'SessionDelete' started.")
+
+	Verbose.Println("Delete the session " + strconv.Itoa(SessionID))
+
+	p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + "/ConfigurationManager/v1/objects/storages/" + p.StorageDeviceID + "/sessions/" + strconv.Itoa(SessionID)
+	p.RequestType = "DELETE"
+	p.RequestBody = `{"force": ` + strconv.FormatBool(p.Force) + `}`
+
+	Out, Status, State := HTTPRequestResult(p)
+	if State {
+		Warning.Println("The session " + strconv.Itoa(SessionID) + " cannot be deleted (" + Status + "): " + Out)
+	}
+
+	Debug.Println("Function 'SessionDelete' ended.")
+	return Out, State
+}
+
+//SessionIdle checks if a session was not accessed for the number of minutes
+//sessions with a last access time that cannot be read are not idle
+func SessionIdle(Session SessionInfo, Minutes int) bool {
+	LastAccess, err := time.Parse(time.RFC3339, Session.LastAccessTime)
+	if err != nil {
+		Verbose.Println("The last access time (" + Session.LastAccessTime + ") of the session " + strconv.Itoa(Session.SessionID) + " cannot be read. It is kept.")
+		return false
+	}
+	return time.Since(LastAccess) >= time.Duration(Minutes)*time.Minute
+}
+
+//LocalIPGet returns the local IP used to contact the host. if it cannot be found "" is returned
+func LocalIPGet(p Params) string {
+	//udp does not send anything. only the route is looked up
+	Connection, err := net.Dial("udp", net.JoinHostPort(p.Host, p.Port))
+	if err != nil {
+		Warning.Println("The local IP cannot be found: " + err.Error())
+		return ""
+	}
+	defer Connection.Close()
+
+	if Address, ok := Connection.LocalAddr().(*net.UDPAddr); ok {
+		return Address.IP.String()
+	}
+	return ""
+}
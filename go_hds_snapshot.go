@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+//Snapshot type is the state of a storage system stored after a run in the snapshot directory (-snapshotdir).
+//Only the sections collected by the run are filled. It is used to compare runs. The reservations are part of the LUN paths.
+type Snapshot struct {
+	Time            time.Time
+	StorageDeviceID string
+	Ldevs           []LdevInfo
+	Pools           []PoolInfo
+	HostGroups      []HostGroupInfo
+	Luns            []LunInfo
+}
+
+//SnapshotTimeFormat is used in the snapshot file name <storageDeviceId>_<time>.json
+const SnapshotTimeFormat = "20060102T150405"
+
+//SnapshotWrite stores the snapshot in the directory as <storageDeviceId>_<time>.json
+//return value (string) is the file name. if an error happened the state is true. Otherwise false.
+func SnapshotWrite(Dir string, Snap Snapshot) (string, bool) {
+	Debug.Println("Function 'SnapshotWrite' started.")
+
+	//initial state is true that means NOK
+	State := true
+
+	FileName := filepath.Join(Dir, Snap.StorageDeviceID+"_"+Snap.Time.Format(SnapshotTimeFormat)+".json")
+
+	if err := os.MkdirAll(Dir, 0755); err != nil {
+		Warning.Println("The snapshot directory (" + Dir + ") cannot be created: " + err.Error())
+		return "", State
+	}
+
+	JSONByt, err := json.MarshalIndent(Snap, "", "  ")
+	if err != nil {
+		Warning.Println("The snapshot cannot be converted to JSON: " + err.Error())
+		return "", State
+	}
+
+	if err := ioutil.WriteFile(FileName, JSONByt, 0644); err != nil {
+		Warning.Println("The snapshot (" + FileName + ") cannot be written: " + err.Error())
+		return "", State
+	}
+
+	Verbose.Println("Snapshot stored: " + FileName)
+	Debug.Println("Function 'SnapshotWrite' ended.")
+
+	//state to OK
+	State = false
+	return FileName, State
+}
+
+//SnapshotRead reads a snapshot file
+//if an error happened the state is true. Otherwise false.
+func SnapshotRead(FileName string) (Snapshot, bool) {
+	var Snap Snapshot
+
+	JSONByt, err := ioutil.ReadFile(FileName)
+	if err != nil {
+		Warning.Println("The snapshot (" + FileName + ") cannot be read: " + err.Error())
+		return Snap, true
+	}
+
+	if err := json.Unmarshal(JSONByt, &Snap); err != nil {
+		Warning.Println("The snapshot (" + FileName + ") is not a valid snapshot: " + err.Error())
+		return Snap, true
+	}
+
+	return Snap, false
+}
+
+//SnapshotLatestRead reads the latest snapshot of the storage in the directory for which Contains returns true
+//return value (bool) is true if no snapshot was found. Otherwise false.
+//example: SnapshotLatestRead(p.SnapshotDir, p.StorageDeviceID, func(s Snapshot) bool { return len(s.Ldevs) > 0 })
+func SnapshotLatestRead(Dir string, StorageDeviceID string, Contains func(Snapshot) bool) (Snapshot, bool) {
+	Debug.Println("Function 'SnapshotLatestRead' started.")
+
+	var Snap Snapshot
+
+	FileNames, err := filepath.Glob(filepath.Join(Dir, StorageDeviceID+"_*.json"))
+	if err != nil || len(FileNames) == 0 {
+		Verbose.Println("No snapshot of the storage " + StorageDeviceID + " found in " + Dir)
+		return Snap, true
+	}
+
+	//the time in the file name sorts the files. the latest first
+	sort.Sort(sort.Reverse(sort.StringSlice(FileNames)))
+	for _, FileName := range FileNames {
+		if !strings.HasSuffix(FileName, ".json") {
+			continue
+		}
+		Read, State := SnapshotRead(FileName)
+		if State || !Contains(Read) {
+			continue
+		}
+		Verbose.Println("Latest snapshot: " + FileName)
+		Debug.Println("Function 'SnapshotLatestRead' ended.")
+		return Read, false
+	}
+
+	Verbose.Println("No matching snapshot of the storage " + StorageDeviceID + " found in " + Dir)
+	return Snap, true
+}
+
+//SnapshotsRead reads all snapshots of the storage in the directory for which Contains returns true. the oldest first.
+//example: SnapshotsRead(p.SnapshotDir, p.StorageDeviceID, func(s Snapshot) bool { return len(s.Pools) > 0 })
+func SnapshotsRead(Dir string, StorageDeviceID string, Contains func(Snapshot) bool) []Snapshot {
+	Debug.Println("Function 'SnapshotsRead' started.")
+
+	var Snaps []Snapshot
+
+	FileNames, err := filepath.Glob(filepath.Join(Dir, StorageDeviceID+"_*.json"))
+	if err != nil {
+		Verbose.Println("No snapshot of the storage " + StorageDeviceID + " found in " + Dir)
+		return Snaps
+	}
+
+	//the time in the file name sorts the files
+	sort.Strings(FileNames)
+	for _, FileName := range FileNames {
+		Read, State := SnapshotRead(FileName)
+		if State || !Contains(Read) {
+			continue
+		}
+		Snaps = append(Snaps, Read)
+	}
+
+	Verbose.Println("Snapshots of the storage "+StorageDeviceID+" found in "+Dir+":", len(Snaps))
+	Debug.Println("Function 'SnapshotsRead' ended.")
+	return Snaps
+}
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//SnapshotChange type is one difference between two snapshots
+//Object is "pool", "host group", "LUN path" or "reservation". Change is "added", "removed" or "changed".
+type SnapshotChange struct {
+	Object    string
+	ID        string
+	Change    string
+	Attribute string
+	Before    string
+	After     string
+}
+
+//SnapshotCollect collects the pools, host groups and LUN paths (with their reservations) and stores them as snapshot in the snapshot directory (-snapshotdir)
+//return value (string) is the file name of the snapshot. if an error happened the state is true. Otherwise false.
+//example: SnapshotCollect(p)
+func SnapshotCollect(p Params) (string, bool) {
+	Debug.Println("Function 'SnapshotCollect' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//initial state is true that means NOK
+	State := true
+
+	Info.Println("Snapshot start")
+
+	var Snap Snapshot
+	Snap.Time = TimeStart
+	Snap.StorageDeviceID = p.StorageDeviceID
+	Snap.Pools, State = PoolsListGet(p)
+	Snap.HostGroups, State = HostGroupsListGet(p)
+	Snap.Luns, State = LunsListGet(p)
+
+	var FileName string
+	FileName, State = SnapshotWrite(p.SnapshotDir, Snap)
+
+	var Reservations int
+	Reservations = 0
+	for _, Lun := range Snap.Luns {
+		if len(Lun.Reserves) > 0 {
+			Reservations = Reservations + 1
+		}
+	}
+
+	OutData := [][]string{}
+	OutData = append(OutData, []string{p.ElementStringStart})
+	OutData = append(OutData, []string{HeaderFormat("Storage device ID", "string", p), Snap.StorageDeviceID})
+	OutData = append(OutData, []string{HeaderFormat("Pools", "float64", p), strconv.Itoa(len(Snap.Pools))})
+	OutData = append(OutData, []string{HeaderFormat("Host groups", "float64", p), strconv.Itoa(len(Snap.HostGroups))})
+	OutData = append(OutData, []string{HeaderFormat("LUN paths", "float64", p), strconv.Itoa(len(Snap.Luns))})
+	OutData = append(OutData, []string{HeaderFormat("Reservations", "float64", p), strconv.Itoa(Reservations)})
+	OutData = append(OutData, []string{HeaderFormat("File", "string", p), FileName})
+	OutData = append(OutData, []string{p.ElementStringEnd})
+	if OutputListFormat(OutData, p) {
+		Warning.Println("The function 'OutputListFormat' returned an Error.")
+	}
+
+	Info.Println("Snapshot end")
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'SnapshotCollect' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'SnapshotCollect' return values State:", State)
+	Debug.Println("Function 'SnapshotCollect' ended.")
+
+	return FileName, State
+}
+
+//SnapshotDiff shows the changes of the pools, host groups, LUN paths and reservations between two snapshots (-snapshotfrom and -snapshotto). No session is needed.
+//only the sections (pools, host groups, LUN paths) that both snapshots contain are compared. ex: a snapshot of the type 'pool-consumers' has LDEVs only.
+//return value (int) is the number of changes. if an error happened the state is true. Otherwise false.
+//The function stops with exit status 80 ("A snapshot cannot be read or the snapshots have no section in common.")
+//example: SnapshotDiff(p)
+func SnapshotDiff(p Params) (int, bool) {
+	Debug.Println("Function 'SnapshotDiff' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//initial state is true that means NOK
+	State := true
+
+	Info.Println("Snapshot diff start")
+
+	From, FromState := SnapshotRead(p.SnapshotFrom)
+	To, ToState := SnapshotRead(p.SnapshotTo)
+	if FromState || ToState {
+		Error.Println("The snapshots cannot be compared.")
+		os.Exit(80)
+	}
+	if From.StorageDeviceID != To.StorageDeviceID {
+		Warning.Println("The snapshots are of different storages (" + From.StorageDeviceID + " and " + To.StorageDeviceID + ").")
+	}
+	Info.Println("Changes from " + From.Time.Format(time.RFC3339) + " to " + To.Time.Format(time.RFC3339))
+
+	//no session. the header of the markdown output is the storage and the time of the newer snapshot
+	MarkdownStorage.StorageDeviceID = To.StorageDeviceID
+	MarkdownCollected = To.Time
+
+	var Changes []SnapshotChange
+	var Sections int
+	Sections = 0
+	if SnapshotSectionCompared("pools", len(From.Pools), len(To.Pools)) {
+		Changes = append(Changes, SnapshotPoolsDiff(From.Pools, To.Pools, p)...)
+		Sections = Sections + 1
+	}
+	if SnapshotSectionCompared("host groups", len(From.HostGroups), len(To.HostGroups)) {
+		Changes = append(Changes, SnapshotHostGroupsDiff(From.HostGroups, To.HostGroups)...)
+		Sections = Sections + 1
+	}
+	if SnapshotSectionCompared("LUN paths", len(From.Luns), len(To.Luns)) {
+		Changes = append(Changes, SnapshotLunsDiff(From.Luns, To.Luns)...)
+		Sections = Sections + 1
+	}
+	if Sections == 0 {
+		Error.Println("The snapshots (" + p.SnapshotFrom + " and " + p.SnapshotTo + ") have no pools, host groups or LUN paths in common. Please compare two snapshots of the type 'snapshot'.")
+		os.Exit(80)
+	}
+
+	OutData := [][]string{}
+	for _, Change := range Changes {
+		OutData = append(OutData, []string{p.ElementStringStart})
+		OutData = append(OutData, []string{HeaderFormat("Object", "string", p), Change.Object})
+		OutData = append(OutData, []string{HeaderFormat("ID", "string", p), Change.ID})
+		OutData = append(OutData, []string{HeaderFormat("Change", "string", p), Change.Change})
+		OutData = append(OutData, []string{HeaderFormat("Attribute", "string", p), Change.Attribute})
+		OutData = append(OutData, []string{HeaderFormat("Before", "string", p), Change.Before})
+		OutData = append(OutData, []string{HeaderFormat("After", "string", p), Change.After})
+		OutData = append(OutData, []string{p.ElementStringEnd})
+	}
+	if len(Changes) == 0 {
+		Info.Println("No changes.")
+	} else if OutputListFormat(OutData, p) {
+		Warning.Println("The function 'OutputListFormat' returned an Error.")
+	}
+
+	Info.Println("Snapshot diff end (" + strconv.Itoa(len(Changes)) + " changes)")
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'SnapshotDiff' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'SnapshotDiff' return values number of changes:", len(Changes))
+	Debug.Println("Function 'SnapshotDiff' ended.")
+
+	//state to OK
+	State = false
+	return len(Changes), State
+}
+
+//SnapshotSectionCompared returns true if both snapshots contain the section. a section that only one snapshot contains is not compared and a warning is shown.
+func SnapshotSectionCompared(Section string, From int, To int) bool {
+	if From > 0 && To > 0 {
+		return true
+	}
+	if From > 0 || To > 0 {
+		Warning.Println("Only one snapshot contains " + Section + ". The " + Section + " are not compared.")
+	}
+	return false
+}
+
+//SnapshotPoolsDiff returns the added and removed pools and the changed names, states and capacities [GB] of the pools
+func SnapshotPoolsDiff(From []PoolInfo, To []PoolInfo, p Params) []SnapshotChange {
+	var Changes []SnapshotChange
+
+	Keys := map[string]bool{}
+	FromPools := map[string]PoolInfo{}
+	for _, Pool := range From {
+		FromPools[Pool.PoolID] = Pool
+		Keys[Pool.PoolID] = true
+	}
+	ToPools := map[string]PoolInfo{}
+	for _, Pool := range To {
+		ToPools[Pool.PoolID] = Pool
+		Keys[Pool.PoolID] = true
+	}
+
+	for _, PoolID := range SnapshotKeysSorted(Keys) {
+		Before, InFrom := FromPools[PoolID]
+		After, InTo := ToPools[PoolID]
+		switch {
+		case !InFrom:
+			Changes = append(Changes, SnapshotChange{Object: "pool", ID: PoolID, Change: "added", Attribute: "name", After: After.PoolName})
+		case !InTo:
+			Changes = append(Changes, SnapshotChange{Object: "pool", ID: PoolID, Change: "removed", Attribute: "name", Before: Before.PoolName})
+		default:
+			Changes = append(Changes, SnapshotStringDiff("pool", PoolID, "name", Before.PoolName, After.PoolName)...)
+			Changes = append(Changes, SnapshotStringDiff("pool", PoolID, "status", Before.PoolStatus, After.PoolStatus)...)
+			Changes = append(Changes, SnapshotCapacityDiff("pool", PoolID, "capacity [GB]", Before.TotalPoolCapacity, After.TotalPoolCapacity, p)...)
+			Changes = append(Changes, SnapshotCapacityDiff("pool", PoolID, "physical capacity [GB]", Before.PhysicalCapacityTotal, After.PhysicalCapacityTotal, p)...)
+			Changes = append(Changes, SnapshotCapacityDiff("pool", PoolID, "physical used [GB]", Before.PhysicalCapacityTotal-Before.PhysicalCapacityFree, After.PhysicalCapacityTotal-After.PhysicalCapacityFree, p)...)
+			Changes = append(Changes, SnapshotCapacityDiff("pool", PoolID, "subscribed [GB]", Before.TotalLocatedCapacity, After.TotalLocatedCapacity, p)...)
+		}
+	}
+	return Changes
+}
+
+//SnapshotHostGroupsDiff returns the added and removed host groups and the changed names and host modes with their options
+func SnapshotHostGroupsDiff(From []HostGroupInfo, To []HostGroupInfo) []SnapshotChange {
+	var Changes []SnapshotChange
+
+	Keys := map[string]bool{}
+	FromHostGroups := map[string]HostGroupInfo{}
+	for _, HostGroup := range From {
+		FromHostGroups[HostGroup.PortID+","+strconv.Itoa(HostGroup.HostGroupNumber)] = HostGroup
+		Keys[HostGroup.PortID+","+strconv.Itoa(HostGroup.HostGroupNumber)] = true
+	}
+	ToHostGroups := map[string]HostGroupInfo{}
+	for _, HostGroup := range To {
+		ToHostGroups[HostGroup.PortID+","+strconv.Itoa(HostGroup.HostGroupNumber)] = HostGroup
+		Keys[HostGroup.PortID+","+strconv.Itoa(HostGroup.HostGroupNumber)] = true
+	}
+
+	for _, ID := range SnapshotKeysSorted(Keys) {
+		Before, InFrom := FromHostGroups[ID]
+		After, InTo := ToHostGroups[ID]
+		switch {
+		case !InFrom:
+			Changes = append(Changes, SnapshotChange{Object: "host group", ID: ID, Change: "added", Attribute: "name", After: After.HostGroupName})
+		case !InTo:
+			Changes = append(Changes, SnapshotChange{Object: "host group", ID: ID, Change: "removed", Attribute: "name", Before: Before.HostGroupName})
+		default:
+			Changes = append(Changes, SnapshotStringDiff("host group", ID, "name", Before.HostGroupName, After.HostGroupName)...)
+			Changes = append(Changes, SnapshotStringDiff("host group", ID, "host mode", HostModeFormat(Before), HostModeFormat(After))...)
+		}
+	}
+	return Changes
+}
+
+//SnapshotLunsDiff returns the added and removed LUN paths, the changed LDEVs of the LUN paths and the added and removed reservations
+func SnapshotLunsDiff(From []LunInfo, To []LunInfo) []SnapshotChange {
+	var Changes []SnapshotChange
+
+	Keys := map[string]bool{}
+	FromLuns := map[string]LunInfo{}
+	for _, Lun := range From {
+		FromLuns[Lun.PortID+","+strconv.Itoa(Lun.HostGroupNumber)+","+strconv.Itoa(Lun.Lun)] = Lun
+		Keys[Lun.PortID+","+strconv.Itoa(Lun.HostGroupNumber)+","+strconv.Itoa(Lun.Lun)] = true
+	}
+	ToLuns := map[string]LunInfo{}
+	for _, Lun := range To {
+		ToLuns[Lun.PortID+","+strconv.Itoa(Lun.HostGroupNumber)+","+strconv.Itoa(Lun.Lun)] = Lun
+		Keys[Lun.PortID+","+strconv.Itoa(Lun.HostGroupNumber)+","+strconv.Itoa(Lun.Lun)] = true
+	}
+
+	for _, ID := range SnapshotKeysSorted(Keys) {
+		Before, InFrom := FromLuns[ID]
+		After, InTo := ToLuns[ID]
+		switch {
+		case !InFrom:
+			Changes = append(Changes, SnapshotChange{Object: "LUN path", ID: ID, Change: "added", Attribute: "LDEV", After: LdevIDFormat(After.LdevID)})
+		case !InTo:
+			Changes = append(Changes, SnapshotChange{Object: "LUN path", ID: ID, Change: "removed", Attribute: "LDEV", Before: LdevIDFormat(Before.LdevID)})
+		default:
+			Changes = append(Changes, SnapshotStringDiff("LUN path", ID, "LDEV", LdevIDFormat(Before.LdevID), LdevIDFormat(After.LdevID))...)
+		}
+
+		//reservations of LUN paths that exist in one snapshot only are new or gone too
+		BeforeReserves := map[string]bool{}
+		for _, Reserve := range Before.Reserves {
+			BeforeReserves[Reserve] = true
+		}
+		AfterReserves := map[string]bool{}
+		for _, Reserve := range After.Reserves {
+			AfterReserves[Reserve] = true
+		}
+		for _, Reserve := range After.Reserves {
+			if !BeforeReserves[Reserve] {
+				Changes = append(Changes, SnapshotChange{Object: "reservation", ID: ID, Change: "added", Attribute: Reserve, After: LdevIDFormat(After.LdevID)})
+			}
+		}
+		for _, Reserve := range Before.Reserves {
+			if !AfterReserves[Reserve] {
+				Changes = append(Changes, SnapshotChange{Object: "reservation", ID: ID, Change: "removed", Attribute: Reserve, Before: LdevIDFormat(Before.LdevID)})
+			}
+		}
+	}
+	return Changes
+}
+
+//SnapshotStringDiff returns a change if the attribute is different
+func SnapshotStringDiff(Object string, ID string, Attribute string, Before string, After string) []SnapshotChange {
+	if Before == After {
+		return nil
+	}
+	return []SnapshotChange{{Object: Object, ID: ID, Change: "changed", Attribute: Attribute, Before: Before, After: After}}
+}
+
+//SnapshotCapacityDiff returns a change with the delta if the capacity [MB] is different in [GB] with the round precision
+func SnapshotCapacityDiff(Object string, ID string, Attribute string, Before float64, After float64, p Params) []SnapshotChange {
+	var Mb2Gb float64
+	Mb2Gb = 1024.0
+
+	BeforeString := strconv.FormatFloat(Before/Mb2Gb, 'f', p.RoundPrecision, 64)
+	AfterString := strconv.FormatFloat(After/Mb2Gb, 'f', p.RoundPrecision, 64)
+	if BeforeString == AfterString {
+		return nil
+	}
+
+	var Delta float64
+	Delta = (After - Before) / Mb2Gb
+	var DeltaString string
+	DeltaString = strconv.FormatFloat(math.Abs(Delta), 'f', p.RoundPrecision, 64)
+	if Delta < 0 {
+		DeltaString = "-" + DeltaString
+	} else {
+		DeltaString = "+" + DeltaString
+	}
+	return []SnapshotChange{{Object: Object, ID: ID, Change: "changed", Attribute: Attribute, Before: BeforeString, After: AfterString + " (" + DeltaString + ")"}}
+}
+
+//SnapshotKeysSorted returns the keys sorted. keys with numbers are sorted by their numbers (ex: CL1-A,2 before CL1-A,10).
+func SnapshotKeysSorted(Keys map[string]bool) []string {
+	var Sorted []string
+	for Key := range Keys {
+		Sorted = append(Sorted, Key)
+	}
+	sort.SliceStable(Sorted, func(i, j int) bool {
+		return SnapshotKeyLess(Sorted[i], Sorted[j])
+	})
+	return Sorted
+}
+
+//SnapshotKeyLess compares the parts of two keys separated by ",". numbers are compared as numbers.
+func SnapshotKeyLess(A string, B string) bool {
+	PartsA := strings.Split(A, ",")
+	PartsB := strings.Split(B, ",")
+	for i := 0; i < len(PartsA) && i < len(PartsB); i++ {
+		if PartsA[i] == PartsB[i] {
+			continue
+		}
+		NumberA, errA := strconv.Atoi(PartsA[i])
+		NumberB, errB := strconv.Atoi(PartsB[i])
+		if errA == nil && errB == nil {
+			return NumberA < NumberB
+		}
+		return PartsA[i] < PartsB[i]
+	}
+	return len(PartsA) < len(PartsB)
+}
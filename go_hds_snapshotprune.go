@@ -0,0 +1,282 @@
+package main
+
+import (
+	"encoding/csv"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//SnapshotRetention type is one line of the retention file. Thin Image snapshots of a snapshot group matching the pattern are kept Days days.
+type SnapshotRetention struct {
+	Pattern *regexp.Regexp
+	Days    int
+}
+
+//SnapshotSplitTimeFormat is the format of the split time of a snapshot without time zone (storage local time). a split time with "Z" or an offset is RFC3339.
+const SnapshotSplitTimeFormat = "2006-01-02T15:04:05"
+
+//SnapshotPrune deletes all Thin Image snapshots older than the retention of their snapshot group (-retentionfile, read by SnapshotRetentionRead before the session is opened)
+//without -execute the snapshots are only listed (dry-run). With -execute the resources are locked and every deletion job is waited for.
+//The snapshot used capacity of the HTI pools and the pools with snapshot data (ex: HDP pools) is shown before and after the deletion.
+//a snapshot that cannot be deleted is shown as failed with its error. the resources are always unlocked.
+//return value (string) is empty. if an error happened the state is true. Otherwise false.
+//example: SnapshotPrune(p, Retentions)
+func SnapshotPrune(p Params, Retentions []SnapshotRetention) (string, bool) {
+	Debug.Println("Function 'SnapshotPrune' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//initial state is true that means NOK
+	State := true
+
+	var Mb2Gb float64
+	Mb2Gb = 1024.0
+
+	if p.Execute {
+		Info.Println("Prune Thin Image snapshots start")
+	} else {
+		Info.Println("Prune Thin Image snapshots start (dry-run. use -execute to delete the snapshots)")
+	}
+
+	var Pairs []CopyPairInfo
+	Pairs, State = LocalCopyPairsListGet(p)
+
+	//all snapshots older than their retention
+	var Candidates []CopyPairInfo
+	var Ages []float64
+	var Keeps []int
+	for _, Pair := range Pairs {
+		if Pair.ReplicationType != "TI" {
+			continue
+		}
+
+		var Keep int
+		Keep = -1
+		for _, Retention := range Retentions {
+			if Retention.Pattern.MatchString(Pair.CopyGroupName) {
+				Keep = Retention.Days
+				break
+			}
+		}
+		//no retention for this snapshot group
+		if Keep < 0 {
+			continue
+		}
+
+		//snapshots that were never split contain no data to keep
+		if Pair.SplitTime == "" {
+			continue
+		}
+		SplitTime, err := SnapshotSplitTimeParse(Pair.SplitTime)
+		if err != nil {
+			Warning.Println("The split time (" + Pair.SplitTime + ") of the snapshot " + Pair.CopyGroupName + " P-VOL: " + LdevIDFormat(Pair.PvolLdevID) + " MU: " + strconv.Itoa(Pair.MuNumber) + " cannot be read. It is kept.")
+			continue
+		}
+
+		Age := TimeStart.Sub(SplitTime).Hours() / 24
+		if Age <= float64(Keep) {
+			continue
+		}
+		Candidates = append(Candidates, Pair)
+		Ages = append(Ages, Age)
+		Keeps = append(Keeps, Keep)
+	}
+
+	if len(Candidates) == 0 {
+		Info.Println("No Thin Image snapshot older than its retention found.")
+		Info.Println("Prune Thin Image snapshots end")
+		return "", false
+	}
+
+	//number of snapshots per pool. HDP pools store snapshot data as well
+	SnapshotCount := map[int]int{}
+	for _, Pair := range Pairs {
+		if Pair.ReplicationType == "TI" && Pair.SnapshotPoolID >= 0 {
+			SnapshotCount[Pair.SnapshotPoolID] = SnapshotCount[Pair.SnapshotPoolID] + 1
+		}
+	}
+
+	//snapshot pools before the deletion
+	PoolsBefore, _ := PoolsListGet(p)
+
+	//no snapshot is deleted if the resources cannot be locked
+	var LockState bool
+	LockState = false
+	if p.Execute {
+		_, LockState = ResourceLock(p)
+	}
+
+	//the snapshots and the snapshot pools are one output
+	OutputReportsStart()
+
+	//add empty string of strings to collect all snapshot data to output
+	OutData := [][]string{}
+	var Deleted int
+	Deleted = 0
+	for i, Pair := range Candidates {
+		var Action string
+		Action = "dry-run"
+		switch {
+		case p.Execute && LockState:
+			Action = "skipped"
+		case p.Execute:
+			Verbose.Println("Delete the snapshot " + Pair.CopyGroupName + " P-VOL: " + LdevIDFormat(Pair.PvolLdevID) + " MU: " + strconv.Itoa(Pair.MuNumber))
+			p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + "/ConfigurationManager/v1/objects/storages/" + p.StorageDeviceID + "/snapshots/" + strconv.Itoa(Pair.PvolLdevID) + "," + strconv.Itoa(Pair.MuNumber)
+			p.RequestType = "DELETE"
+			if Job, JobState := JobRequest(p); JobState {
+				Action = "failed " + JobErrorFormat(Job)
+			} else {
+				Action = "deleted"
+				Deleted = Deleted + 1
+			}
+		}
+
+		var SnapshotPoolID string
+		SnapshotPoolID = "-"
+		if Pair.SnapshotPoolID >= 0 {
+			SnapshotPoolID = strconv.Itoa(Pair.SnapshotPoolID)
+		}
+
+		OutData = append(OutData, []string{p.ElementStringStart})
+		OutData = append(OutData, []string{HeaderFormat("Snapshot group", "string", p), Pair.CopyGroupName})
+		OutData = append(OutData, []string{HeaderFormat("P-VOL", "string", p), LdevIDFormat(Pair.PvolLdevID)})
+		OutData = append(OutData, []string{HeaderFormat("MU", "float64", p), strconv.Itoa(Pair.MuNumber)})
+		OutData = append(OutData, []string{HeaderFormat("Snapshot pool", "string", p), SnapshotPoolID})
+		OutData = append(OutData, []string{HeaderFormat("Split time", "string", p), Pair.SplitTime})
+		OutData = append(OutData, []string{HeaderFormat("Age [days]", "float64", p), strconv.FormatFloat(Ages[i], 'f', 0, 64)})
+		OutData = append(OutData, []string{HeaderFormat("Retention [days]", "float64", p), strconv.Itoa(Keeps[i])})
+		OutData = append(OutData, []string{HeaderFormat("Action", "string", p), Action})
+		OutData = append(OutData, []string{p.ElementStringEnd})
+	}
+
+	p.ReportName = "snapshots"
+	if OutputListFormat(OutData, p) {
+		Warning.Println("The function 'OutputListFormat' returned an Error.")
+	}
+
+	if p.Execute && !LockState {
+		ResourceUnlock(p)
+	}
+
+	//snapshot pools after the deletion
+	PoolsAfter := PoolsBefore
+	if p.Execute {
+		PoolsAfter, _ = PoolsListGet(p)
+	}
+	SnapshotUsedAfter := map[string]float64{}
+	for _, Pool := range PoolsAfter {
+		SnapshotUsedAfter[Pool.PoolID] = Pool.SnapshotUsedCapacity
+	}
+
+	OutData = [][]string{}
+	for _, Pool := range PoolsBefore {
+		PoolID, _ := strconv.Atoi(Pool.PoolID)
+		if Pool.PoolType != "HTI" && SnapshotCount[PoolID] == 0 {
+			continue
+		}
+
+		var After string
+		var Reclaimed string
+		After = "n/a"
+		Reclaimed = "n/a"
+		if p.Execute {
+			After = strconv.FormatFloat(SnapshotUsedAfter[Pool.PoolID]/Mb2Gb, 'f', p.RoundPrecision, 64)
+			Reclaimed = strconv.FormatFloat((Pool.SnapshotUsedCapacity-SnapshotUsedAfter[Pool.PoolID])/Mb2Gb, 'f', p.RoundPrecision, 64)
+		}
+
+		OutData = append(OutData, []string{p.ElementStringStart})
+		OutData = append(OutData, []string{HeaderFormat("Pool ID", "string", p), Pool.PoolID})
+		OutData = append(OutData, []string{HeaderFormat("Pool name", "string", p), Pool.PoolName})
+		OutData = append(OutData, []string{HeaderFormat("Snapshot used before [GB]", "float64", p), strconv.FormatFloat(Pool.SnapshotUsedCapacity/Mb2Gb, 'f', p.RoundPrecision, 64)})
+		OutData = append(OutData, []string{HeaderFormat("Snapshot used after [GB]", "float64", p), After})
+		OutData = append(OutData, []string{HeaderFormat("Reclaimed [GB]", "float64", p), Reclaimed})
+		OutData = append(OutData, []string{p.ElementStringEnd})
+	}
+
+	if len(OutData) > 0 {
+		p.ReportName = "snapshotPools"
+		if OutputListFormat(OutData, p) {
+			Warning.Println("The function 'OutputListFormat' returned an Error.")
+		}
+	}
+	OutputReportsEnd(p)
+
+	if p.Execute {
+		Info.Println("Snapshots deleted: " + strconv.Itoa(Deleted) + " of " + strconv.Itoa(len(Candidates)))
+	} else {
+		Info.Println("Snapshots to delete: " + strconv.Itoa(len(Candidates)))
+	}
+	Info.Println("Prune Thin Image snapshots end")
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'SnapshotPrune' - Elapsed time ", TimeDiff)
+
+	//state to NOK if a deletion failed
+	State = p.Execute && Deleted != len(Candidates)
+
+	Debug.Println("Function 'SnapshotPrune' return values State:", State)
+	Debug.Println("Function 'SnapshotPrune' end")
+
+	return "", State
+}
+
+//SnapshotSplitTimeParse returns the split time of a snapshot. a time with "Z" (UTC) or an offset is read as RFC3339, a time without time zone as local time (SnapshotSplitTimeFormat).
+//example: SnapshotSplitTimeParse("2018-05-20T02:00:01Z")
+func SnapshotSplitTimeParse(SplitTime string) (time.Time, error) {
+	if Time, err := time.Parse(time.RFC3339, SplitTime); err == nil {
+		return Time, nil
+	}
+	return time.ParseInLocation(SnapshotSplitTimeFormat, SplitTime, time.Local)
+}
+
+//SnapshotRetentionRead reads the retention file. every line is "<snapshot group name regular expression>,<days>". lines starting with # are skipped.
+//The function stops with exit status 72 ("The retention file cannot be read.")
+//The function stops with exit status 73 ("The retention file contains an invalid line.")
+func SnapshotRetentionRead(FileName string) ([]SnapshotRetention, bool) {
+	Debug.Println("Function 'SnapshotRetentionRead' started.")
+
+	//initial state is true that means NOK
+	State := true
+
+	File, err := os.Open(FileName)
+	if err != nil {
+		Error.Println("The retention file (" + FileName + ") cannot be read: " + err.Error())
+		os.Exit(72)
+	}
+	defer File.Close()
+
+	Reader := csv.NewReader(File)
+	Reader.Comment = '#'
+	Reader.FieldsPerRecord = 2
+	Records, err := Reader.ReadAll()
+	if err != nil {
+		Error.Println("The retention file (" + FileName + ") contains an invalid line: " + err.Error())
+		os.Exit(73)
+	}
+
+	var Retentions []SnapshotRetention
+	for _, Record := range Records {
+		Pattern, err := regexp.Compile(strings.TrimSpace(Record[0]))
+		if err != nil {
+			Error.Println("The retention file (" + FileName + ") contains an invalid regular expression: " + err.Error())
+			os.Exit(73)
+		}
+		Days, err := strconv.Atoi(strings.TrimSpace(Record[1]))
+		if err != nil || Days < 0 {
+			Error.Println("The retention file (" + FileName + ") contains an invalid number of days: " + Record[1])
+			os.Exit(73)
+		}
+		Retentions = append(Retentions, SnapshotRetention{Pattern: Pattern, Days: Days})
+	}
+
+	Debug.Println("Function 'SnapshotRetentionRead' return values number of retentions:", len(Retentions))
+	Debug.Println("Function 'SnapshotRetentionRead' ended.")
+
+	//state to OK
+	State = false
+	return Retentions, State
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strconv"
+	"time"
+)
+
+//StorageInfo type is the model and serial number of the storage the report is about
+type StorageInfo struct {
+	StorageDeviceID string
+	Model           string
+	Serial          string
+	MicroVersion    string
+}
+
+//StorageInfoGet gets the model, the serial number and the microcode version of the storage (p.StorageDeviceID)
+//return value (StorageInfo) is the storage. if an error happened the state is true. Otherwise false.
+//example: StorageInfoGet(p)
+func StorageInfoGet(p Params) (StorageInfo, bool) {
+	Debug.Println("Function 'StorageInfoGet' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//initial state is true that means NOK
+	State := true
+
+	/*
+	   {
+	       "storageDeviceId": "834000470018",
+	       "model": "VSP G600",
+	       "serialNumber": 470018,
+	       "svpIp": "10.70.5.104",
+	       "dkcMicroVersion": "83-04-21/00",
+	       ...
+	   }
+	*/
+
+	var Storage StorageInfo
+	Storage.StorageDeviceID = p.StorageDeviceID
+
+	p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + "/ConfigurationManager/v1/objects/storages/" + p.StorageDeviceID
+	Debug.Println(p.URL)
+	p.RequestType = "GET"
+
+	var ParsedMap map[string]interface{}
+	ParsedMap, State = JSONUnmarshal(HTTPRequest(p))
+
+	Storage.Model = ElementString(ParsedMap, "model")
+	if ParsedMap["serialNumber"] != nil {
+		Storage.Serial = strconv.FormatFloat(ElementFloat64(ParsedMap, "serialNumber"), 'f', 0, 64)
+	}
+	Storage.MicroVersion = ElementString(ParsedMap, "dkcMicroVersion")
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'StorageInfoGet' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'StorageInfoGet' return values:", Storage)
+	Debug.Println("Function 'StorageInfoGet' ended.")
+
+	//state to OK
+	State = false
+	return Storage, State
+}
+
+//StorageName returns the model and the serial number of the storage (ex: "VSP G600 (470018)"). the storage device id is used if the model is not known.
+func StorageName(Storage StorageInfo) string {
+	if Storage.Model == "" {
+		return Storage.StorageDeviceID
+	}
+	return Storage.Model + " (" + Storage.Serial + ")"
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+//TokenCacheSaltSize is the size of the salt of the key derivation. the salt is stored in front of the nonce.
+const TokenCacheSaltSize int = 16
+
+//TokenCacheEntry type is one session stored in the token cache (-tokencache). It is keyed by host, port, user and storageDeviceId.
+type TokenCacheEntry struct {
+	Host            string
+	Port            string
+	User            string
+	StorageDeviceID string
+	Token           string
+	SessionID       float64
+	Created         time.Time
+}
+
+//SessionOpen returns the StorageDeviceID, the token and the session id to use
+//without -tokencache the StorageDeviceID is requested and a new session is created.
+//with -tokencache a cached session of the host and user is reused if it is still valid. otherwise a new session is created and cached.
+//if the cache contains only one storage of the host the StorageDeviceID is not requested.
+//the keep-alive of the session is started (SessionStart). the storage of the markdown output is requested (MarkdownStorageGet).
+//if an error happened the state is true. Otherwise false.
+//example: SessionOpen(p)
+func SessionOpen(p Params) (string, string, float64, bool) {
+	Debug.Println("Function 'SessionOpen' started.")
+
+	//initial state is true that means NOK
+	State := true
+
+	if p.TokenCache == "" {
+		p.StorageDeviceID, State = StorageDeviceIDGet(p)
+		p.Token, p.SessionID, State = TokenGet(p)
+		SessionStart(p)
+		MarkdownStorageGet(p)
+		return p.StorageDeviceID, p.Token, p.SessionID, State
+	}
+
+	var Entries []TokenCacheEntry
+	Entries, _ = TokenCacheRead(p)
+
+	//all cached storages of the host
+	StorageDeviceIDs := map[string]bool{}
+	for _, Entry := range Entries {
+		if Entry.Host == p.Host && Entry.Port == p.Port && Entry.User == p.Username {
+			StorageDeviceIDs[Entry.StorageDeviceID] = true
+		}
+	}
+	if len(StorageDeviceIDs) == 1 {
+		for StorageDeviceID := range StorageDeviceIDs {
+			p.StorageDeviceID = StorageDeviceID
+		}
+		Verbose.Println("StorageDeviceID from the token cache: " + p.StorageDeviceID)
+	} else {
+		p.StorageDeviceID, State = StorageDeviceIDGet(p)
+	}
+
+	//reuse the cached session if it is still valid
+	for _, Entry := range Entries {
+		if Entry.Host != p.Host || Entry.Port != p.Port || Entry.User != p.Username || Entry.StorageDeviceID != p.StorageDeviceID {
+			continue
+		}
+		p.Token = Entry.Token
+		p.SessionID = Entry.SessionID
+		if SessionValid(p) {
+			Verbose.Println("Session ID: " + strconv.FormatFloat(p.SessionID, 'g', -1, 64) + " reused from the token cache.")
+			SessionStart(p)
+			MarkdownStorageGet(p)
+			Debug.Println("Function 'SessionOpen' ended.")
+			return p.StorageDeviceID, p.Token, p.SessionID, false
+		}
+		Verbose.Println("The cached session ID: " + strconv.FormatFloat(p.SessionID, 'g', -1, 64) + " is expired. A new session is created.")
+	}
+
+	//new session
+	p.Token = ""
+	p.Token, p.SessionID, State = TokenGet(p)
+	TokenCacheStore(p)
+	SessionStart(p)
+	MarkdownStorageGet(p)
+
+	Debug.Println("Function 'SessionOpen' ended.")
+	return p.StorageDeviceID, p.Token, p.SessionID, State
+}
+
+//SessionClose stops the keep-alive and deletes the current session. with -tokencache the session is kept for the next run if it is stored in the token cache.
+//a session that still holds the lock of the resources (ResourceUnlockFailed) is always deleted to release the lock.
+//example: SessionClose(p)
+func SessionClose(p Params) (string, bool) {
+	p.Token, p.SessionID = SessionStop(p)
+	if p.TokenCache != "" && !ResourceUnlockFailed {
+		Entries, _ := TokenCacheRead(p)
+		for _, Entry := range Entries {
+			if Entry.SessionID == p.SessionID && Entry.Token == p.Token {
+				Verbose.Println("Session ID: " + strconv.FormatFloat(p.SessionID, 'g', -1, 64) + " kept in the token cache.")
+				return "", false
+			}
+		}
+		Verbose.Println("Session ID: " + strconv.FormatFloat(p.SessionID, 'g', -1, 64) + " is not stored in the token cache. It is deleted.")
+	}
+	return TokenDelete(p)
+}
+
+//TokenCacheStore stores the session of the storage in the token cache. an older session of the storage is replaced.
+//a token cache that cannot be decrypted with the credentials (ex: of another user) is not overwritten. the state is true then.
+//example: TokenCacheStore(p)
+func TokenCacheStore(p Params) bool {
+	Entries, ReadState := TokenCacheRead(p)
+	if ReadState {
+		Warning.Println("The token cache (" + p.TokenCache + ") cannot be decrypted with these credentials or is not valid. It is not overwritten. Use one token cache per user or delete it. The session is not cached.")
+		return true
+	}
+
+	var NewEntries []TokenCacheEntry
+	for _, Entry := range Entries {
+		if Entry.Host == p.Host && Entry.Port == p.Port && Entry.User == p.Username && Entry.StorageDeviceID == p.StorageDeviceID {
+			continue
+		}
+		NewEntries = append(NewEntries, Entry)
+	}
+	NewEntries = append(NewEntries, TokenCacheEntry{Host: p.Host, Port: p.Port, User: p.Username, StorageDeviceID: p.StorageDeviceID, Token: p.Token, SessionID: p.SessionID, Created: time.Now()})
+	return TokenCacheWrite(p, NewEntries)
+}
+
+//SessionValid checks if the session of the token is still valid by requesting the session itself
+func SessionValid(p Params) bool {
+	p.URL = p.Protocol + "://" + p.Host + ":" + p.Port + "/ConfigurationManager/v1/objects/storages/" + p.StorageDeviceID + "/sessions/" + strconv.FormatFloat(p.SessionID, 'g', -1, 64)
+	p.RequestType = "GET"
+
+	StatusCode, Status, _ := HTTPRequestStatus(p)
+	Debug.Println("Session validation status: " + Status)
+	return StatusCode == 200
+}
+
+//TokenCacheKey returns the key to encrypt the token cache. It is derived with scrypt from the credentials and the salt, so only the same user and password can read it.
+func TokenCacheKey(p Params, Salt []byte) ([]byte, error) {
+	return scrypt.Key([]byte(p.Username+"\x00"+p.Password), Salt, 32768, 8, 1, 32)
+}
+
+//TokenCacheCipher returns the cipher (AES-GCM) of the token cache with the key of the credentials and the salt
+func TokenCacheCipher(p Params, Salt []byte) (cipher.AEAD, error) {
+	Key, err := TokenCacheKey(p, Salt)
+	if err != nil {
+		return nil, err
+	}
+	Block, err := aes.NewCipher(Key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(Block)
+}
+
+//TokenCacheRead reads and decrypts the token cache (AES-GCM). the file is the salt, the nonce and the encrypted entries.
+//a missing cache is empty. if the cache cannot be decrypted with the credentials or is not valid the state is true. Otherwise false.
+func TokenCacheRead(p Params) ([]TokenCacheEntry, bool) {
+	var Entries []TokenCacheEntry
+
+	Encrypted, err := ioutil.ReadFile(p.TokenCache)
+	if err != nil {
+		Verbose.Println("The token cache (" + p.TokenCache + ") cannot be read. It is created.")
+		return Entries, false
+	}
+
+	if len(Encrypted) < TokenCacheSaltSize {
+		Verbose.Println("The token cache (" + p.TokenCache + ") is not valid.")
+		return Entries, true
+	}
+	GCM, err := TokenCacheCipher(p, Encrypted[:TokenCacheSaltSize])
+	if err != nil {
+		Warning.Println("The token cache cipher cannot be created: " + err.Error())
+		return Entries, true
+	}
+	Encrypted = Encrypted[TokenCacheSaltSize:]
+	if len(Encrypted) < GCM.NonceSize() {
+		Verbose.Println("The token cache (" + p.TokenCache + ") is not valid.")
+		return Entries, true
+	}
+
+	//the nonce is stored in front of the encrypted data
+	Plain, err := GCM.Open(nil, Encrypted[:GCM.NonceSize()], Encrypted[GCM.NonceSize():], nil)
+	if err != nil {
+		Verbose.Println("The token cache (" + p.TokenCache + ") cannot be decrypted with these credentials.")
+		return Entries, true
+	}
+
+	if err := json.Unmarshal(Plain, &Entries); err != nil {
+		Verbose.Println("The token cache (" + p.TokenCache + ") is not valid.")
+		return nil, true
+	}
+	return Entries, false
+}
+
+//TokenCacheWrite encrypts (AES-GCM) and writes the token cache with a new salt and nonce. Only the owner can read the file.
+func TokenCacheWrite(p Params, Entries []TokenCacheEntry) bool {
+	Plain, err := json.Marshal(Entries)
+	if err != nil {
+		Warning.Println("The token cache cannot be converted to JSON: " + err.Error())
+		return true
+	}
+
+	//a new salt for every write
+	Salt := make([]byte, TokenCacheSaltSize)
+	if _, err := io.ReadFull(rand.Reader, Salt); err != nil {
+		Warning.Println("The token cache salt cannot be created: " + err.Error())
+		return true
+	}
+	GCM, err := TokenCacheCipher(p, Salt)
+	if err != nil {
+		Warning.Println("The token cache cipher cannot be created: " + err.Error())
+		return true
+	}
+	Nonce := make([]byte, GCM.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, Nonce); err != nil {
+		Warning.Println("The token cache nonce cannot be created: " + err.Error())
+		return true
+	}
+
+	//the salt and the nonce are stored in front of the encrypted data
+	if err := ioutil.WriteFile(p.TokenCache, GCM.Seal(append(Salt, Nonce...), Nonce, Plain, nil), 0600); err != nil {
+		Warning.Println("The token cache (" + p.TokenCache + ") cannot be written: " + err.Error())
+		return true
+	}
+	//an existing file keeps its permissions on write
+	os.Chmod(p.TokenCache, 0600)
+
+	Verbose.Println("Token cache stored: " + p.TokenCache)
+	return false
+}
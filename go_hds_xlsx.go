@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/360EntSecGroup-Skylar/excelize"
+)
+
+//Workbook is the workbook of the xlsx output. every report is one sheet. the workbook is saved to the output file (-outputfile) after every report.
+var Workbook *excelize.File
+
+//XLSXMaxSheetName is the maximum length of a sheet name
+const XLSXMaxSheetName int = 31
+
+//XLSXMaxColumnWidth is the maximum width of a column (characters)
+const XLSXMaxColumnWidth int = 60
+
+//OutputXLSX adds the data as sheet to the workbook and saves it to the output file (-outputfile). Every element is one row.
+//The sheet is named after the report (p.ReportName) or the type (-type). The descriptors of the first element without the type are the header.
+//float64 values and values without type are numeric cells if they are numbers. "Utilization [%]" is highlighted if it reaches the "Warning threshold [%]" or the "Depletion threshold [%]" of the same row.
+func OutputXLSX(Data [][]string, p Params) bool {
+	Debug.Println("Function 'OutputXLSX' started.")
+	//start timer
+	TimeStart := time.Now()
+
+	//true -> NOK
+	//false -> OK
+	State := false
+
+	// if no data is available skip output
+	if len(Data) == 0 {
+		Error.Println("No Data to output.")
+		return State
+	}
+
+	var Sheet string
+	if Workbook == nil {
+		Workbook = excelize.NewFile()
+		Sheet = XLSXSheetName(p)
+		Workbook.SetSheetName("Sheet1", Sheet)
+	} else {
+		Sheet = XLSXSheetName(p)
+		Workbook.NewSheet(Sheet)
+	}
+
+	HeaderStyle, _ := Workbook.NewStyle(`{"font":{"bold":true},"fill":{"type":"pattern","color":["#DDEBF7"],"pattern":1}}`)
+	NumberFormat := "0"
+	if p.RoundPrecision > 0 {
+		NumberFormat = "0." + strings.Repeat("0", p.RoundPrecision)
+	}
+	NumberStyle, _ := Workbook.NewStyle(`{"custom_number_format":"` + NumberFormat + `"}`)
+
+	var Header []string
+	var Widths []int
+	var HeaderDone bool
+	HeaderDone = false
+	var Row int
+	Row = 1
+	var Column int
+	Column = 0
+	for i := 0; i < len(Data); i++ {
+		switch Data[i][0] {
+		case p.ElementStringStart:
+			Row = Row + 1
+			Column = 0
+		case p.ElementStringEnd:
+			HeaderDone = true
+		default:
+			Key, Type := HeaderSplit(Data[i][0])
+			if !HeaderDone {
+				Header = append(Header, Key)
+				Widths = append(Widths, len(Key))
+				Workbook.SetCellStr(Sheet, excelize.ToAlphaString(Column)+"1", Key)
+			}
+
+			Cell := excelize.ToAlphaString(Column) + strconv.Itoa(Row)
+			Number, err := strconv.ParseFloat(Data[i][1], 64)
+			if Type != "string" && err == nil {
+				Workbook.SetCellValue(Sheet, Cell, Number)
+				if strings.Contains(Data[i][1], ".") {
+					Workbook.SetCellStyle(Sheet, Cell, Cell, NumberStyle)
+				}
+			} else {
+				Workbook.SetCellStr(Sheet, Cell, Data[i][1])
+			}
+
+			if Column < len(Widths) && len(Data[i][1]) > Widths[Column] {
+				Widths[Column] = len(Data[i][1])
+			}
+			Column = Column + 1
+		}
+	}
+
+	if len(Header) > 0 {
+		LastColumn := excelize.ToAlphaString(len(Header) - 1)
+		Workbook.SetCellStyle(Sheet, "A1", LastColumn+"1", HeaderStyle)
+		Workbook.SetPanes(Sheet, `{"freeze":true,"split":false,"x_split":0,"y_split":1,"top_left_cell":"A2","active_pane":"bottomLeft"}`)
+		Workbook.AutoFilter(Sheet, "A1", LastColumn+strconv.Itoa(Row), "")
+		for Column, Width := range Widths {
+			if Width > XLSXMaxColumnWidth {
+				Width = XLSXMaxColumnWidth
+			}
+			Workbook.SetColWidth(Sheet, excelize.ToAlphaString(Column), excelize.ToAlphaString(Column), float64(Width+2))
+		}
+	}
+
+	//utilization against the thresholds of the same row. a threshold that is not set (0) is not highlighted
+	UtilizationColumn := XLSXColumn(Header, "Utilization [%]")
+	WarningColumn := XLSXColumn(Header, "Warning threshold [%]")
+	DepletionColumn := XLSXColumn(Header, "Depletion threshold [%]")
+	if UtilizationColumn != "" && WarningColumn != "" && DepletionColumn != "" && Row > 1 {
+		DepletionStyle, _ := Workbook.NewConditionalStyle(`{"font":{"color":"#9C0006"},"fill":{"type":"pattern","color":["#FFC7CE"],"pattern":1}}`)
+		WarningStyle, _ := Workbook.NewConditionalStyle(`{"font":{"color":"#9C5700"},"fill":{"type":"pattern","color":["#FFEB9C"],"pattern":1}}`)
+		Rules := fmt.Sprintf(`[{"type":"formula","criteria":"AND($%s2>0,$%s2>=$%s2)","format":%d},{"type":"formula","criteria":"AND($%s2>0,$%s2>=$%s2)","format":%d}]`, DepletionColumn, UtilizationColumn, DepletionColumn, DepletionStyle, WarningColumn, UtilizationColumn, WarningColumn, WarningStyle)
+		if err := Workbook.SetConditionalFormat(Sheet, UtilizationColumn+"2:"+UtilizationColumn+strconv.Itoa(Row), Rules); err != nil {
+			Warning.Println("The conditional format of the sheet '" + Sheet + "' cannot be set: " + err.Error())
+		}
+	}
+
+	//the workbook opens with the first sheet
+	Workbook.SetActiveSheet(1)
+	if err := Workbook.SaveAs(p.OutputFile); err != nil {
+		Error.Println("The output file (" + p.OutputFile + ") cannot be written: " + err.Error())
+		State = true
+	} else {
+		Info.Println("Sheet '" + Sheet + "' (" + strconv.Itoa(Row-1) + " rows) written to " + p.OutputFile)
+	}
+
+	TimeEnd := time.Now()
+	TimeDiff := TimeEnd.Sub(TimeStart)
+	Debug.Println("Function 'OutputXLSX' - Elapsed time ", TimeDiff)
+	Debug.Println("Function 'OutputXLSX' return values State:", State)
+	Debug.Println("Function 'OutputXLSX' ended.")
+	return State
+}
+
+//XLSXSheetName returns a sheet name that is not used in the workbook. the report (p.ReportName) or the type (-type) with a number if it is used already (ex: "pool-rebalance 2").
+func XLSXSheetName(p Params) string {
+	var Name string
+	Name = p.ReportName
+	if Name == "" {
+		Name = p.OutputType
+	}
+	if len(Name) > XLSXMaxSheetName-3 {
+		Name = Name[:XLSXMaxSheetName-3]
+	}
+
+	var Unique string
+	Unique = Name
+	for Number := 2; Workbook.GetSheetIndex(Unique) != 0; Number++ {
+		Unique = Name + " " + strconv.Itoa(Number)
+	}
+	return Unique
+}
+
+//XLSXColumn returns the column (ex: "F") of a header. if the header does not exist an empty string is returned.
+func XLSXColumn(Header []string, Key string) string {
+	for Column, Descriptor := range Header {
+		if Descriptor == Key {
+			return excelize.ToAlphaString(Column)
+		}
+	}
+	return ""
+}